@@ -10,12 +10,15 @@ import (
 type ConditionList []Condition
 
 type Condition struct {
-	Type               ConditionType          `json:"type"`
-	Status             corev1.ConditionStatus `json:"status"`
-	Reason             ConditionReason        `json:"reason,omitempty"`
-	Message            string                 `json:"message,omitempty"`
-	LastHeartbeatTime  metav1.Time            `json:"lastHearbeatTime,omitempty"`
-	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Type    ConditionType          `json:"type"`
+	Status  corev1.ConditionStatus `json:"status"`
+	Reason  ConditionReason        `json:"reason,omitempty"`
+	Message string                 `json:"message,omitempty"`
+	// Details has the whole failure output, it is truncated to keep the
+	// object small
+	Details            string      `json:"details,omitempty"`
+	LastHeartbeatTime  metav1.Time `json:"lastHearbeatTime,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 type ConditionType string
@@ -36,22 +39,29 @@ func NewCondition(conditionType ConditionType, status corev1.ConditionStatus, re
 }
 
 func (conditions *ConditionList) Set(conditionType ConditionType, status corev1.ConditionStatus, reason ConditionReason, message string) {
+	conditions.SetWithDetails(conditionType, status, reason, message, "")
+}
+
+// SetWithDetails sets the condition like Set does and also its details
+func (conditions *ConditionList) SetWithDetails(conditionType ConditionType, status corev1.ConditionStatus, reason ConditionReason, message string, details string) {
 	condition := conditions.Find(conditionType)
 
 	// If there isn't condition we want to change, add new one
 	if condition == nil {
 		condition := NewCondition(conditionType, status, reason, message)
+		condition.Details = details
 		*conditions = append(*conditions, condition)
 		return
 	}
 
 	now := metav1.Time{Time: time.Now()}
 
-	// If there is different status, reason or message update it
-	if condition.Status != status || condition.Reason != reason || condition.Message != message {
+	// If there is different status, reason, message or details update it
+	if condition.Status != status || condition.Reason != reason || condition.Message != message || condition.Details != details {
 		condition.Status = status
 		condition.Reason = reason
 		condition.Message = message
+		condition.Details = details
 		condition.LastTransitionTime = now
 	}
 	condition.LastHeartbeatTime = now
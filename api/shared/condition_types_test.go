@@ -112,5 +112,22 @@ var _ = Describe("Conditions list", func() {
 				Expect(updatedCondition.LastHeartbeatTime.Time).To(BeTemporally(">", originalConditions[0].LastHeartbeatTime.Time))
 			})
 		})
+
+		Context("and we update only its details", func() {
+			BeforeEach(func() {
+				newConditions.SetWithDetails(originalConditions[0].Type, originalConditions[0].Status, originalConditions[0].Reason, originalConditions[0].Message, "foobar")
+			})
+
+			It("should change details and update LastTransitionTime", func() {
+				updatedCondition := newConditions.Find(originalConditions[0].Type)
+				Expect(updatedCondition.Details).To(Equal("foobar"))
+				Expect(updatedCondition.LastTransitionTime.Time).To(BeTemporally(">", originalConditions[0].LastTransitionTime.Time))
+			})
+
+			It("should clear details when set without them", func() {
+				newConditions.Set(originalConditions[0].Type, originalConditions[0].Status, originalConditions[0].Reason, originalConditions[0].Message)
+				Expect(newConditions.Find(originalConditions[0].Type).Details).To(BeEmpty())
+			})
+		})
 	})
 })
@@ -3,6 +3,7 @@ package shared
 import (
 	"fmt"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -15,17 +16,69 @@ type NodeNetworkConfigurationEnactmentStatus struct {
 
 	// The generation from policy needed to check if an enactment
 	// condition status belongs to the same policy version
-	PolicyGeneration int64         `json:"policyGeneration,omitempty"`
-	Conditions       ConditionList `json:"conditions,omitempty"`
+	PolicyGeneration int64 `json:"policyGeneration,omitempty"`
+
+	// +kubebuilder:validation:XPreserveUnknownFields
+	// The state that reverts the policy configuration at the enactment's
+	// node, captured before the policy is applied if the policy has cleanup
+	RevertState State `json:"revertState,omitempty"`
+
+	// The difference between the node current state and the state the
+	// node would have with the desired state applied, filled in when
+	// the policy is a dry run
+	DryRunDiff string `json:"dryRunDiff,omitempty"`
+
+	// The results of the policy probes and the NodeNetworkProbes matching
+	// the enactment's node, from the last time the desired state was
+	// applied
+	ProbeResults []ProbeResult `json:"probeResults,omitempty"`
+
+	Conditions ConditionList `json:"conditions,omitempty"`
 }
 
+// ProbeResult is the outcome of a probe run after applying the desired state
+type ProbeResult struct {
+	// Name is the policy probe name or the NodeNetworkProbe name
+	Name string `json:"name"`
+	// Source is where the probe is defined, Policy or NodeNetworkProbe
+	Source ProbeSource `json:"source"`
+	// Passed is true if the probe passed before its timeout
+	Passed bool `json:"passed"`
+	// Message is the probe failure
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastRunTime is when the probe finished
+	LastRunTime metav1.Time `json:"lastRunTime,omitempty"`
+}
+
+type ProbeSource string
+
 const (
-	EnactmentPolicyLabel                                                = "nmstate.io/policy"
-	NodeNetworkConfigurationEnactmentConditionAvailable   ConditionType = "Available"
-	NodeNetworkConfigurationEnactmentConditionFailing     ConditionType = "Failing"
-	NodeNetworkConfigurationEnactmentConditionPending     ConditionType = "Pending"
-	NodeNetworkConfigurationEnactmentConditionProgressing ConditionType = "Progressing"
-	NodeNetworkConfigurationEnactmentConditionAborted     ConditionType = "Aborted"
+	ProbeSourcePolicy           ProbeSource = "Policy"
+	ProbeSourceNodeNetworkProbe ProbeSource = "NodeNetworkProbe"
+)
+
+// EnactmentRevertAttemptsAnnotation counts the attempts to revert the
+// configuration of a deleted policy with cleanup at the enactment node
+const EnactmentRevertAttemptsAnnotation = "nmstate.io/revert-attempts"
+
+// EnactmentSkipRevertAnnotation set to "true" at an enactment whose revert
+// failed releases it, the enactment is deleted without reverting the node
+// configuration
+const EnactmentSkipRevertAnnotation = "nmstate.io/skip-revert"
+
+const (
+	EnactmentPolicyLabel                                                    = "nmstate.io/policy"
+	NodeNetworkConfigurationEnactmentConditionAvailable       ConditionType = "Available"
+	NodeNetworkConfigurationEnactmentConditionFailing         ConditionType = "Failing"
+	NodeNetworkConfigurationEnactmentConditionPending         ConditionType = "Pending"
+	NodeNetworkConfigurationEnactmentConditionProgressing     ConditionType = "Progressing"
+	NodeNetworkConfigurationEnactmentConditionAborted         ConditionType = "Aborted"
+	NodeNetworkConfigurationEnactmentConditionDryRunSucceeded ConditionType = "DryRunSucceeded"
+	NodeNetworkConfigurationEnactmentConditionDryRunFailed    ConditionType = "DryRunFailed"
+	// NodeNetworkConfigurationEnactmentConditionDrifted is only set at
+	// available enactments after checking the node current state
+	NodeNetworkConfigurationEnactmentConditionDrifted ConditionType = "Drifted"
 )
 
 var NodeNetworkConfigurationEnactmentConditionTypes = [...]ConditionType{
@@ -36,14 +89,50 @@ var NodeNetworkConfigurationEnactmentConditionTypes = [...]ConditionType{
 	NodeNetworkConfigurationEnactmentConditionAborted,
 }
 
+// NodeNetworkConfigurationEnactmentDryRunConditionTypes are only set at
+// enactments from policies with dryRun
+var NodeNetworkConfigurationEnactmentDryRunConditionTypes = [...]ConditionType{
+	NodeNetworkConfigurationEnactmentConditionDryRunSucceeded,
+	NodeNetworkConfigurationEnactmentConditionDryRunFailed,
+}
+
 const (
 	NodeNetworkConfigurationEnactmentConditionFailedToConfigure          ConditionReason = "FailedToConfigure"
 	NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured     ConditionReason = "SuccessfullyConfigured"
 	NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached ConditionReason = "MaxUnavailableLimitReached"
 	NodeNetworkConfigurationEnactmentConditionConfigurationProgressing   ConditionReason = "ConfigurationProgressing"
 	NodeNetworkConfigurationEnactmentConditionConfigurationAborted       ConditionReason = "ConfigurationAborted"
+	NodeNetworkConfigurationEnactmentConditionDryRunConfigured           ConditionReason = "DryRunConfigured"
+	NodeNetworkConfigurationEnactmentConditionDryRunFailedToConfigure    ConditionReason = "DryRunFailedToConfigure"
+	NodeNetworkConfigurationEnactmentConditionPaused                     ConditionReason = "Paused"
+	NodeNetworkConfigurationEnactmentConditionWaitingForRolloutStep      ConditionReason = "WaitingForRolloutStep"
+	NodeNetworkConfigurationEnactmentConditionConfigurationDrifted       ConditionReason = "ConfigurationDrifted"
+	NodeNetworkConfigurationEnactmentConditionConfigurationInSync        ConditionReason = "ConfigurationInSync"
+	// Failing reasons classifying why the desired state was not applied
+	NodeNetworkConfigurationEnactmentConditionInvalidArgument       ConditionReason = "InvalidArgument"
+	NodeNetworkConfigurationEnactmentConditionDependencyError       ConditionReason = "DependencyError"
+	NodeNetworkConfigurationEnactmentConditionVerificationFailed    ConditionReason = "VerificationFailed"
+	NodeNetworkConfigurationEnactmentConditionNetworkManagerTimeout ConditionReason = "NetworkManagerTimeout"
+	NodeNetworkConfigurationEnactmentConditionProbeFailed           ConditionReason = "ProbeFailed"
+	NodeNetworkConfigurationEnactmentConditionRollbackFailed        ConditionReason = "RollbackFailed"
+	NodeNetworkConfigurationEnactmentConditionCommitFailed          ConditionReason = "CommitFailed"
+	NodeNetworkConfigurationEnactmentConditionRevertFailed          ConditionReason = "RevertFailed"
 )
 
+// NodeNetworkConfigurationEnactmentFailingReasons are the reasons of the
+// Failing condition, FailedToConfigure is used for unclassified failures
+var NodeNetworkConfigurationEnactmentFailingReasons = [...]ConditionReason{
+	NodeNetworkConfigurationEnactmentConditionFailedToConfigure,
+	NodeNetworkConfigurationEnactmentConditionInvalidArgument,
+	NodeNetworkConfigurationEnactmentConditionDependencyError,
+	NodeNetworkConfigurationEnactmentConditionVerificationFailed,
+	NodeNetworkConfigurationEnactmentConditionNetworkManagerTimeout,
+	NodeNetworkConfigurationEnactmentConditionProbeFailed,
+	NodeNetworkConfigurationEnactmentConditionRollbackFailed,
+	NodeNetworkConfigurationEnactmentConditionCommitFailed,
+	NodeNetworkConfigurationEnactmentConditionRevertFailed,
+}
+
 func EnactmentKey(node string, policy string) types.NamespacedName {
 	return types.NamespacedName{Name: fmt.Sprintf("%s.%s", node, policy)}
 }
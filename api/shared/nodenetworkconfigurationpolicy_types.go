@@ -1,6 +1,9 @@
 package shared
 
-import "k8s.io/apimachinery/pkg/util/intstr"
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
 
 // NodeNetworkConfigurationPolicySpec defines the desired state of NodeNetworkConfigurationPolicy
 type NodeNetworkConfigurationPolicySpec struct {
@@ -10,6 +13,12 @@ type NodeNetworkConfigurationPolicySpec struct {
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 
+	// NodeLabelSelector is a label query over the nodes that supports set
+	// based requirements (In, NotIn, Exists and DoesNotExist). If
+	// nodeSelector is also set the node has to match both.
+	// +optional
+	NodeLabelSelector *metav1.LabelSelector `json:"nodeLabelSelector,omitempty"`
+
 	// +kubebuilder:validation:XPreserveUnknownFields
 	// The desired configuration of the policy
 	DesiredState State `json:"desiredState,omitempty"`
@@ -18,6 +27,204 @@ type NodeNetworkConfigurationPolicySpec struct {
 	// of machines that can be updating at a time. Default is "50%".
 	// +optional
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// Cleanup reverts the node network configuration done by the policy
+	// when the policy is deleted or the node stops matching its node
+	// selector. Interfaces created by the policy are removed and the
+	// modified ones are restored to the configuration they had before
+	// the policy was applied.
+	// +optional
+	Cleanup bool `json:"cleanup,omitempty"`
+	// DryRun checks that the desired state can be applied at the matching
+	// nodes without committing it. The desired state is applied, the probes
+	// are run and then the previous configuration is always rolled back.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// Paused stops the policy rollout, nodes that have not started to
+	// apply the desired state stay pending until the policy is resumed
+	// while nodes already applying it finish normally.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+	// Rollout configures a staged rollout of the policy, nodes are
+	// configured in ordered steps and each step starts once all the nodes
+	// from the previous one are configured. MaxUnavailable still limits
+	// the nodes configured at the same time inside a step.
+	// +optional
+	Rollout *NodeNetworkConfigurationPolicyRollout `json:"rollout,omitempty"`
+	// Remediation is what the handler does when the node configuration
+	// drifts from the applied desired state, with Reapply the policy is
+	// applied again at the drifted node. Default is None, drift is only
+	// reported.
+	// +optional
+	Remediation NodeNetworkConfigurationPolicyRemediation `json:"remediation,omitempty"`
+	// RevisionHistoryLimit is the number of policy revisions kept to
+	// rollback, default is 10.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+	// RollbackTo restores the policy spec from the given revision, 0 means
+	// the previous one. It is never stored, the restored spec is rolled
+	// out as any other policy change.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RollbackTo *int64 `json:"rollbackTo,omitempty"`
+	// Timeouts overrides the checkpoint and probe timeouts used to apply
+	// the desired state, unset ones are taken from the NMState CR or the
+	// defaults.
+	// +optional
+	Timeouts *NodeNetworkConfigurationPolicyTimeouts `json:"timeouts,omitempty"`
+	// Probes are connectivity checks that have to pass after applying the
+	// desired state, together with the builtin ones, before the
+	// configuration is committed, if one fails the configuration is
+	// rolled back.
+	// +optional
+	Probes []NodeNetworkConfigurationPolicyProbe `json:"probes,omitempty"`
+}
+
+// NodeNetworkConfigurationPolicyProbe is a connectivity check defined at a
+// policy
+type NodeNetworkConfigurationPolicyProbe struct {
+	// Name identifies the probe at the enactment probe results
+	Name string `json:"name"`
+	// Timeout is how long the probe is retried until it passes, default
+	// is 120s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	ProbeCheck `json:",inline"`
+}
+
+// ProbeCheck is what a probe checks, exactly one of icmp, tcp, http, dns or
+// interface has to be set.
+type ProbeCheck struct {
+	// ICMP pings a target
+	// +optional
+	ICMP *ICMPProbe `json:"icmp,omitempty"`
+	// TCP connects to a host and port
+	// +optional
+	TCP *TCPProbe `json:"tcp,omitempty"`
+	// HTTP does a GET request expecting a status code
+	// +optional
+	HTTP *HTTPProbe `json:"http,omitempty"`
+	// DNS resolves a host name
+	// +optional
+	DNS *DNSProbe `json:"dns,omitempty"`
+	// Interface checks that a node interface has carrier or an address
+	// +optional
+	Interface *InterfaceProbe `json:"interface,omitempty"`
+}
+
+// ICMPProbe pings a target
+type ICMPProbe struct {
+	// Target is the address or host name to ping
+	Target string `json:"target"`
+	// SourceInterface is the node interface used to send the ping
+	// +optional
+	SourceInterface string `json:"sourceInterface,omitempty"`
+}
+
+// TCPProbe opens a TCP connection
+type TCPProbe struct {
+	// Address is the host:port to connect to
+	Address string `json:"address"`
+	// SourceInterface is the node interface the connection is bound to
+	// +optional
+	SourceInterface string `json:"sourceInterface,omitempty"`
+}
+
+// HTTPProbe does an HTTP GET request
+type HTTPProbe struct {
+	// URL is the http or https URL to get
+	URL string `json:"url"`
+	// ExpectedStatus is the response status code, default is 200
+	// +optional
+	ExpectedStatus int32 `json:"expectedStatus,omitempty"`
+	// SourceInterface is the node interface the connection is bound to
+	// +optional
+	SourceInterface string `json:"sourceInterface,omitempty"`
+}
+
+// DNSProbe resolves a host name
+type DNSProbe struct {
+	// Host is the name to resolve
+	Host string `json:"host"`
+	// Server is the name server address, default is the node name servers
+	// +optional
+	Server string `json:"server,omitempty"`
+}
+
+// InterfaceProbe checks the node interface link and addresses
+type InterfaceProbe struct {
+	// Name is the node interface name
+	Name string `json:"name"`
+	// Carrier checks that the interface has carrier
+	// +optional
+	Carrier bool `json:"carrier,omitempty"`
+	// IPAddress checks that the interface has the address
+	// +optional
+	IPAddress string `json:"ipAddress,omitempty"`
+}
+
+// NodeNetworkConfigurationPolicyTimeouts are the timeouts used by the handler
+// to apply a desired state, all of them are 120s by default.
+type NodeNetworkConfigurationPolicyTimeouts struct {
+	// Checkpoint is how long the applied configuration is kept before it
+	// is rolled back if the probes do not finish, it has to be longer than
+	// the probes. Default is twice the sum of the default gateway, API
+	// server and policy probes timeouts.
+	// +optional
+	Checkpoint *metav1.Duration `json:"checkpoint,omitempty"`
+	// DefaultGatewayProbe is the time to wait for the default gateway to
+	// answer ping after applying the desired state
+	// +optional
+	DefaultGatewayProbe *metav1.Duration `json:"defaultGatewayProbe,omitempty"`
+	// DNSProbe is the time to wait for the name servers to resolve after
+	// applying the desired state
+	// +optional
+	DNSProbe *metav1.Duration `json:"dnsProbe,omitempty"`
+	// APIServerProbe is the time to wait for the API server to be
+	// reachable after applying the desired state
+	// +optional
+	APIServerProbe *metav1.Duration `json:"apiServerProbe,omitempty"`
+	// NodeReadinessProbe is the time to wait for the node to be ready
+	// after applying the desired state
+	// +optional
+	NodeReadinessProbe *metav1.Duration `json:"nodeReadinessProbe,omitempty"`
+}
+
+// NodeNetworkConfigurationPolicyRemediation is the action done when the node
+// configuration drifts from the policy
+// +kubebuilder:validation:Enum=None;Reapply
+type NodeNetworkConfigurationPolicyRemediation string
+
+const (
+	NodeNetworkConfigurationPolicyRemediationNone    NodeNetworkConfigurationPolicyRemediation = "None"
+	NodeNetworkConfigurationPolicyRemediationReapply NodeNetworkConfigurationPolicyRemediation = "Reapply"
+)
+
+// NodeNetworkConfigurationPolicyRollout defines the steps of a staged rollout
+type NodeNetworkConfigurationPolicyRollout struct {
+	// Steps are processed in order, nodes not covered by the steps are
+	// configured after the last one.
+	Steps []NodeNetworkConfigurationPolicyRolloutStep `json:"steps,omitempty"`
+	// NodeOrderLabel is the node label used to order the nodes along the
+	// steps, nodes without the label go first and the rest are sorted by
+	// the label value, nodes with the same value are sorted by name. For
+	// example node-role.kubernetes.io/master configures the control plane
+	// nodes last.
+	// +optional
+	NodeOrderLabel string `json:"nodeOrderLabel,omitempty"`
+}
+
+// NodeNetworkConfigurationPolicyRolloutStep is a stage of the rollout
+type NodeNetworkConfigurationPolicyRolloutStep struct {
+	// Nodes is the number or percentage of matching nodes that have the
+	// policy applied when the step finishes, previous steps included.
+	Nodes intstr.IntOrString `json:"nodes"`
+	// Pause is the time to wait after all the step nodes are configured
+	// before the next step starts, if some of them are failing or have
+	// drifted at the end of the pause the rollout is aborted.
+	// +optional
+	Pause *metav1.Duration `json:"pause,omitempty"`
 }
 
 // NodeNetworkConfigurationPolicyStatus defines the observed state of NodeNetworkConfigurationPolicy
@@ -30,6 +237,22 @@ type NodeNetworkConfigurationPolicyStatus struct {
 	UnavailableNodeCount int `json:"unavailableNodeCount,omitempty" optional:"true"`
 }
 
+const (
+	// NodeNetworkConfigurationPolicyCleanupFinalizer is kept at policies with
+	// cleanup until all the nodes have reverted its configuration
+	NodeNetworkConfigurationPolicyCleanupFinalizer = "nmstate.io/cleanup"
+
+	// NodeNetworkConfigurationPolicyAllowManagementChangesAnnotation set to
+	// "true" allows policies that change the interfaces carrying the nodes
+	// InternalIP or default route
+	NodeNetworkConfigurationPolicyAllowManagementChangesAnnotation = "nmstate.io/allow-management-changes"
+
+	// PolicyRevisionPolicyLabel holds the name of the policy a
+	// NodeNetworkConfigurationPolicyRevision was taken from, it is not the
+	// enactments label so selecting one kind never matches the other
+	PolicyRevisionPolicyLabel = "nmstate.io/policy-revision-of"
+)
+
 const (
 	NodeNetworkConfigurationPolicyConditionAvailable ConditionType = "Available"
 	NodeNetworkConfigurationPolicyConditionDegraded  ConditionType = "Degraded"
@@ -45,4 +268,7 @@ const (
 	NodeNetworkConfigurationPolicyConditionSuccessfullyConfigured      ConditionReason = "SuccessfullyConfigured"
 	NodeNetworkConfigurationPolicyConditionConfigurationProgressing    ConditionReason = "ConfigurationProgressing"
 	NodeNetworkConfigurationPolicyConditionConfigurationNoMatchingNode ConditionReason = "NoMatchingNode"
+	NodeNetworkConfigurationPolicyConditionDryRunConfigured            ConditionReason = "DryRunConfigured"
+	NodeNetworkConfigurationPolicyConditionDryRunFailedToConfigure     ConditionReason = "DryRunFailedToConfigure"
+	NodeNetworkConfigurationPolicyConditionPaused                      ConditionReason = "Paused"
 )
@@ -9,6 +9,13 @@ type NodeNetworkStateStatus struct {
 	// +kubebuilder:validation:XPreserveUnknownFields
 	CurrentState             State       `json:"currentState,omitempty"`
 	LastSuccessfulUpdateTime metav1.Time `json:"lastSuccessfulUpdateTime,omitempty"`
+	// LLDPNeighbors are the neighbors announced with LLDP at the interfaces
+	// with LLDP enabled, they are not filtered out of the state.
+	// +optional
+	LLDPNeighbors []LLDPNeighbor `json:"lldpNeighbors,omitempty"`
+	// Hardware is the inventory of the node physical NICs
+	// +optional
+	Hardware []InterfaceHardware `json:"hardware,omitempty"`
 
 	Conditions ConditionList `json:"conditions,omitempty" optional:"true"`
 }
@@ -22,3 +29,96 @@ const (
 	NodeNetworkStateConditionFailedToConfigure      ConditionReason = "FailedToConfigure"
 	NodeNetworkStateConditionSuccessfullyConfigured ConditionReason = "SuccessfullyConfigured"
 )
+
+// LLDPNeighbor is a switch port or host announced with LLDP at one of the node
+// interfaces
+type LLDPNeighbor struct {
+	// Interface is the node interface receiving the announcements
+	Interface string `json:"interface"`
+	// ChassisID identifies the neighbor, usually with its MAC address
+	// +optional
+	ChassisID string `json:"chassisID,omitempty"`
+	// PortID identifies the neighbor port connected to the interface
+	// +optional
+	PortID string `json:"portID,omitempty"`
+	// PortDescription describes the neighbor port
+	// +optional
+	PortDescription string `json:"portDescription,omitempty"`
+	// SystemName is the neighbor name
+	// +optional
+	SystemName string `json:"systemName,omitempty"`
+	// VLANs are the VLANs configured at the neighbor port
+	// +optional
+	VLANs []LLDPNeighborVLAN `json:"vlans,omitempty"`
+}
+
+// LLDPNeighborVLAN is a VLAN configured at a LLDP neighbor port
+type LLDPNeighborVLAN struct {
+	ID int `json:"id"`
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// InterfaceHardware is the hardware inventory of a physical NIC
+type InterfaceHardware struct {
+	// Interface is the NIC interface name
+	Interface string `json:"interface"`
+	// PCIAddress is the NIC PCI address, e.g. 0000:3b:00.0
+	// +optional
+	PCIAddress string `json:"pciAddress,omitempty"`
+	// +optional
+	Driver string `json:"driver,omitempty"`
+	// +optional
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
+	// Speed is the link speed in Mb/s, it is not set if the link is down
+	// +optional
+	Speed int `json:"speed,omitempty"`
+	// Duplex is full or half, it is not set if the link is down
+	// +optional
+	Duplex string `json:"duplex,omitempty"`
+	// SupportedLinkModes are the ethtool link modes supported by the NIC,
+	// e.g. 25000baseSR/Full
+	// +optional
+	SupportedLinkModes []string `json:"supportedLinkModes,omitempty"`
+	// NUMANode is the NUMA node the NIC is attached to, it is not set if
+	// the node has no NUMA
+	// +optional
+	NUMANode *int `json:"numaNode,omitempty"`
+	// SRIOVTotalVFs is the number of SR-IOV virtual functions the NIC
+	// supports
+	// +optional
+	SRIOVTotalVFs int `json:"sriovTotalVFs,omitempty"`
+	// PermanentMACAddress is the MAC address burnt into the NIC, it stays
+	// the same when the interface is a bond port
+	// +optional
+	PermanentMACAddress string `json:"permanentMACAddress,omitempty"`
+}
+
+// NodeNetworkStateFilter drops from the NodeNetworkState the interfaces,
+// routes and fields that change too often to be meaningful, on top of the
+// interfaces filter and the builtin dynamic attributes filter.
+type NodeNetworkStateFilter struct {
+	// InterfaceTypes are the types of the interfaces that are not reported,
+	// their routes are not reported either.
+	// +optional
+	InterfaceTypes []string `json:"interfaceTypes,omitempty"`
+	// RouteTables are the ids of the route tables whose routes are not
+	// reported.
+	// +optional
+	RouteTables []int `json:"routeTables,omitempty"`
+	// RouteProtocols are the protocols of the routes that are not reported,
+	// matched against the route protocol attribute, e.g. bgp. Routes without
+	// it are kept.
+	// +optional
+	RouteProtocols []string `json:"routeProtocols,omitempty"`
+	// Fields are the paths of the fields that are not reported, the keys
+	// are separated by dots and * matches every list item or map value,
+	// e.g. interfaces.*.ethtool.feature. Paths have to start with
+	// interfaces or routes.
+	// +optional
+	Fields []string `json:"fields,omitempty"`
+	// DynamicAddresses drops the addresses obtained with DHCP or autoconf,
+	// including the IPv6 temporary ones.
+	// +optional
+	DynamicAddresses bool `json:"dynamicAddresses,omitempty"`
+}
@@ -20,7 +20,10 @@ limitations under the License.
 
 package shared
 
-import ()
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
@@ -60,10 +63,138 @@ func (in ConditionList) DeepCopy() ConditionList {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSProbe) DeepCopyInto(out *DNSProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSProbe.
+func (in *DNSProbe) DeepCopy() *DNSProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPProbe) DeepCopyInto(out *HTTPProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProbe.
+func (in *HTTPProbe) DeepCopy() *HTTPProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ICMPProbe) DeepCopyInto(out *ICMPProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ICMPProbe.
+func (in *ICMPProbe) DeepCopy() *ICMPProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ICMPProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InterfaceHardware) DeepCopyInto(out *InterfaceHardware) {
+	*out = *in
+	if in.SupportedLinkModes != nil {
+		in, out := &in.SupportedLinkModes, &out.SupportedLinkModes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NUMANode != nil {
+		in, out := &in.NUMANode, &out.NUMANode
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceHardware.
+func (in *InterfaceHardware) DeepCopy() *InterfaceHardware {
+	if in == nil {
+		return nil
+	}
+	out := new(InterfaceHardware)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InterfaceProbe) DeepCopyInto(out *InterfaceProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceProbe.
+func (in *InterfaceProbe) DeepCopy() *InterfaceProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(InterfaceProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLDPNeighbor) DeepCopyInto(out *LLDPNeighbor) {
+	*out = *in
+	if in.VLANs != nil {
+		in, out := &in.VLANs, &out.VLANs
+		*out = make([]LLDPNeighborVLAN, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLDPNeighbor.
+func (in *LLDPNeighbor) DeepCopy() *LLDPNeighbor {
+	if in == nil {
+		return nil
+	}
+	out := new(LLDPNeighbor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LLDPNeighborVLAN) DeepCopyInto(out *LLDPNeighborVLAN) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLDPNeighborVLAN.
+func (in *LLDPNeighborVLAN) DeepCopy() *LLDPNeighborVLAN {
+	if in == nil {
+		return nil
+	}
+	out := new(LLDPNeighborVLAN)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeNetworkConfigurationEnactmentStatus) DeepCopyInto(out *NodeNetworkConfigurationEnactmentStatus) {
 	*out = *in
 	in.DesiredState.DeepCopyInto(&out.DesiredState)
+	in.RevertState.DeepCopyInto(&out.RevertState)
+	if in.ProbeResults != nil {
+		in, out := &in.ProbeResults, &out.ProbeResults
+		*out = make([]ProbeResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(ConditionList, len(*in))
@@ -83,6 +214,70 @@ func (in *NodeNetworkConfigurationEnactmentStatus) DeepCopy() *NodeNetworkConfig
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyProbe) DeepCopyInto(out *NodeNetworkConfigurationPolicyProbe) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	in.ProbeCheck.DeepCopyInto(&out.ProbeCheck)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyProbe.
+func (in *NodeNetworkConfigurationPolicyProbe) DeepCopy() *NodeNetworkConfigurationPolicyProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyRollout) DeepCopyInto(out *NodeNetworkConfigurationPolicyRollout) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]NodeNetworkConfigurationPolicyRolloutStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyRollout.
+func (in *NodeNetworkConfigurationPolicyRollout) DeepCopy() *NodeNetworkConfigurationPolicyRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyRolloutStep) DeepCopyInto(out *NodeNetworkConfigurationPolicyRolloutStep) {
+	*out = *in
+	out.Nodes = in.Nodes
+	if in.Pause != nil {
+		in, out := &in.Pause, &out.Pause
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyRolloutStep.
+func (in *NodeNetworkConfigurationPolicyRolloutStep) DeepCopy() *NodeNetworkConfigurationPolicyRolloutStep {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyRolloutStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeNetworkConfigurationPolicySpec) DeepCopyInto(out *NodeNetworkConfigurationPolicySpec) {
 	*out = *in
@@ -93,7 +288,44 @@ func (in *NodeNetworkConfigurationPolicySpec) DeepCopyInto(out *NodeNetworkConfi
 			(*out)[key] = val
 		}
 	}
+	if in.NodeLabelSelector != nil {
+		in, out := &in.NodeLabelSelector, &out.NodeLabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	in.DesiredState.DeepCopyInto(&out.DesiredState)
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(NodeNetworkConfigurationPolicyRollout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RollbackTo != nil {
+		in, out := &in.RollbackTo, &out.RollbackTo
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(NodeNetworkConfigurationPolicyTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = make([]NodeNetworkConfigurationPolicyProbe, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicySpec.
@@ -128,11 +360,100 @@ func (in *NodeNetworkConfigurationPolicyStatus) DeepCopy() *NodeNetworkConfigura
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyTimeouts) DeepCopyInto(out *NodeNetworkConfigurationPolicyTimeouts) {
+	*out = *in
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DefaultGatewayProbe != nil {
+		in, out := &in.DefaultGatewayProbe, &out.DefaultGatewayProbe
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DNSProbe != nil {
+		in, out := &in.DNSProbe, &out.DNSProbe
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.APIServerProbe != nil {
+		in, out := &in.APIServerProbe, &out.APIServerProbe
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.NodeReadinessProbe != nil {
+		in, out := &in.NodeReadinessProbe, &out.NodeReadinessProbe
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyTimeouts.
+func (in *NodeNetworkConfigurationPolicyTimeouts) DeepCopy() *NodeNetworkConfigurationPolicyTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkStateFilter) DeepCopyInto(out *NodeNetworkStateFilter) {
+	*out = *in
+	if in.InterfaceTypes != nil {
+		in, out := &in.InterfaceTypes, &out.InterfaceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RouteTables != nil {
+		in, out := &in.RouteTables, &out.RouteTables
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.RouteProtocols != nil {
+		in, out := &in.RouteProtocols, &out.RouteProtocols
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkStateFilter.
+func (in *NodeNetworkStateFilter) DeepCopy() *NodeNetworkStateFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkStateFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeNetworkStateStatus) DeepCopyInto(out *NodeNetworkStateStatus) {
 	*out = *in
 	in.CurrentState.DeepCopyInto(&out.CurrentState)
 	in.LastSuccessfulUpdateTime.DeepCopyInto(&out.LastSuccessfulUpdateTime)
+	if in.LLDPNeighbors != nil {
+		in, out := &in.LLDPNeighbors, &out.LLDPNeighbors
+		*out = make([]LLDPNeighbor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hardware != nil {
+		in, out := &in.Hardware, &out.Hardware
+		*out = make([]InterfaceHardware, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(ConditionList, len(*in))
@@ -152,6 +473,62 @@ func (in *NodeNetworkStateStatus) DeepCopy() *NodeNetworkStateStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeCheck) DeepCopyInto(out *ProbeCheck) {
+	*out = *in
+	if in.ICMP != nil {
+		in, out := &in.ICMP, &out.ICMP
+		*out = new(ICMPProbe)
+		**out = **in
+	}
+	if in.TCP != nil {
+		in, out := &in.TCP, &out.TCP
+		*out = new(TCPProbe)
+		**out = **in
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPProbe)
+		**out = **in
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSProbe)
+		**out = **in
+	}
+	if in.Interface != nil {
+		in, out := &in.Interface, &out.Interface
+		*out = new(InterfaceProbe)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeCheck.
+func (in *ProbeCheck) DeepCopy() *ProbeCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeResult) DeepCopyInto(out *ProbeResult) {
+	*out = *in
+	in.LastRunTime.DeepCopyInto(&out.LastRunTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeResult.
+func (in *ProbeResult) DeepCopy() *ProbeResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in RawState) DeepCopyInto(out *RawState) {
 	{
@@ -190,3 +567,18 @@ func (in *State) DeepCopy() *State {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPProbe) DeepCopyInto(out *TCPProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPProbe.
+func (in *TCPProbe) DeepCopy() *TCPProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPProbe)
+	in.DeepCopyInto(out)
+	return out
+}
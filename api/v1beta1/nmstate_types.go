@@ -29,6 +29,36 @@ type NMStateSpec struct {
 	// as labels applied to the node.
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Timeouts are the default checkpoint and probe timeouts for the
+	// policies that do not set them.
+	// +optional
+	Timeouts *shared.NodeNetworkConfigurationPolicyTimeouts `json:"timeouts,omitempty"`
+	// Handler configures the nmstate-handler DaemonSet, changing it rolls
+	// the handlers out again. The probes and checkpoint defaults are taken
+	// from Timeouts.
+	// +optional
+	Handler *NMStateHandlerSpec `json:"handler,omitempty"`
+}
+
+// NMStateHandlerSpec defines the nmstate-handler settings
+type NMStateHandlerSpec struct {
+	// InterfacesFilter are the globs of the interfaces that are not
+	// reported at NodeNetworkState, it defaults to veth* and cali*.
+	// +optional
+	InterfacesFilter []string `json:"interfacesFilter,omitempty"`
+	// RefreshPeriod is how often the NodeNetworkState is polled, it
+	// defaults to 15 minutes if the handler refreshes it on NetworkManager
+	// signals and to 1 minute otherwise.
+	// +optional
+	RefreshPeriod *metav1.Duration `json:"refreshPeriod,omitempty"`
+	// LogLevel of the handler, it defaults to production.
+	// +kubebuilder:validation:Enum=production;debug
+	// +optional
+	LogLevel string `json:"logLevel,omitempty"`
+	// StateFilter drops more interfaces, routes and fields from the
+	// NodeNetworkState so it is not updated when they change.
+	// +optional
+	StateFilter *shared.NodeNetworkStateFilter `json:"stateFilter,omitempty"`
 }
 
 // NMStateStatus defines the observed state of NMState
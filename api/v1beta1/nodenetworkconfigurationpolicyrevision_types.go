@@ -0,0 +1,69 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/names"
+)
+
+// +kubebuilder:object:root=true
+
+// NodeNetworkConfigurationPolicyRevisionList contains a list of NodeNetworkConfigurationPolicyRevision
+type NodeNetworkConfigurationPolicyRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeNetworkConfigurationPolicyRevision `json:"items"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=nodenetworkconfigurationpolicyrevisions,shortName=nncpr,scope=Cluster
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=".metadata.labels.nmstate\\.io/policy-revision-of",description="Policy"
+// +kubebuilder:printcolumn:name="Revision",type="integer",JSONPath=".revision",description="Revision"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:storageversion
+
+// NodeNetworkConfigurationPolicyRevision is an immutable snapshot of a
+// NodeNetworkConfigurationPolicy spec at one of its generations
+type NodeNetworkConfigurationPolicyRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Revision is the policy generation this revision was taken from
+	Revision int64 `json:"revision"`
+
+	// Spec is the policy spec at this revision
+	Spec shared.NodeNetworkConfigurationPolicySpec `json:"spec"`
+}
+
+// PolicyRevisionKey returns the key of the revision of a policy
+func PolicyRevisionKey(policyName string, revision int64) types.NamespacedName {
+	return types.NamespacedName{Name: fmt.Sprintf("%s.%d", policyName, revision)}
+}
+
+// NewPolicyRevision returns a revision of the policy at its current
+// generation, owned by the policy so it is removed with it.
+func NewPolicyRevision(policy NodeNetworkConfigurationPolicy) NodeNetworkConfigurationPolicyRevision {
+	spec := *policy.Spec.DeepCopy()
+	spec.RollbackTo = nil
+	controller := true
+	return NodeNetworkConfigurationPolicyRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: PolicyRevisionKey(policy.Name, policy.Generation).Name,
+			OwnerReferences: []metav1.OwnerReference{
+				{Name: policy.Name, Kind: "NodeNetworkConfigurationPolicy", APIVersion: GroupVersion.String(), UID: policy.UID, Controller: &controller},
+			},
+			Labels: names.IncludeRelationshipLabels(map[string]string{shared.PolicyRevisionPolicyLabel: policy.Name}),
+		},
+		Revision: policy.Generation,
+		Spec:     spec,
+	}
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeNetworkConfigurationPolicyRevision{}, &NodeNetworkConfigurationPolicyRevisionList{})
+}
@@ -0,0 +1,51 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// NodeNetworkProbeSpec defines the check and the nodes where it runs
+type NodeNetworkProbeSpec struct {
+	// NodeSelector is a selector which must be true for the probe to run
+	// on a node, the probe runs on every node if it is empty.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Timeout is how long the probe is retried until it passes, default
+	// is 120s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	shared.ProbeCheck `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodeNetworkProbeList contains a list of NodeNetworkProbe
+type NodeNetworkProbeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeNetworkProbe `json:"items"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=nodenetworkprobes,shortName=nnp,scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:storageversion
+
+// NodeNetworkProbe is a connectivity check that has to pass at every
+// matching node after applying any NodeNetworkConfigurationPolicy,
+// otherwise the node configuration is rolled back.
+type NodeNetworkProbe struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NodeNetworkProbeSpec `json:"spec,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeNetworkProbe{}, &NodeNetworkProbeList{})
+}
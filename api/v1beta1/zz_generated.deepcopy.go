@@ -22,6 +22,7 @@ package v1beta1
 
 import (
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -52,6 +53,36 @@ func (in *NMState) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NMStateHandlerSpec) DeepCopyInto(out *NMStateHandlerSpec) {
+	*out = *in
+	if in.InterfacesFilter != nil {
+		in, out := &in.InterfacesFilter, &out.InterfacesFilter
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RefreshPeriod != nil {
+		in, out := &in.RefreshPeriod, &out.RefreshPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.StateFilter != nil {
+		in, out := &in.StateFilter, &out.StateFilter
+		*out = new(shared.NodeNetworkStateFilter)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NMStateHandlerSpec.
+func (in *NMStateHandlerSpec) DeepCopy() *NMStateHandlerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NMStateHandlerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NMStateList) DeepCopyInto(out *NMStateList) {
 	*out = *in
@@ -94,6 +125,16 @@ func (in *NMStateSpec) DeepCopyInto(out *NMStateSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(shared.NodeNetworkConfigurationPolicyTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Handler != nil {
+		in, out := &in.Handler, &out.Handler
+		*out = new(NMStateHandlerSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NMStateSpec.
@@ -245,6 +286,150 @@ func (in *NodeNetworkConfigurationPolicyList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyRevision) DeepCopyInto(out *NodeNetworkConfigurationPolicyRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyRevision.
+func (in *NodeNetworkConfigurationPolicyRevision) DeepCopy() *NodeNetworkConfigurationPolicyRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeNetworkConfigurationPolicyRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkConfigurationPolicyRevisionList) DeepCopyInto(out *NodeNetworkConfigurationPolicyRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeNetworkConfigurationPolicyRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkConfigurationPolicyRevisionList.
+func (in *NodeNetworkConfigurationPolicyRevisionList) DeepCopy() *NodeNetworkConfigurationPolicyRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkConfigurationPolicyRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeNetworkConfigurationPolicyRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkProbe) DeepCopyInto(out *NodeNetworkProbe) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkProbe.
+func (in *NodeNetworkProbe) DeepCopy() *NodeNetworkProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeNetworkProbe) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkProbeList) DeepCopyInto(out *NodeNetworkProbeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeNetworkProbe, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkProbeList.
+func (in *NodeNetworkProbeList) DeepCopy() *NodeNetworkProbeList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkProbeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeNetworkProbeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNetworkProbeSpec) DeepCopyInto(out *NodeNetworkProbeSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	in.ProbeCheck.DeepCopyInto(&out.ProbeCheck)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNetworkProbeSpec.
+func (in *NodeNetworkProbeSpec) DeepCopy() *NodeNetworkProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNetworkProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeNetworkState) DeepCopyInto(out *NodeNetworkState) {
 	*out = *in
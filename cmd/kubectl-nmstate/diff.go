@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	yaml "sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+func newDiffCommand(newClient clientFactory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <node-a> <node-b>",
+		Short: "Compare the network state of two nodes",
+		Long: "Compare the NodeNetworkState current state of two nodes, interfaces are matched by\n" +
+			"name and routes by all their fields.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := newClient()
+			if err != nil {
+				return err
+			}
+			return diff(cli, cmd.OutOrStdout(), args[0], args[1])
+		},
+	}
+}
+
+func diff(cli client.Client, out io.Writer, nodeA, nodeB string) error {
+	states := []shared.State{}
+	for _, node := range []string{nodeA, nodeB} {
+		nns := nmstatev1beta1.NodeNetworkState{}
+		err := cli.Get(context.TODO(), types.NamespacedName{Name: node}, &nns)
+		if err != nil {
+			return err
+		}
+		states = append(states, nns.Status.CurrentState)
+	}
+
+	differences, err := stateDifferences(states[0], states[1])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", nodeA, nodeB)
+	for _, difference := range differences {
+		fmt.Fprintln(out, difference)
+	}
+	return nil
+}
+
+// stateDifferences returns the fields that differ between the states, the
+// ones of a prefixed with - and the ones of b with +
+func stateDifferences(a, b shared.State) ([]string, error) {
+	fieldsA, err := stateFields(a)
+	if err != nil {
+		return nil, err
+	}
+	fieldsB, err := stateFields(b)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]struct{}{}
+	for path := range fieldsA {
+		paths[path] = struct{}{}
+	}
+	for path := range fieldsB {
+		paths[path] = struct{}{}
+	}
+	sortedPaths := make([]string, 0, len(paths))
+	for path := range paths {
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.Strings(sortedPaths)
+
+	differences := []string{}
+	for _, path := range sortedPaths {
+		valueA, inA := fieldsA[path]
+		valueB, inB := fieldsB[path]
+		if inA && inB && valueA == valueB {
+			continue
+		}
+		if inA {
+			differences = append(differences, fmt.Sprintf("- %s: %s", path, valueA))
+		}
+		if inB {
+			differences = append(differences, fmt.Sprintf("+ %s: %s", path, valueB))
+		}
+	}
+	return differences, nil
+}
+
+// stateFields flattens the state into its fields paths, interfaces are keyed
+// by name and routes by their value since they have no name
+func stateFields(state shared.State) (map[string]string, error) {
+	unmarshaled := map[string]interface{}{}
+	if err := yaml.Unmarshal(state.Raw, &unmarshaled); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]string{}
+	for key, value := range unmarshaled {
+		switch key {
+		case "interfaces":
+			for _, ifaceRaw := range list(value) {
+				iface, ok := ifaceRaw.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				flatten(fmt.Sprintf("interfaces.%v", iface["name"]), iface, fields)
+			}
+		case "routes":
+			routes, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for routesKey, routesList := range routes {
+				for _, route := range list(routesList) {
+					fields[fmt.Sprintf("routes.%s[%s]", routesKey, toString(route))] = "present"
+				}
+			}
+		default:
+			flatten(key, value, fields)
+		}
+	}
+	return fields, nil
+}
+
+func flatten(path string, value interface{}, fields map[string]string) {
+	valueMap, ok := value.(map[string]interface{})
+	if !ok {
+		fields[path] = toString(value)
+		return
+	}
+	for key, nestedValue := range valueMap {
+		flatten(path+"."+key, nestedValue, fields)
+	}
+}
+
+func list(value interface{}) []interface{} {
+	if valueList, ok := value.([]interface{}); ok {
+		return valueList
+	}
+	return []interface{}{}
+}
+
+func toString(value interface{}) string {
+	if stringValue, ok := value.(string); ok {
+		return stringValue
+	}
+	marshaled, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(marshaled)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+// enactmentNode returns the node of the enactment, enactments are named
+// after their node and policy
+func enactmentNode(enactment nmstatev1beta1.NodeNetworkConfigurationEnactment) string {
+	policy := enactment.Labels[shared.EnactmentPolicyLabel]
+	return strings.TrimSuffix(enactment.Name, "."+policy)
+}
+
+// currentCondition returns the condition with status true, Drifted is only
+// set at available enactments so it is not their status
+func currentCondition(conditions shared.ConditionList) *shared.Condition {
+	for i, condition := range conditions {
+		if condition.Type == shared.NodeNetworkConfigurationEnactmentConditionDrifted {
+			continue
+		}
+		if condition.Status == corev1.ConditionTrue {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
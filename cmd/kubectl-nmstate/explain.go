@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+)
+
+func newExplainCommand(newClient clientFactory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain <enactment>",
+		Short: "Explain why an enactment failed",
+		Long: "Explain why an enactment failed, showing the nmstate error from the condition\n" +
+			"message and the probes that did not pass instead of the whole nmstatectl output.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := newClient()
+			if err != nil {
+				return err
+			}
+			return explain(cli, cmd.OutOrStdout(), args[0])
+		},
+	}
+}
+
+func explain(cli client.Client, out io.Writer, enactmentName string) error {
+	enactment := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err := cli.Get(context.TODO(), types.NamespacedName{Name: enactmentName}, &enactment)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Enactment:  %s\n", enactment.Name)
+	fmt.Fprintf(out, "Node:       %s\n", enactmentNode(enactment))
+	fmt.Fprintf(out, "Policy:     %s (generation %d)\n", enactment.Labels[shared.EnactmentPolicyLabel], enactment.Status.PolicyGeneration)
+
+	condition := currentCondition(enactment.Status.Conditions)
+	if condition == nil {
+		fmt.Fprintln(out, "Status:     Unknown")
+		return nil
+	}
+	fmt.Fprintf(out, "Status:     %s (%s) since %s\n", condition.Type, condition.Reason, condition.LastTransitionTime.UTC().Format(time.RFC3339))
+
+	if condition.Type != shared.NodeNetworkConfigurationEnactmentConditionFailing &&
+		condition.Type != shared.NodeNetworkConfigurationEnactmentConditionAborted &&
+		condition.Type != shared.NodeNetworkConfigurationEnactmentConditionDryRunFailed {
+		return nil
+	}
+	if condition.Message != "" {
+		fmt.Fprintf(out, "Cause:      %s\n", nmstatectl.ShortError(errors.New(condition.Message)))
+	}
+	if condition.Details != "" {
+		fmt.Fprintln(out, "Details:")
+		for _, line := range strings.Split(strings.TrimRight(condition.Details, "\n"), "\n") {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+
+	failedProbes := []shared.ProbeResult{}
+	for _, result := range enactment.Status.ProbeResults {
+		if !result.Passed {
+			failedProbes = append(failedProbes, result)
+		}
+	}
+	if len(failedProbes) > 0 {
+		fmt.Fprintln(out, "Failed probes:")
+		for _, result := range failedProbes {
+			fmt.Fprintf(out, "  %s (%s): %s\n", result.Name, result.Source, result.Message)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+)
+
+// historyEntry is an enactment condition transition or an event of the node
+type historyEntry struct {
+	time    time.Time
+	policy  string
+	status  string
+	message string
+}
+
+func newHistoryCommand(newClient clientFactory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "history <node>",
+		Short: "Show the policies applied at a node and the handler events",
+		Long: "Show the enactments of the node with the time of their last transition together\n" +
+			"with the events the handler recorded for the node, like probe failures and rollbacks.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := newClient()
+			if err != nil {
+				return err
+			}
+			return history(cli, cmd.OutOrStdout(), args[0])
+		},
+	}
+}
+
+func history(cli client.Client, out io.Writer, nodeName string) error {
+	entries := []historyEntry{}
+
+	enactments := nmstatev1beta1.NodeNetworkConfigurationEnactmentList{}
+	err := cli.List(context.TODO(), &enactments)
+	if err != nil {
+		return err
+	}
+	for _, enactment := range enactments.Items {
+		if enactmentNode(enactment) != nodeName {
+			continue
+		}
+		condition := currentCondition(enactment.Status.Conditions)
+		if condition == nil {
+			continue
+		}
+		entry := historyEntry{
+			time:   condition.LastTransitionTime.Time,
+			policy: fmt.Sprintf("%s@%d", enactment.Labels[shared.EnactmentPolicyLabel], enactment.Status.PolicyGeneration),
+			status: fmt.Sprintf("%s/%s", condition.Type, condition.Reason),
+		}
+		if condition.Message != "" {
+			entry.message = nmstatectl.ShortError(errors.New(condition.Message))
+		}
+		entries = append(entries, entry)
+	}
+
+	events := corev1.EventList{}
+	err = cli.List(context.TODO(), &events, client.MatchingFieldsSelector{Selector: fields.SelectorFromSet(fields.Set{
+		"involvedObject.kind": "Node",
+		"involvedObject.name": nodeName,
+	})})
+	if err != nil {
+		return err
+	}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name != nodeName || event.Source.Component != "nmstate-handler" {
+			continue
+		}
+		entries = append(entries, historyEntry{
+			time:    event.LastTimestamp.Time,
+			status:  fmt.Sprintf("%s/%s", event.Type, event.Reason),
+			message: event.Message,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].time.Before(entries[j].time)
+	})
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tPOLICY\tSTATUS\tMESSAGE")
+	for _, entry := range entries {
+		policy := entry.policy
+		if policy == "" {
+			policy = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.time.UTC().Format(time.RFC3339), policy, entry.status, entry.message)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.kubectl-nmstate-kubectl-nmstate_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "kubectl-nmstate Test Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+var transitionTime = metav1.NewTime(time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC))
+
+func enactment(node, policy string, conditionType shared.ConditionType, reason shared.ConditionReason, message string) *nmstatev1beta1.NodeNetworkConfigurationEnactment {
+	return &nmstatev1beta1.NodeNetworkConfigurationEnactment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   shared.EnactmentKey(node, policy).Name,
+			Labels: map[string]string{shared.EnactmentPolicyLabel: policy},
+		},
+		Status: shared.NodeNetworkConfigurationEnactmentStatus{
+			PolicyGeneration: 2,
+			Conditions: shared.ConditionList{
+				{
+					Type:               conditionType,
+					Status:             corev1.ConditionTrue,
+					Reason:             reason,
+					Message:            message,
+					LastTransitionTime: transitionTime,
+				},
+			},
+		},
+	}
+}
+
+func nodeNetworkState(node, currentState string) *nmstatev1beta1.NodeNetworkState {
+	return &nmstatev1beta1.NodeNetworkState{
+		ObjectMeta: metav1.ObjectMeta{Name: node},
+		Status: shared.NodeNetworkStateStatus{
+			CurrentState: shared.NewState(currentState),
+		},
+	}
+}
+
+func run(objs []runtime.Object, args ...string) (string, error) {
+	out := &bytes.Buffer{}
+	root := newRootCommand(func() (client.Client, error) {
+		return fake.NewFakeClientWithScheme(scheme, objs...), nil
+	}, out)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+var _ = Describe("kubectl-nmstate", func() {
+	Context("status", func() {
+		It("should show the policy condition at every node", func() {
+			out, err := run([]runtime.Object{
+				enactment("node02", "br1", shared.NodeNetworkConfigurationEnactmentConditionFailing, shared.NodeNetworkConfigurationEnactmentConditionFailedToConfigure, "error reconciling NodeNetworkConfigurationPolicy: failed to execute nmstatectl set: 'exit status 1' '' 'NmstateValueError: Invalid bond mode'"),
+				enactment("node01", "br1", shared.NodeNetworkConfigurationEnactmentConditionAvailable, shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured, ""),
+				enactment("node01", "other", shared.NodeNetworkConfigurationEnactmentConditionAvailable, shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured, ""),
+			}, "status", "br1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(MatchRegexp(`(?s)NODE +STATUS +REASON +GENERATION +MESSAGE\n` +
+				`node01 +Available +SuccessfullyConfigured +2 +\n` +
+				`node02 +Failing +FailedToConfigure +2 +NmstateValueError: Invalid bond mode\n$`))
+		})
+		It("should fail if the policy has no enactments", func() {
+			_, err := run([]runtime.Object{}, "status", "br1")
+			Expect(err).To(MatchError("no enactments found for policy br1"))
+		})
+	})
+
+	Context("diff", func() {
+		It("should show the fields that differ between the nodes", func() {
+			out, err := run([]runtime.Object{
+				nodeNetworkState("node01", `interfaces:
+- name: eth0
+  type: ethernet
+  mtu: 1500
+- name: eth1
+  type: ethernet
+  mtu: 1500
+routes:
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth0
+`),
+				nodeNetworkState("node02", `interfaces:
+- name: eth0
+  type: ethernet
+  mtu: 9000
+routes:
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth0
+`),
+			}, "diff", "node01", "node02")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(Equal(`--- node01
++++ node02
+- interfaces.eth0.mtu: 1500
++ interfaces.eth0.mtu: 9000
+- interfaces.eth1.mtu: 1500
+- interfaces.eth1.name: eth1
+- interfaces.eth1.type: ethernet
+`))
+		})
+	})
+
+	Context("explain", func() {
+		It("should show the short nmstate error and the failed probes", func() {
+			failing := enactment("node01", "br1", shared.NodeNetworkConfigurationEnactmentConditionFailing, shared.NodeNetworkConfigurationEnactmentConditionFailedToConfigure, "error reconciling NodeNetworkConfigurationPolicy: failed to execute nmstatectl set: 'exit status 1' '' 'NmstateValueError: Invalid bond mode'")
+			failing.Status.ProbeResults = []shared.ProbeResult{
+				{Name: "ping-gateway", Source: shared.ProbeSourcePolicy, Passed: false, Message: "no reply from 192.168.66.2"},
+				{Name: "api-server", Source: shared.ProbeSourceNodeNetworkProbe, Passed: true},
+			}
+			out, err := run([]runtime.Object{failing}, "explain", "node01.br1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(Equal(`Enactment:  node01.br1
+Node:       node01
+Policy:     br1 (generation 2)
+Status:     Failing (FailedToConfigure) since 2021-06-01T10:00:00Z
+Cause:      NmstateValueError: Invalid bond mode
+Failed probes:
+  ping-gateway (Policy): no reply from 192.168.66.2
+`))
+		})
+		It("should show the failure details", func() {
+			failing := enactment("node01", "br1", shared.NodeNetworkConfigurationEnactmentConditionFailing, shared.NodeNetworkConfigurationEnactmentConditionInvalidArgument, "NmstateValueError: Invalid bond mode")
+			failing.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionFailing).Details = "failed to execute nmstatectl set: 'exit status 1' ''\n'NmstateValueError: Invalid bond mode'"
+			out, err := run([]runtime.Object{failing}, "explain", "node01.br1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(HaveSuffix(`Status:     Failing (InvalidArgument) since 2021-06-01T10:00:00Z
+Cause:      NmstateValueError: Invalid bond mode
+Details:
+  failed to execute nmstatectl set: 'exit status 1' ''
+  'NmstateValueError: Invalid bond mode'
+`))
+		})
+		It("should not show a cause for available enactments", func() {
+			out, err := run([]runtime.Object{
+				enactment("node01", "br1", shared.NodeNetworkConfigurationEnactmentConditionAvailable, shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured, "successfully reconciled"),
+			}, "explain", "node01.br1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).ToNot(ContainSubstring("Cause"))
+		})
+	})
+
+	Context("history", func() {
+		It("should show the node enactments and handler events sorted by time", func() {
+			event := &corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{Name: "node01.rollback", Namespace: "default"},
+				InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "node01"},
+				Source:         corev1.EventSource{Component: "nmstate-handler"},
+				Type:           corev1.EventTypeWarning,
+				Reason:         "ConfigurationFailed",
+				Message:        "rolled back br1",
+				LastTimestamp:  metav1.NewTime(transitionTime.Add(-time.Minute)),
+			}
+			out, err := run([]runtime.Object{
+				event,
+				enactment("node01", "br1", shared.NodeNetworkConfigurationEnactmentConditionAvailable, shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured, ""),
+				enactment("node02", "br1", shared.NodeNetworkConfigurationEnactmentConditionAvailable, shared.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured, ""),
+			}, "history", "node01")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(MatchRegexp(`(?s)TIME +POLICY +STATUS +MESSAGE\n` +
+				`2021-06-01T09:59:00Z +- +Warning/ConfigurationFailed +rolled back br1\n` +
+				`2021-06-01T10:00:00Z +br1@2 +Available/SuccessfullyConfigured +\n$`))
+		})
+	})
+})
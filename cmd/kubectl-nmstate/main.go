@@ -0,0 +1,65 @@
+package main
+
+import (
+	goflag "flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+var (
+	scheme = runtime.NewScheme()
+)
+
+func init() {
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(nmstatev1beta1.AddToScheme(scheme))
+}
+
+// clientFactory returns the client the commands use, tests replace it with a
+// fake one
+type clientFactory func() (client.Client, error)
+
+func newClient() (client.Client, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
+
+func newRootCommand(newClient clientFactory, out io.Writer) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "kubectl-nmstate",
+		Short:         "Inspect and validate kubernetes-nmstate policies, enactments and node network states",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	// --kubeconfig is registered by controller-runtime at the go flags
+	root.PersistentFlags().AddGoFlagSet(goflag.CommandLine)
+	root.SetOut(out)
+	root.AddCommand(
+		newStatusCommand(newClient),
+		newDiffCommand(newClient),
+		newExplainCommand(newClient),
+		newHistoryCommand(newClient),
+		newValidateCommand(),
+	)
+	return root
+}
+
+func main() {
+	if err := newRootCommand(newClient, os.Stdout).Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
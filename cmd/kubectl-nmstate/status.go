@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+)
+
+func newStatusCommand(newClient clientFactory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <policy>",
+		Short: "Show the policy status at every node",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cli, err := newClient()
+			if err != nil {
+				return err
+			}
+			return status(cli, cmd.OutOrStdout(), args[0])
+		},
+	}
+}
+
+func status(cli client.Client, out io.Writer, policyName string) error {
+	enactments := nmstatev1beta1.NodeNetworkConfigurationEnactmentList{}
+	err := cli.List(context.TODO(), &enactments, client.MatchingLabels{shared.EnactmentPolicyLabel: policyName})
+	if err != nil {
+		return err
+	}
+	if len(enactments.Items) == 0 {
+		return fmt.Errorf("no enactments found for policy %s", policyName)
+	}
+	sort.Slice(enactments.Items, func(i, j int) bool {
+		return enactmentNode(enactments.Items[i]) < enactmentNode(enactments.Items[j])
+	})
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tSTATUS\tREASON\tGENERATION\tMESSAGE")
+	for _, enactment := range enactments.Items {
+		status, reason, message := "Unknown", "", ""
+		if condition := currentCondition(enactment.Status.Conditions); condition != nil {
+			status, reason = string(condition.Type), string(condition.Reason)
+			if condition.Message != "" {
+				message = nmstatectl.ShortError(errors.New(condition.Message))
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", enactmentNode(enactment), status, reason, enactment.Status.PolicyGeneration, message)
+	}
+	return w.Flush()
+}
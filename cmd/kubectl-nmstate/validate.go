@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/policytemplate"
+	"github.com/nmstate/kubernetes-nmstate/pkg/selectors"
+	webhook "github.com/nmstate/kubernetes-nmstate/pkg/webhook/nodenetworkconfigurationpolicy"
+)
+
+// capturedObjects are the objects read from the files passed to validate
+type capturedObjects struct {
+	nodes    []corev1.Node
+	states   []nmstatev1beta1.NodeNetworkState
+	policies []nmstatev1beta1.NodeNetworkConfigurationPolicy
+}
+
+func newValidateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate <policy file> [<objects file>...]",
+		Short: "Validate a policy and render its desired state without a cluster",
+		Long: "Validate a policy against captured NodeNetworkStates without a cluster, showing the\n" +
+			"nodes it matches, the desired state rendered for each of them and the webhook\n" +
+			"validations that fail. The objects files can have NodeNetworkStates, Nodes and\n" +
+			"other policies, as YAML documents or lists from kubectl get -o yaml. The Nodes\n" +
+			"are needed to match labels other than kubernetes.io/hostname and to check the\n" +
+			"management connectivity, the other policies to check conflicts.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return validate(cmd.OutOrStdout(), args[0], args[1:])
+		},
+	}
+}
+
+func validate(out io.Writer, policyFile string, objectsFiles []string) error {
+	policyObjects, err := readObjects(policyFile)
+	if err != nil {
+		return err
+	}
+	if len(policyObjects.policies) != 1 {
+		return fmt.Errorf("expected one NodeNetworkConfigurationPolicy at %s, found %d", policyFile, len(policyObjects.policies))
+	}
+	policy := policyObjects.policies[0]
+
+	captured := capturedObjects{}
+	for _, objectsFile := range objectsFiles {
+		objects, err := readObjects(objectsFile)
+		if err != nil {
+			return err
+		}
+		captured.nodes = append(captured.nodes, objects.nodes...)
+		captured.states = append(captured.states, objects.states...)
+		captured.policies = append(captured.policies, objects.policies...)
+	}
+	nodes := capturedNodes(captured)
+
+	objs := []runtime.Object{}
+	for i := range nodes {
+		objs = append(objs, &nodes[i])
+	}
+	states := map[string]nmstatev1beta1.NodeNetworkState{}
+	for i, nns := range captured.states {
+		states[nns.Name] = nns
+		objs = append(objs, &captured.states[i])
+	}
+	for i, otherPolicy := range captured.policies {
+		if otherPolicy.Name != policy.Name {
+			objs = append(objs, &captured.policies[i])
+		}
+	}
+	cli := fake.NewFakeClientWithScheme(scheme, objs...)
+
+	failed := false
+	nodeSelector, err := selectors.NodeSelector(policy)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tMATCHES")
+	matchingNodes := []corev1.Node{}
+	for _, node := range nodes {
+		matches := nodeSelector.Matches(labels.Set(node.Labels))
+		if matches {
+			matchingNodes = append(matchingNodes, node)
+		}
+		fmt.Fprintf(w, "%s\t%t\n", node.Name, matches)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, node := range matchingNodes {
+		fmt.Fprintf(out, "\n# %s\n", node.Name)
+		nns, found := states[node.Name]
+		if !found {
+			if policytemplate.IsTemplate(policy.Spec.DesiredState) {
+				failed = true
+				fmt.Fprintf(out, "failed rendering desired state: no NodeNetworkState for node %s\n", node.Name)
+				continue
+			}
+			// Without templates the desired state is the same at every
+			// node, it is shown as is
+			fmt.Fprintf(out, "# not rendered: no NodeNetworkState for node %s\n", node.Name)
+		}
+		desiredState, err := policytemplate.Render(policy.Spec.DesiredState, node, nns.Status.CurrentState)
+		if err != nil {
+			failed = true
+			fmt.Fprintf(out, "failed rendering desired state: %v\n", err)
+			continue
+		}
+		fmt.Fprint(out, desiredState.String())
+	}
+
+	causes := webhook.ValidatePolicy(cli, policy)
+	if len(causes) > 0 {
+		failed = true
+		fmt.Fprintln(out, "\nValidation errors:")
+		for _, cause := range causes {
+			fmt.Fprintf(out, "  %s\n", causeMessage(cause))
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("policy %s is not valid", policy.Name)
+	}
+	return nil
+}
+
+// capturedNodes returns the captured nodes sorted by name, together with a
+// node labeled only with its hostname for every state without its node
+func capturedNodes(captured capturedObjects) []corev1.Node {
+	nodes := []corev1.Node{}
+	nodeNames := map[string]bool{}
+	for _, node := range captured.nodes {
+		if !nodeNames[node.Name] {
+			nodes = append(nodes, node)
+			nodeNames[node.Name] = true
+		}
+	}
+	for _, nns := range captured.states {
+		if !nodeNames[nns.Name] {
+			nodes = append(nodes, corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   nns.Name,
+					Labels: map[string]string{corev1.LabelHostname: nns.Name},
+				},
+			})
+			nodeNames[nns.Name] = true
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
+
+func causeMessage(cause metav1.StatusCause) string {
+	if cause.Field == "" || strings.HasPrefix(cause.Message, cause.Field) {
+		return cause.Message
+	}
+	return fmt.Sprintf("%s: %s", cause.Field, cause.Message)
+}
+
+// readObjects decodes the nodes, node network states and policies from a
+// YAML or JSON file with one or more documents, the documents can be lists
+func readObjects(file string) (capturedObjects, error) {
+	objects := capturedObjects{}
+	f, err := os.Open(file)
+	if err != nil {
+		return objects, err
+	}
+	defer f.Close()
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		document := map[string]interface{}{}
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return objects, errors.Wrapf(err, "failed decoding %s", file)
+		}
+		if len(document) == 0 {
+			continue
+		}
+
+		items := []unstructured.Unstructured{{Object: document}}
+		if items[0].IsList() {
+			list, err := items[0].ToList()
+			if err != nil {
+				return objects, errors.Wrapf(err, "failed decoding list at %s", file)
+			}
+			items = list.Items
+		}
+		for _, item := range items {
+			if err := objects.add(item); err != nil {
+				return objects, errors.Wrapf(err, "failed decoding %s", file)
+			}
+		}
+	}
+}
+
+func (o *capturedObjects) add(item unstructured.Unstructured) error {
+	converter := runtime.DefaultUnstructuredConverter
+	switch item.GetKind() {
+	case "Node":
+		node := corev1.Node{}
+		if err := converter.FromUnstructured(item.Object, &node); err != nil {
+			return err
+		}
+		o.nodes = append(o.nodes, node)
+	case "NodeNetworkState":
+		nns := nmstatev1beta1.NodeNetworkState{}
+		if err := converter.FromUnstructured(item.Object, &nns); err != nil {
+			return err
+		}
+		o.states = append(o.states, nns)
+	case "NodeNetworkConfigurationPolicy":
+		policy := nmstatev1beta1.NodeNetworkConfigurationPolicy{}
+		if err := converter.FromUnstructured(item.Object, &policy); err != nil {
+			return err
+		}
+		o.policies = append(o.policies, policy)
+	default:
+		return fmt.Errorf("unsupported kind %q of %s", item.GetKind(), item.GetName())
+	}
+	return nil
+}
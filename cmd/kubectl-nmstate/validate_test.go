@@ -0,0 +1,181 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("validate", func() {
+	const (
+		statesList = `apiVersion: v1
+kind: List
+items:
+- apiVersion: nmstate.io/v1beta1
+  kind: NodeNetworkState
+  metadata:
+    name: node01
+  status:
+    currentState:
+      interfaces:
+      - name: eth0
+        type: ethernet
+        state: up
+      - name: eth1
+        type: ethernet
+        state: up
+        mac-address: 52:55:00:D1:56:01
+      routes:
+        running:
+        - destination: 0.0.0.0/0
+          next-hop-address: 192.168.66.1
+          next-hop-interface: eth0
+- apiVersion: nmstate.io/v1beta1
+  kind: NodeNetworkState
+  metadata:
+    name: node02
+  status:
+    currentState:
+      interfaces:
+      - name: eth0
+        type: ethernet
+        state: up
+      - name: eth1
+        type: ethernet
+        state: up
+        mac-address: 52:55:00:D1:56:02
+      routes:
+        running:
+        - destination: 0.0.0.0/0
+          next-hop-address: 192.168.66.1
+          next-hop-interface: eth0
+`
+		nodes = `apiVersion: v1
+kind: Node
+metadata:
+  name: node01
+  labels:
+    role: worker
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: node02
+  labels:
+    role: master
+`
+	)
+	var dir string
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "kubectl-nmstate-validate")
+		Expect(err).ToNot(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("should show the matching nodes and their rendered desired state", func() {
+		policy := writeFile("policy.yaml", `apiVersion: nmstate.io/v1beta1
+kind: NodeNetworkConfigurationPolicy
+metadata:
+  name: eth1-mac
+spec:
+  nodeSelector:
+    role: worker
+  desiredState:
+    interfaces:
+    - name: eth1
+      type: ethernet
+      state: up
+      mac-address: '{{ currentState "interfaces.#(name==\"eth1\").mac-address" }}'
+`)
+		out, err := run(nil, "validate", policy, writeFile("states.yaml", statesList), writeFile("nodes.yaml", nodes))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(MatchRegexp(`(?s)^NODE +MATCHES\n` +
+			`node01 +true\n` +
+			`node02 +false\n` +
+			`\n# node01\n` +
+			`interfaces:\n` +
+			`- mac-address: 52:55:00:D1:56:01\n` +
+			`  name: eth1\n` +
+			`  state: up\n` +
+			`  type: ethernet\n$`))
+	})
+
+	It("should match nodes without a Node object by hostname", func() {
+		policy := writeFile("policy.yaml", `apiVersion: nmstate.io/v1beta1
+kind: NodeNetworkConfigurationPolicy
+metadata:
+  name: eth1-down
+spec:
+  nodeSelector:
+    kubernetes.io/hostname: node02
+  desiredState:
+    interfaces:
+    - name: eth1
+      type: ethernet
+      state: down
+`)
+		out, err := run(nil, "validate", policy, writeFile("states.yaml", statesList))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(MatchRegexp(`node01 +false\nnode02 +true\n\n# node02\n`))
+	})
+
+	It("should say which nodes are not rendered for lack of NodeNetworkState", func() {
+		policy := writeFile("policy.yaml", `apiVersion: nmstate.io/v1beta1
+kind: NodeNetworkConfigurationPolicy
+metadata:
+  name: eth1-down
+spec:
+  desiredState:
+    interfaces:
+    - name: eth1
+      type: ethernet
+      state: down
+`)
+		out, err := run(nil, "validate", policy, writeFile("nodes.yaml", nodes))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(ContainSubstring("\n# node01\n# not rendered: no NodeNetworkState for node node01\ninterfaces:\n"))
+		Expect(out).To(ContainSubstring("\n# node02\n# not rendered: no NodeNetworkState for node node02\ninterfaces:\n"))
+	})
+
+	It("should fail with the webhook validations and the rendering errors", func() {
+		policy := writeFile("policy.yaml", `apiVersion: nmstate.io/v1beta1
+kind: NodeNetworkConfigurationPolicy
+metadata:
+  name: eth1-address
+spec:
+  desiredState:
+    interfaces:
+    - name: eth1
+      type: ethernet
+      state: up
+      mtu: '{{ .Node.Labels.mtu | toInt }}'
+      ipv4:
+        enabled: true
+        address:
+        - ip: 10.244.0.300
+          prefix-length: 24
+`)
+		out, err := run(nil, "validate", policy, writeFile("states.yaml", statesList), writeFile("nodes.yaml", nodes))
+		Expect(err).To(MatchError("policy eth1-address is not valid"))
+		Expect(out).To(ContainSubstring("# node01\nfailed rendering desired state: "))
+		Expect(out).To(ContainSubstring("\nValidation errors:\n  spec.desiredState.interfaces[0].ipv4.address[0].ip: Invalid value: \"10.244.0.300\": must be a valid IPv4 address\n"))
+	})
+
+	It("should fail if the policy file has no policy", func() {
+		_, err := run(nil, "validate", writeFile("states.yaml", statesList))
+		Expect(err).To(MatchError(ContainSubstring("expected one NodeNetworkConfigurationPolicy")))
+	})
+})
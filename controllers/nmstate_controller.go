@@ -19,9 +19,11 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -40,6 +42,7 @@ import (
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
 	"github.com/nmstate/kubernetes-nmstate/pkg/names"
 	nmstaterenderer "github.com/nmstate/kubernetes-nmstate/pkg/render"
+	"github.com/nmstate/kubernetes-nmstate/pkg/state"
 )
 
 // NMStateReconciler reconciles a NMState object
@@ -178,6 +181,35 @@ func (r *NMStateReconciler) applyHandler(instance *nmstatev1beta1.NMState) error
 	data.Data["HandlerNodeSelector"] = amd64AndCRNodeSelector
 	data.Data["HandlerTolerations"] = []corev1.Toleration{operatorExistsToleration}
 	data.Data["HandlerAffinity"] = corev1.Affinity{}
+	data.Data["HandlerMetricsBindAddress"] = os.Getenv("HANDLER_METRICS_BIND_ADDRESS")
+	data.Data["HandlerNmstateBackend"] = os.Getenv("HANDLER_NMSTATE_BACKEND")
+	data.Data["HandlerInterfacesFilter"] = state.DefaultInterfacesFilter
+	data.Data["HandlerRefreshPeriod"] = ""
+	data.Data["HandlerLogLevel"] = ""
+	data.Data["HandlerStateFilter"] = ""
+	if handler := instance.Spec.Handler; handler != nil {
+		if handler.InterfacesFilter != nil {
+			interfacesFilter := interfacesFilterGlob(handler.InterfacesFilter)
+			if _, err := state.CompileInterfacesFilter(interfacesFilter); err != nil {
+				return errors.Wrapf(err, "invalid handler interfaces filter %q", interfacesFilter)
+			}
+			data.Data["HandlerInterfacesFilter"] = interfacesFilter
+		}
+		if handler.RefreshPeriod != nil {
+			data.Data["HandlerRefreshPeriod"] = handler.RefreshPeriod.Duration.String()
+		}
+		data.Data["HandlerLogLevel"] = handler.LogLevel
+		if handler.StateFilter != nil {
+			if err := state.ValidateStateFilter(*handler.StateFilter); err != nil {
+				return errors.Wrap(err, "invalid handler state filter")
+			}
+			stateFilter, err := json.Marshal(handler.StateFilter)
+			if err != nil {
+				return errors.Wrap(err, "failed marshaling handler state filter")
+			}
+			data.Data["HandlerStateFilter"] = string(stateFilter)
+		}
+	}
 	// TODO: This is just a place holder to make template renderer happy
 	//       proper variable has to be read from env or CR
 	data.Data["CARotateInterval"] = ""
@@ -187,6 +219,18 @@ func (r *NMStateReconciler) applyHandler(instance *nmstatev1beta1.NMState) error
 	return r.renderAndApply(instance, data, "handler", true)
 }
 
+// interfacesFilterGlob joins the interfaces filter globs into the one the
+// handler expects at INTERFACES_FILTER
+func interfacesFilterGlob(globs []string) string {
+	if len(globs) == 1 {
+		return globs[0]
+	}
+	if len(globs) > 1 {
+		return "{" + strings.Join(globs, ",") + "}"
+	}
+	return ""
+}
+
 func (r *NMStateReconciler) renderAndApply(instance *nmstatev1beta1.NMState, data render.RenderData, sourceDirectory string, setControllerReference bool) error {
 	var err error
 	objs := []*uns.Unstructured{}
@@ -7,20 +7,24 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
 	"github.com/nmstate/kubernetes-nmstate/pkg/names"
 )
@@ -163,6 +167,140 @@ var _ = Describe("NMState controller reconcile", func() {
 			}
 		})
 	})
+	Context("when operator spec has handler settings", func() {
+		var (
+			handlerSpec *nmstatev1beta1.NMStateHandlerSpec
+			container   corev1.Container
+		)
+		JustBeforeEach(func() {
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NMState{},
+			)
+			instance := nmstate.DeepCopy()
+			instance.Spec.Handler = handlerSpec
+			cl = fake.NewFakeClientWithScheme(s, instance)
+			reconciler.Client = cl
+			request := ctrl.Request{}
+			request.Name = existingNMStateName
+			result, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+
+			ds := &appsv1.DaemonSet{}
+			handlerKey := types.NamespacedName{Namespace: handlerNamespace, Name: handlerPrefix + "-nmstate-handler"}
+			err = cl.Get(context.TODO(), handlerKey, ds)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ds.Spec.Template.Spec.Containers).To(HaveLen(1))
+			container = ds.Spec.Template.Spec.Containers[0]
+		})
+		Context("and they are not set", func() {
+			BeforeEach(func() {
+				handlerSpec = nil
+			})
+			It("should render the handler defaults", func() {
+				Expect(container.Args).To(ContainElement("--v=production"))
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "INTERFACES_FILTER", Value: "{veth*,cali*}"}))
+				for _, env := range container.Env {
+					Expect(env.Name).ToNot(BeElementOf("NETWORK_STATE_REFRESH_PERIOD", "STATE_FILTER"))
+				}
+			})
+		})
+		Context("and they are set", func() {
+			BeforeEach(func() {
+				handlerSpec = &nmstatev1beta1.NMStateHandlerSpec{
+					InterfacesFilter: []string{"veth*", "cali*", "tap*"},
+					RefreshPeriod:    &metav1.Duration{Duration: 5 * time.Minute},
+					LogLevel:         "debug",
+					StateFilter: &shared.NodeNetworkStateFilter{
+						InterfaceTypes: []string{"ovs-interface"},
+						Fields:         []string{"interfaces.*.ethtool"},
+					},
+				}
+			})
+			It("should render them at the handler daemonset", func() {
+				Expect(container.Args).To(ContainElement("--v=debug"))
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "STATE_FILTER", Value: `{"interfaceTypes":["ovs-interface"],"fields":["interfaces.*.ethtool"]}`}))
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "INTERFACES_FILTER", Value: "{veth*,cali*,tap*}"}))
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "NETWORK_STATE_REFRESH_PERIOD", Value: "5m0s"}))
+			})
+		})
+		Context("and the interfaces filter is disabled", func() {
+			BeforeEach(func() {
+				handlerSpec = &nmstatev1beta1.NMStateHandlerSpec{
+					InterfacesFilter: []string{""},
+				}
+			})
+			It("should render an empty filter", func() {
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "INTERFACES_FILTER", Value: ""}))
+			})
+		})
+		Context("and only one interfaces filter glob is set", func() {
+			BeforeEach(func() {
+				handlerSpec = &nmstatev1beta1.NMStateHandlerSpec{
+					InterfacesFilter: []string{"veth*"},
+				}
+			})
+			It("should render it as is", func() {
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "INTERFACES_FILTER", Value: "veth*"}))
+			})
+		})
+	})
+	Context("when operator spec has a state filter with invalid fields", func() {
+		var err error
+		BeforeEach(func() {
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NMState{},
+			)
+			instance := nmstate.DeepCopy()
+			instance.Spec.Handler = &nmstatev1beta1.NMStateHandlerSpec{
+				StateFilter: &shared.NodeNetworkStateFilter{
+					Fields: []string{"dns-resolver.running"},
+				},
+			}
+			cl = fake.NewFakeClientWithScheme(s, instance)
+			reconciler.Client = cl
+			request := ctrl.Request{}
+			request.Name = existingNMStateName
+			_, err = reconciler.Reconcile(context.Background(), request)
+		})
+		It("should return an error", func() {
+			Expect(err).To(MatchError(ContainSubstring(`invalid handler state filter: invalid field path "dns-resolver.running"`)))
+		})
+	})
+
+	Context("when operator spec has an invalid interfaces filter", func() {
+		var (
+			result ctrl.Result
+			err    error
+		)
+		BeforeEach(func() {
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NMState{},
+			)
+			instance := nmstate.DeepCopy()
+			instance.Spec.Handler = &nmstatev1beta1.NMStateHandlerSpec{
+				InterfacesFilter: []string{"veth*", "cali["},
+			}
+			cl = fake.NewFakeClientWithScheme(s, instance)
+			reconciler.Client = cl
+			request := ctrl.Request{}
+			request.Name = existingNMStateName
+			result, err = reconciler.Reconcile(context.Background(), request)
+		})
+		It("should return an error", func() {
+			Expect(err).To(MatchError(ContainSubstring(`invalid handler interfaces filter "{veth*,cali[}"`)))
+			Expect(result).To(Equal(ctrl.Result{}))
+		})
+		It("should not render the handler daemonset", func() {
+			ds := &appsv1.DaemonSet{}
+			handlerKey := types.NamespacedName{Namespace: handlerNamespace, Name: handlerPrefix + "-nmstate-handler"}
+			err = cl.Get(context.TODO(), handlerKey, ds)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
 
 })
 
@@ -210,14 +348,16 @@ func copyManifest(src, dst string) error {
 
 func copyManifests(manifestsDir string) error {
 	srcToDest := map[string]string{
-		"../deploy/crds/nmstate.io_nodenetworkconfigurationenactments.yaml": "kubernetes-nmstate/crds/",
-		"../deploy/crds/nmstate.io_nodenetworkconfigurationpolicies.yaml":   "kubernetes-nmstate/crds/",
-		"../deploy/crds/nmstate.io_nodenetworkstates.yaml":                  "kubernetes-nmstate/crds/",
-		"../deploy/handler/namespace.yaml":                                  "kubernetes-nmstate/namespace/",
-		"../deploy/handler/operator.yaml":                                   "kubernetes-nmstate/handler/handler.yaml",
-		"../deploy/handler/service_account.yaml":                            "kubernetes-nmstate/rbac/",
-		"../deploy/handler/role.yaml":                                       "kubernetes-nmstate/rbac/",
-		"../deploy/handler/role_binding.yaml":                               "kubernetes-nmstate/rbac/",
+		"../deploy/crds/nmstate.io_nodenetworkconfigurationenactments.yaml":      "kubernetes-nmstate/crds/",
+		"../deploy/crds/nmstate.io_nodenetworkconfigurationpolicies.yaml":        "kubernetes-nmstate/crds/",
+		"../deploy/crds/nmstate.io_nodenetworkconfigurationpolicyrevisions.yaml": "kubernetes-nmstate/crds/",
+		"../deploy/crds/nmstate.io_nodenetworkprobes.yaml":                       "kubernetes-nmstate/crds/",
+		"../deploy/crds/nmstate.io_nodenetworkstates.yaml":                       "kubernetes-nmstate/crds/",
+		"../deploy/handler/namespace.yaml":                                       "kubernetes-nmstate/namespace/",
+		"../deploy/handler/operator.yaml":                                        "kubernetes-nmstate/handler/handler.yaml",
+		"../deploy/handler/service_account.yaml":                                 "kubernetes-nmstate/rbac/",
+		"../deploy/handler/role.yaml":                                            "kubernetes-nmstate/rbac/",
+		"../deploy/handler/role_binding.yaml":                                    "kubernetes-nmstate/rbac/",
 	}
 
 	for src, dest := range srcToDest {
@@ -19,19 +19,31 @@ package controllers
 
 import (
 	"context"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/drift"
+	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
+	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
+	"github.com/nmstate/kubernetes-nmstate/pkg/hardware"
 	nmstate "github.com/nmstate/kubernetes-nmstate/pkg/helper"
+	"github.com/nmstate/kubernetes-nmstate/pkg/metrics"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	"github.com/nmstate/kubernetes-nmstate/pkg/node"
 	"github.com/nmstate/kubernetes-nmstate/pkg/state"
@@ -39,17 +51,36 @@ import (
 )
 
 // Added for test purposes
-type NmstateUpdater func(client client.Client, node *corev1.Node, namespace client.ObjectKey, observedState shared.State) error
-type NmstatectlShow func() (string, error)
+type NmstateUpdater func(client client.Client, node *corev1.Node, namespace client.ObjectKey, observedState shared.State, lldpNeighbors []shared.LLDPNeighbor, hardware []shared.InterfaceHardware) error
+type HardwareInventory func() ([]shared.InterfaceHardware, error)
 
 // NodeReconciler reconciles a Node object
 type NodeReconciler struct {
 	client.Client
-	Log            logr.Logger
-	Scheme         *runtime.Scheme
-	lastState      shared.State
-	nmstateUpdater NmstateUpdater
-	nmstatectlShow NmstatectlShow
+	// APIClient controller-runtime client without cache, it will be used to
+	// retrieve the enactments to check for configuration drift.
+	APIClient client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	// PolicyReapply receives the policies with remediation Reapply that
+	// have drifted at this node
+	PolicyReapply chan<- event.GenericEvent
+	// Refresh sends this node when NetworkManager signals network changes,
+	// if it is set the periodic refresh is stretched to a safety period
+	Refresh <-chan event.GenericEvent
+	// RefreshPeriod overrides the period to poll the network state if it
+	// is not zero
+	RefreshPeriod time.Duration
+	// Nmstate reports the node network state
+	Nmstate   nmstatectl.Backend
+	lastState shared.State
+	// lastLLDPNeighbors are compared apart since they are taken from the
+	// unfiltered state
+	lastLLDPNeighbors []shared.LLDPNeighbor
+	lastHardware      []shared.InterfaceHardware
+	nmstateUpdater    NmstateUpdater
+	hardwareInventory HardwareInventory
 }
 
 // Reconcile reads that state of the cluster for a Node object and makes changes based on the state read
@@ -58,7 +89,7 @@ type NodeReconciler struct {
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *NodeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
-	currentStateRaw, err := r.nmstatectlShow()
+	currentStateRaw, err := r.Nmstate.Show(ctx)
 	if err != nil {
 		// We cannot call nmstatectl show let's reconcile again
 		return ctrl.Result{}, err
@@ -69,9 +100,25 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	lldpNeighbors, err := state.LLDPNeighbors(shared.NewState(currentStateRaw))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	nicsHardware, hardwareErr := r.hardwareInventory()
+	if hardwareErr != nil {
+		// The hardware inventory is informative, do not block the network
+		// state report on it and keep reporting the last one read
+		r.Log.Error(hardwareErr, "failed reading NICs hardware")
+		nicsHardware = r.lastHardware
+	}
+
 	// Reduce apiserver hits by checking node's network state with last one
-	if r.lastState.String() == currentState.String() {
-		return ctrl.Result{RequeueAfter: node.NetworkStateRefreshWithJitter()}, err
+	if r.lastState.String() == currentState.String() &&
+		reflect.DeepEqual(r.lastLLDPNeighbors, lldpNeighbors) &&
+		reflect.DeepEqual(r.lastHardware, nicsHardware) {
+		metrics.NodeNetworkStateRefreshes.WithLabelValues("false").Inc()
+		return ctrl.Result{RequeueAfter: r.refreshPeriod()}, nil
 	} else {
 		r.Log.Info("Network configuration changed, updating NodeNetworkState")
 	}
@@ -89,7 +136,7 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (c
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
 	}
-	err = r.nmstateUpdater(r.Client, instance, request.NamespacedName, currentState)
+	err = r.nmstateUpdater(r.Client, instance, request.NamespacedName, currentState, lldpNeighbors, nicsHardware)
 	if err != nil {
 		err = errors.Wrap(err, "error at node reconcile creating NodeNetworkState")
 		return ctrl.Result{}, err
@@ -97,17 +144,105 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, request ctrl.Request) (c
 
 	// Cache currentState after successfully storing it at NodeNetworkState
 	r.lastState = currentState
+	r.lastLLDPNeighbors = lldpNeighbors
+	r.lastHardware = nicsHardware
+	metrics.NodeNetworkStateRefreshes.WithLabelValues("true").Inc()
+
+	// Configuration can only drift when the network state changes
+	r.detectDrift(request.Name, shared.NewState(currentStateRaw))
 
-	return ctrl.Result{RequeueAfter: node.NetworkStateRefreshWithJitter()}, nil
+	return ctrl.Result{RequeueAfter: r.refreshPeriod()}, nil
+}
+
+// refreshPeriod returns the period to poll the network state, the configured
+// one or a longer one if the network state is refreshed on NetworkManager
+// signals
+func (r *NodeReconciler) refreshPeriod() time.Duration {
+	if r.RefreshPeriod > 0 {
+		return node.NetworkStateRefreshPeriodWithJitter(r.RefreshPeriod)
+	}
+	if r.Refresh != nil {
+		return node.NetworkStateSafetyRefreshWithJitter()
+	}
+	return node.NetworkStateRefreshWithJitter()
+}
+
+// detectDrift compares the desired state from the available enactments of
+// this node with the current state and updates their Drifted condition, if
+// the policy remediation is Reapply the policy is applied again.
+func (r *NodeReconciler) detectDrift(nodeName string, currentState shared.State) {
+	log := r.Log.WithName("detectDrift").WithValues("node", nodeName)
+	policies := nmstatev1beta1.NodeNetworkConfigurationPolicyList{}
+	err := r.Client.List(context.TODO(), &policies)
+	if err != nil {
+		log.Error(err, "failed listing policies to detect configuration drift")
+		return
+	}
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if !policy.DeletionTimestamp.IsZero() || policy.Spec.DryRun {
+			continue
+		}
+		enactmentKey := shared.EnactmentKey(nodeName, policy.Name)
+		enactmentInstance := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+		err = r.APIClient.Get(context.TODO(), enactmentKey, &enactmentInstance)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				log.Error(err, "failed getting enactment to detect configuration drift", "enactment", enactmentKey.Name)
+			}
+			continue
+		}
+		if enactmentInstance.Status.PolicyGeneration != policy.Generation || !enactmentstatus.IsAvailable(&enactmentInstance.Status.Conditions) {
+			continue
+		}
+
+		differences, err := drift.Detect(enactmentInstance.Status.DesiredState, currentState)
+		if err != nil {
+			log.Error(err, "failed detecting configuration drift", "enactment", enactmentKey.Name)
+			continue
+		}
+		enactmentConditions := enactmentconditions.New(r.APIClient, enactmentKey)
+		driftedCondition := enactmentInstance.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionDrifted)
+		wasDrifted := driftedCondition != nil && driftedCondition.Status == corev1.ConditionTrue
+		if len(differences) == 0 {
+			if wasDrifted {
+				log.Info("node configuration is in sync with policy again", "policy", policy.Name)
+				enactmentConditions.NotifyInSync()
+			}
+			continue
+		}
+
+		message := strings.Join(differences, "; ")
+		log.Info("node configuration drifted from policy", "policy", policy.Name, "differences", message)
+		if !wasDrifted {
+			enactmentConditions.NotifyDrifted(message)
+			if r.Recorder != nil {
+				r.Recorder.Event(&enactmentInstance, corev1.EventTypeWarning, string(shared.NodeNetworkConfigurationEnactmentConditionConfigurationDrifted), message)
+			}
+		}
+		if policy.Spec.Remediation == shared.NodeNetworkConfigurationPolicyRemediationReapply && r.PolicyReapply != nil {
+			select {
+			case r.PolicyReapply <- event.GenericEvent{Object: policy}:
+				log.Info("re-applying drifted policy", "policy", policy.Name)
+			default:
+				// Do not block the node reconcile if the policy controller
+				// is not consuming, forget the last state so the drift is
+				// detected and the policy sent again at the next refresh
+				log.Info("policy reapply queue is full, retrying at next refresh", "policy", policy.Name)
+				r.lastState = shared.State{}
+			}
+		}
+	}
 }
 
 func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	r.nmstateUpdater = nmstate.CreateOrUpdateNodeNetworkState
-	r.nmstatectlShow = nmstatectl.Show
+	r.hardwareInventory = hardware.Inventory
 
 	// By default all this functors return true so controller watch all events,
-	// but we only want to watch create/delete for current node.
+	// but we only want to watch create for current node and the refreshes
+	// sent by NetworkManager signals.
 	onCreationForThisNode := predicate.Funcs{
 		CreateFunc: func(createEvent event.CreateEvent) bool {
 			return nmstate.EventIsForThisNode(createEvent.Object)
@@ -118,13 +253,16 @@ func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		UpdateFunc: func(event.UpdateEvent) bool {
 			return false
 		},
-		GenericFunc: func(event.GenericEvent) bool {
-			return false
+		GenericFunc: func(genericEvent event.GenericEvent) bool {
+			return nmstate.EventIsForThisNode(genericEvent.Object)
 		},
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	nodeController := ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Node{}).
-		WithEventFilter(onCreationForThisNode).
-		Complete(r)
+		WithEventFilter(onCreationForThisNode)
+	if r.Refresh != nil {
+		nodeController = nodeController.Watches(&source.Channel{Source: r.Refresh}, &handler.EnqueueRequestForObject{})
+	}
+	return nodeController.Complete(r)
 }
@@ -14,13 +14,16 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	nmstatenode "github.com/nmstate/kubernetes-nmstate/pkg/node"
 	"github.com/nmstate/kubernetes-nmstate/pkg/state"
@@ -53,6 +56,10 @@ var _ = Describe("Node controller reconcile", func() {
 		s := scheme.Scheme
 		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
 			&nmstatev1beta1.NodeNetworkState{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicy{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyList{},
+			&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+			&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
 		)
 
 		objs := []runtime.Object{&node, &nodenetworkstate}
@@ -61,10 +68,13 @@ var _ = Describe("Node controller reconcile", func() {
 		cl = fake.NewFakeClientWithScheme(s, objs...)
 
 		reconciler.Client = cl
+		reconciler.APIClient = cl
 		reconciler.Log = ctrl.Log.WithName("controllers").WithName("Node")
 		reconciler.Scheme = s
 		reconciler.nmstateUpdater = nmstate.CreateOrUpdateNodeNetworkState
-		reconciler.nmstatectlShow = nmstatectl.Show
+		reconciler.hardwareInventory = func() ([]shared.InterfaceHardware, error) {
+			return nil, nil
+		}
 		reconciler.lastState = shared.NewState("lastState")
 		observedState = `
 ---
@@ -80,18 +90,14 @@ routes:
 		filteredOutObservedState, err = state.FilterOut(shared.NewState(observedState))
 		Expect(err).ToNot(HaveOccurred())
 
-		reconciler.nmstatectlShow = func() (string, error) {
-			return observedState, nil
-		}
+		reconciler.Nmstate = &nmstatectl.Fake{CurrentState: observedState}
 	})
 	Context("and nmstatectl show is failing", func() {
 		var (
 			request reconcile.Request
 		)
 		BeforeEach(func() {
-			reconciler.nmstatectlShow = func() (string, error) {
-				return "", fmt.Errorf("forced failure at unit test")
-			}
+			reconciler.Nmstate = &nmstatectl.Fake{ShowErr: fmt.Errorf("forced failure at unit test")}
 		})
 		It("should return the error from nmstatectl", func() {
 			_, err := reconciler.Reconcile(context.Background(), request)
@@ -105,7 +111,7 @@ routes:
 		BeforeEach(func() {
 			reconciler.lastState = filteredOutObservedState
 			reconciler.nmstateUpdater = func(client.Client, *corev1.Node,
-				client.ObjectKey, shared.State) error {
+				client.ObjectKey, shared.State, []shared.LLDPNeighbor, []shared.InterfaceHardware) error {
 				return fmt.Errorf("we are not suppose to catch this error")
 			}
 		})
@@ -152,13 +158,11 @@ routes:
 			)
 			BeforeEach(func() {
 				By("Create the NNS with last state")
-				err := reconciler.nmstateUpdater(cl, &node, types.NamespacedName{Name: node.Name}, filteredOutObservedState)
+				err := reconciler.nmstateUpdater(cl, &node, types.NamespacedName{Name: node.Name}, filteredOutObservedState, nil, nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				By("Mock nmstate show so we return different value from last state")
-				reconciler.nmstatectlShow = func() (string, error) {
-					return expectedStateRaw, nil
-				}
+				reconciler.Nmstate = &nmstatectl.Fake{CurrentState: expectedStateRaw}
 
 			})
 			It("should call nmstateUpdater and return a Result with RequeueAfter set (trigger re-reconciliation)", func() {
@@ -173,6 +177,179 @@ routes:
 				Expect(obtainedNNS.Status.CurrentState.String()).To(Equal(filteredOutExpectedState.String()))
 			})
 		})
+		Context("and an interface has LLDP neighbors", func() {
+			BeforeEach(func() {
+				reconciler.Nmstate = &nmstatectl.Fake{CurrentState: `---
+interfaces:
+  - name: eth1
+    type: ethernet
+    state: up
+    lldp:
+      enabled: true
+      neighbors:
+      - - chassis-id: 00:01:30:F9:AD:A0
+          type: 1
+        - port-id: 1/1
+          type: 2
+routes:
+  running: []
+  config: []
+`}
+			})
+			It("should report them at NodeNetworkState status", func() {
+				_, err := reconciler.Reconcile(context.Background(), request)
+				Expect(err).ToNot(HaveOccurred())
+				obtainedNNS := nmstatev1beta1.NodeNetworkState{}
+				err = cl.Get(context.TODO(), types.NamespacedName{Name: existingNodeName}, &obtainedNNS)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(obtainedNNS.Status.LLDPNeighbors).To(Equal([]shared.LLDPNeighbor{
+					{Interface: "eth1", ChassisID: "00:01:30:F9:AD:A0", PortID: "1/1"},
+				}))
+			})
+		})
+		Context("and the node has physical NICs", func() {
+			var (
+				nicsHardware = []shared.InterfaceHardware{
+					{Interface: "eth1", PCIAddress: "0000:3b:00.0", Driver: "ice", Speed: 25000},
+				}
+			)
+			BeforeEach(func() {
+				reconciler.lastState = filteredOutObservedState
+				reconciler.hardwareInventory = func() ([]shared.InterfaceHardware, error) {
+					return nicsHardware, nil
+				}
+			})
+			It("should report their hardware at NodeNetworkState status even if the network state did not change", func() {
+				_, err := reconciler.Reconcile(context.Background(), request)
+				Expect(err).ToNot(HaveOccurred())
+				obtainedNNS := nmstatev1beta1.NodeNetworkState{}
+				err = cl.Get(context.TODO(), types.NamespacedName{Name: existingNodeName}, &obtainedNNS)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(obtainedNNS.Status.Hardware).To(Equal(nicsHardware))
+			})
+		})
+		Context("and reading the NICs hardware fails", func() {
+			var (
+				nicsHardware = []shared.InterfaceHardware{
+					{Interface: "eth1", PCIAddress: "0000:3b:00.0", Driver: "ice", Speed: 25000},
+				}
+			)
+			BeforeEach(func() {
+				reconciler.lastHardware = nicsHardware
+				reconciler.hardwareInventory = func() ([]shared.InterfaceHardware, error) {
+					return nil, fmt.Errorf("forced hardware failure at unit test")
+				}
+			})
+			It("should not return the error and keep the last hardware if the network state did not change", func() {
+				reconciler.lastState = filteredOutObservedState
+				result, err := reconciler.Reconcile(context.Background(), request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).ToNot(BeZero())
+				Expect(reconciler.lastHardware).To(Equal(nicsHardware))
+			})
+			It("should report the last hardware at NodeNetworkState status if the network state changed", func() {
+				_, err := reconciler.Reconcile(context.Background(), request)
+				Expect(err).ToNot(HaveOccurred())
+				obtainedNNS := nmstatev1beta1.NodeNetworkState{}
+				err = cl.Get(context.TODO(), types.NamespacedName{Name: existingNodeName}, &obtainedNNS)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(obtainedNNS.Status.Hardware).To(Equal(nicsHardware))
+				Expect(reconciler.lastHardware).To(Equal(nicsHardware))
+			})
+		})
+		Context("and a policy applied at the node has drifted", func() {
+			var (
+				recorder      *record.FakeRecorder
+				policyReapply chan event.GenericEvent
+				policy        nmstatev1beta1.NodeNetworkConfigurationPolicy
+				enactmentKey  = shared.EnactmentKey(existingNodeName, "policy1")
+			)
+			BeforeEach(func() {
+				recorder = record.NewFakeRecorder(10)
+				policyReapply = make(chan event.GenericEvent, 10)
+				reconciler.Recorder = recorder
+				reconciler.PolicyReapply = policyReapply
+
+				policy = nmstatev1beta1.NodeNetworkConfigurationPolicy{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "policy1",
+						Generation: 1,
+					},
+				}
+				enactment := nmstatev1beta1.NewEnactment(existingNodeName, policy)
+				enactment.Status.PolicyGeneration = 1
+				enactment.Status.DesiredState = shared.NewState(`interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+- name: eth3
+  type: ethernet
+  state: up
+`)
+				enactmentconditions.SetSuccess(&enactment.Status.Conditions, "")
+				Expect(cl.Create(context.TODO(), &enactment)).To(Succeed())
+			})
+			getDriftedCondition := func() *shared.Condition {
+				enactment := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+				ExpectWithOffset(1, cl.Get(context.TODO(), enactmentKey, &enactment)).To(Succeed())
+				return enactment.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionDrifted)
+			}
+			Context("with remediation None", func() {
+				BeforeEach(func() {
+					Expect(cl.Create(context.TODO(), &policy)).To(Succeed())
+				})
+				It("should mark the enactment as drifted and record an event without re-applying the policy", func() {
+					_, err := reconciler.Reconcile(context.Background(), request)
+					Expect(err).ToNot(HaveOccurred())
+
+					drifted := getDriftedCondition()
+					Expect(drifted).ToNot(BeNil())
+					Expect(drifted.Status).To(Equal(corev1.ConditionTrue))
+					Expect(drifted.Message).To(Equal("interface eth3 is missing"))
+					Expect(recorder.Events).To(Receive(Equal("Warning ConfigurationDrifted interface eth3 is missing")))
+					Expect(policyReapply).ToNot(Receive())
+				})
+				It("should mark the enactment in sync after the configuration is restored", func() {
+					_, err := reconciler.Reconcile(context.Background(), request)
+					Expect(err).ToNot(HaveOccurred())
+
+					reconciler.Nmstate = &nmstatectl.Fake{CurrentState: `interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+- name: eth3
+  type: ethernet
+  state: up
+`}
+					_, err = reconciler.Reconcile(context.Background(), request)
+					Expect(err).ToNot(HaveOccurred())
+
+					drifted := getDriftedCondition()
+					Expect(drifted).ToNot(BeNil())
+					Expect(drifted.Status).To(Equal(corev1.ConditionFalse))
+				})
+			})
+			Context("with remediation Reapply", func() {
+				BeforeEach(func() {
+					policy.Spec.Remediation = shared.NodeNetworkConfigurationPolicyRemediationReapply
+					Expect(cl.Create(context.TODO(), &policy)).To(Succeed())
+				})
+				It("should send the policy to be re-applied", func() {
+					_, err := reconciler.Reconcile(context.Background(), request)
+					Expect(err).ToNot(HaveOccurred())
+
+					var reapplied event.GenericEvent
+					Expect(policyReapply).To(Receive(&reapplied))
+					Expect(reapplied.Object.GetName()).To(Equal("policy1"))
+				})
+				It("should not block if the reapply queue is full and detect the drift again at next refresh", func() {
+					reconciler.PolicyReapply = make(chan event.GenericEvent)
+					_, err := reconciler.Reconcile(context.Background(), request)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(reconciler.lastState).To(Equal(shared.State{}))
+				})
+			})
+		})
 		Context("and nodenetworkstate is not there", func() {
 			BeforeEach(func() {
 				By("Delete the nodenetworkstate")
@@ -21,15 +21,18 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	builder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -46,19 +49,37 @@ import (
 
 	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/cleanup"
 	"github.com/nmstate/kubernetes-nmstate/pkg/enactment"
 	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
 	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
 	"github.com/nmstate/kubernetes-nmstate/pkg/environment"
 	nmstate "github.com/nmstate/kubernetes-nmstate/pkg/helper"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	"github.com/nmstate/kubernetes-nmstate/pkg/node"
 	"github.com/nmstate/kubernetes-nmstate/pkg/policyconditions"
+	"github.com/nmstate/kubernetes-nmstate/pkg/policytemplate"
+	"github.com/nmstate/kubernetes-nmstate/pkg/probe"
+	"github.com/nmstate/kubernetes-nmstate/pkg/rollout"
 	"github.com/nmstate/kubernetes-nmstate/pkg/selectors"
 )
 
+// Reasons of the events emitted for the desired state failures that are not
+// enactment condition reasons
+const (
+	probeFailedEventReason  = "ProbeFailed"
+	rolledBackEventReason   = "RolledBack"
+	invalidProbeEventReason = "InvalidProbe"
+)
+
+// maxRevertAttempts bounds the attempts to revert the configuration of a
+// deleted policy with cleanup at a node
+const maxRevertAttempts = 3
+
 var (
 	nodeName                                string
 	nodeRunningUpdateRetryTime              = 5 * time.Second
+	revertRetryTime                         = 30 * time.Second
 	onCreateOrUpdateWithDifferentGeneration = predicate.Funcs{
 		CreateFunc: func(createEvent event.CreateEvent) bool {
 			return true
@@ -73,6 +94,21 @@ var (
 		},
 	}
 
+	onDeletionStarted = predicate.Funcs{
+		CreateFunc: func(createEvent event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+			return false
+		},
+		UpdateFunc: func(updateEvent event.UpdateEvent) bool {
+			return updateEvent.ObjectOld.GetDeletionTimestamp() == nil && updateEvent.ObjectNew.GetDeletionTimestamp() != nil
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+
 	onLabelsUpdatedForThisNode = predicate.Funcs{
 		CreateFunc: func(createEvent event.CreateEvent) bool {
 			return false
@@ -98,6 +134,14 @@ type NodeNetworkConfigurationPolicyReconciler struct {
 	APIClient client.Client
 	Log       logr.Logger
 	Scheme    *runtime.Scheme
+	// Recorder emits the enactment lifecycle events at the policy and the
+	// node, it is optional.
+	Recorder record.EventRecorder
+	// Reapply sends the policies that have to be applied again at this
+	// node because its configuration has drifted.
+	Reapply <-chan event.GenericEvent
+	// Nmstate applies the desired state at the node
+	Nmstate nmstatectl.Backend
 }
 
 func init() {
@@ -135,6 +179,11 @@ func (r *NodeNetworkConfigurationPolicyReconciler) Reconcile(ctx context.Context
 		return ctrl.Result{}, err
 	}
 
+	if !instance.DeletionTimestamp.IsZero() {
+		log.Info("Policy is being deleted, reverting its configuration if needed")
+		return r.cleanupPolicy(instance)
+	}
+
 	policyconditions.Reset(r.Client, request.NamespacedName)
 
 	// Policy conditions will be updated at the end so updating it
@@ -143,24 +192,59 @@ func (r *NodeNetworkConfigurationPolicyReconciler) Reconcile(ctx context.Context
 	defer policyconditions.Update(r.Client, r.APIClient, request.NamespacedName)
 
 	policySelectors := selectors.NewFromPolicy(r.Client, *instance)
-	unmatchingNodeLabels, err := policySelectors.UnmatchedNodeLabels(nodeName)
+	matchesNode, err := policySelectors.MatchesNode(nodeName)
 	if err != nil {
 		log.Error(err, "failed checking node selectors")
 		return ctrl.Result{}, err
 	}
 
-	if len(unmatchingNodeLabels) > 0 {
+	if !matchesNode {
 		log.Info("Policy node selectors does not match node, removing previous enactments if any")
-		err = r.deleteEnactmentForPolicy(instance)
+		return r.revertAndDeleteEnactment(instance)
+	}
+
+	err = r.updateCleanupFinalizer(instance)
+	if err != nil {
+		log.Error(err, "failed updating cleanup finalizer")
 		return ctrl.Result{}, err
 	}
 
-	previousConditions, err := r.initializeEnactment(*instance)
+	result, rechecked, err := r.recheckRolloutProbes(instance)
+	if err != nil {
+		log.Error(err, "failed re-running probes after the rollout step pause")
+		return ctrl.Result{}, err
+	}
+	if rechecked {
+		return result, nil
+	}
+
+	desiredState, renderErr := r.renderDesiredState(*instance)
+
+	if renderErr == nil {
+		alreadyApplied, err := r.keepAppliedEnactment(*instance, desiredState)
+		if err != nil {
+			log.Error(err, "failed checking if policy is already applied")
+			return ctrl.Result{}, err
+		}
+		if alreadyApplied {
+			log.Info("Policy desired state already applied at node, keeping enactment")
+			return ctrl.Result{}, nil
+		}
+	}
+
+	previousConditions, err := r.initializeEnactment(*instance, desiredState)
 	if err != nil {
 		log.Error(err, "Error initializing enactment")
 	}
 
-	enactmentConditions := enactmentconditions.New(r.APIClient, nmstateapi.EnactmentKey(nodeName, instance.Name))
+	enactmentConditions := r.enactmentConditions(instance)
+
+	if renderErr != nil {
+		err = errors.Wrap(renderErr, "failed rendering desired state for node")
+		log.Error(err, "")
+		enactmentConditions.NotifyFailedToConfigure(err)
+		return ctrl.Result{}, nil
+	}
 
 	_, enactmentCountByCondition, err := enactment.CountByPolicy(r.APIClient, instance)
 	if err != nil {
@@ -174,7 +258,36 @@ func (r *NodeNetworkConfigurationPolicyReconciler) Reconcile(ctx context.Context
 		return ctrl.Result{}, nil
 	}
 
+	// Nodes already applying the policy are not interrupted, they
+	// finish before the policy is reconciled again.
+	if instance.Spec.Paused {
+		log.Info("Policy rollout is paused, waiting for it to be resumed")
+		enactmentConditions.NotifyPaused()
+		return ctrl.Result{}, nil
+	}
+
 	if r.shouldIncrementUnavailableNodeCount(previousConditions) {
+		rolloutStatus, err := r.rolloutStatus(instance)
+		if err != nil {
+			log.Error(err, "failed checking policy rollout")
+			return ctrl.Result{}, err
+		}
+		if rolloutStatus.Aborted {
+			err = fmt.Errorf("policy rollout aborted, %s", rolloutStatus.Message)
+			log.Error(err, "")
+			enactmentConditions.NotifyAborted(err)
+			return ctrl.Result{}, nil
+		}
+		if !rolloutStatus.Ready {
+			log.Info("node rollout step has not started", "message", rolloutStatus.Message)
+			enactmentConditions.NotifyWaitingForRolloutStep(rolloutStatus.Message)
+			requeueAfter := rolloutStatus.RequeueAfter
+			if requeueAfter == 0 {
+				requeueAfter = nodeRunningUpdateRetryTime
+			}
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+
 		err = r.incrementUnavailableNodeCount(instance)
 		if err != nil {
 			if apierrors.IsConflict(err) {
@@ -186,11 +299,40 @@ func (r *NodeNetworkConfigurationPolicyReconciler) Reconcile(ctx context.Context
 	}
 	defer r.decrementUnavailableNodeCount(instance)
 
+	if instance.Spec.DryRun {
+		enactmentConditions.NotifyProgressing()
+		userProbes := r.userProbes(instance)
+		diff, err := nmstate.DryRunDesiredState(r.APIClient, r.Nmstate, desiredState, r.timeouts(instance), userProbes)
+		r.updateProbeResults(instance, userProbes)
+		if err != nil {
+			err = errors.Wrap(err, "error dry running NodeNetworkConfigurationPolicy desired state")
+			log.Error(err, "")
+			enactmentConditions.NotifyDryRunFailed(err)
+			return ctrl.Result{}, nil
+		}
+		log.Info("dry run", "diff", diff)
+		enactmentConditions.NotifyDryRunSucceeded(diff)
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Spec.Cleanup {
+		err = r.updateRevertState(instance, desiredState)
+		if err != nil {
+			err = errors.Wrap(err, "failed capturing node configuration to revert the policy at cleanup")
+			log.Error(err, "")
+			enactmentConditions.NotifyFailedToConfigure(err)
+			return ctrl.Result{}, nil
+		}
+	}
+
 	enactmentConditions.NotifyProgressing()
-	nmstateOutput, err := nmstate.ApplyDesiredState(r.APIClient, instance.Spec.DesiredState)
+	userProbes := r.userProbes(instance)
+	nmstateOutput, err := nmstate.ApplyDesiredState(r.APIClient, r.Nmstate, desiredState, r.timeouts(instance), userProbes)
+	r.updateProbeResults(instance, userProbes)
 	if err != nil {
-		errmsg := fmt.Errorf("error reconciling NodeNetworkConfigurationPolicy at desired state apply: %s, %v", nmstateOutput, err)
+		errmsg := errors.Wrapf(err, "error reconciling NodeNetworkConfigurationPolicy at desired state apply: %s", nmstateOutput)
 
+		recordApplyFailureEvents(&enactmentConditions, err)
 		enactmentConditions.NotifyFailedToConfigure(errmsg)
 		log.Error(errmsg, fmt.Sprintf("Rolling back network configuration, manual intervention needed: %s", nmstateOutput))
 		return ctrl.Result{}, nil
@@ -201,7 +343,12 @@ func (r *NodeNetworkConfigurationPolicyReconciler) Reconcile(ctx context.Context
 
 	r.forceNNSRefresh(nodeName)
 
-	return ctrl.Result{}, nil
+	// Wait for the rollout step pause to re-run the probes
+	result, _, err = r.recheckRolloutProbes(instance)
+	if err != nil {
+		log.Error(err, "failed checking the rollout step pause")
+	}
+	return result, nil
 }
 
 func (r *NodeNetworkConfigurationPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -224,11 +371,15 @@ func (r *NodeNetworkConfigurationPolicyReconciler) SetupWithManager(mgr ctrl.Man
 			return allPoliciesAsRequest
 		})
 
-	// Reconcile NNCP if they are created or updated
-	err := ctrl.NewControllerManagedBy(mgr).
+	// Reconcile NNCP if they are created or updated, or if they have to be
+	// re-applied after configuration drift
+	policyController := ctrl.NewControllerManagedBy(mgr).
 		For(&nmstatev1beta1.NodeNetworkConfigurationPolicy{}).
-		WithEventFilter(onCreateOrUpdateWithDifferentGeneration).
-		Complete(r)
+		WithEventFilter(predicate.Or(onCreateOrUpdateWithDifferentGeneration, onDeletionStarted))
+	if r.Reapply != nil {
+		policyController = policyController.Watches(&source.Channel{Source: r.Reapply}, &handler.EnqueueRequestForObject{})
+	}
+	err := policyController.Complete(r)
 	if err != nil {
 		return errors.Wrap(err, "failed to add controller to NNCP Reconciler listening NNCP events")
 	}
@@ -245,7 +396,7 @@ func (r *NodeNetworkConfigurationPolicyReconciler) SetupWithManager(mgr ctrl.Man
 	return nil
 }
 
-func (r *NodeNetworkConfigurationPolicyReconciler) initializeEnactment(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) (*nmstateapi.ConditionList, error) {
+func (r *NodeNetworkConfigurationPolicyReconciler) initializeEnactment(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, desiredState nmstateapi.State) (*nmstateapi.ConditionList, error) {
 	enactmentKey := nmstateapi.EnactmentKey(nodeName, policy.Name)
 	log := r.Log.WithName("initializeEnactment").WithValues("policy", policy.Name, "enactment", enactmentKey.Name)
 	// Return if it's already initialize or we cannot retrieve it
@@ -271,11 +422,95 @@ func (r *NodeNetworkConfigurationPolicyReconciler) initializeEnactment(policy nm
 	}
 
 	return &enactment.Status.Conditions, enactmentstatus.Update(r.APIClient, enactmentKey, func(status *nmstateapi.NodeNetworkConfigurationEnactmentStatus) {
-		status.DesiredState = policy.Spec.DesiredState
+		status.DesiredState = desiredState
+		status.PolicyGeneration = policy.Generation
+		status.DryRunDiff = ""
+	})
+}
+
+// keepAppliedEnactment checks if the enactment is available with the same
+// desired state from a previous policy generation, that happens when the
+// policy spec changes without changing the node configuration, for example
+// pausing or resuming it. In that case the enactment is moved to the current
+// policy generation so completed nodes do not apply the policy again.
+func (r *NodeNetworkConfigurationPolicyReconciler) keepAppliedEnactment(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, desiredState nmstateapi.State) (bool, error) {
+	if policy.Spec.DryRun {
+		return false, nil
+	}
+	enactmentKey := nmstateapi.EnactmentKey(nodeName, policy.Name)
+	enactmentInstance := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err := r.APIClient.Get(context.TODO(), enactmentKey, &enactmentInstance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed getting enactment")
+	}
+	if enactmentInstance.Status.PolicyGeneration == policy.Generation ||
+		!enactmentstatus.IsAvailable(&enactmentInstance.Status.Conditions) ||
+		enactmentInstance.Status.DesiredState.String() != desiredState.String() {
+		return false, nil
+	}
+	if policy.Spec.Cleanup && cleanup.IsEmpty(enactmentInstance.Status.RevertState) {
+		return false, nil
+	}
+	return true, enactmentstatus.Update(r.APIClient, enactmentKey, func(status *nmstateapi.NodeNetworkConfigurationEnactmentStatus) {
 		status.PolicyGeneration = policy.Generation
 	})
 }
 
+// renderDesiredState resolves the policy desiredState template with the facts
+// from this node, if rendering fails the policy desiredState is returned
+// so it can be stored at the enactment.
+func (r *NodeNetworkConfigurationPolicyReconciler) renderDesiredState(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) (nmstateapi.State, error) {
+	if !policytemplate.IsTemplate(policy.Spec.DesiredState) {
+		return policy.Spec.DesiredState, nil
+	}
+
+	node := corev1.Node{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: nodeName}, &node)
+	if err != nil {
+		return policy.Spec.DesiredState, errors.Wrap(err, "failed retrieving node")
+	}
+
+	// If NodeNetworkState is not there yet templates referencing current
+	// state will fail at rendering
+	nns := nmstatev1beta1.NodeNetworkState{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: nodeName}, &nns)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return policy.Spec.DesiredState, errors.Wrap(err, "failed retrieving NodeNetworkState")
+	}
+
+	desiredState, err := policytemplate.Render(policy.Spec.DesiredState, node, nns.Status.CurrentState)
+	if err != nil {
+		return policy.Spec.DesiredState, err
+	}
+	return desiredState, nil
+}
+
+// enactmentConditions returns the conditions of this node enactment for the
+// policy, they emit events at the policy and the node if there is a recorder.
+func (r *NodeNetworkConfigurationPolicyReconciler) enactmentConditions(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy) enactmentconditions.EnactmentConditions {
+	enactmentConditions := enactmentconditions.New(r.APIClient, nmstateapi.EnactmentKey(nodeName, policy.Name))
+	if r.Recorder != nil {
+		// Node events reference the node by name as UID like kubelet does
+		nodeRef := &corev1.ObjectReference{APIVersion: "v1", Kind: "Node", Name: nodeName, UID: types.UID(nodeName)}
+		enactmentConditions.RecordEvents(r.Recorder, policy, nodeRef)
+	}
+	return enactmentConditions
+}
+
+// recordApplyFailureEvents emits the events for the probe that failed and
+// the rollback of the desired state if any
+func recordApplyFailureEvents(enactmentConditions *enactmentconditions.EnactmentConditions, err error) {
+	if probeName := probe.FailedName(err); probeName != "" {
+		enactmentConditions.Event(corev1.EventTypeWarning, probeFailedEventReason, fmt.Sprintf("probe '%s' failed after applying desired state", probeName))
+	}
+	if nmstatectl.IsRollback(err) {
+		enactmentConditions.Event(corev1.EventTypeWarning, rolledBackEventReason, fmt.Sprintf("desired state rolled back: %s", nmstatectl.ShortError(err)))
+	}
+}
+
 func (r *NodeNetworkConfigurationPolicyReconciler) waitEnactmentCreated(enactmentKey types.NamespacedName) error {
 	var enactment nmstatev1beta1.NodeNetworkConfigurationEnactment
 	pollErr := wait.PollImmediate(1*time.Second, 10*time.Second, func() (bool, error) {
@@ -308,6 +543,359 @@ func (r *NodeNetworkConfigurationPolicyReconciler) deleteEnactmentForPolicy(poli
 	return nil
 }
 
+// cleanupPolicy reverts the configuration of a deleted policy at this node
+// and removes the cleanup finalizer after all the nodes have reverted it.
+func (r *NodeNetworkConfigurationPolicyReconciler) cleanupPolicy(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(policy, nmstateapi.NodeNetworkConfigurationPolicyCleanupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	result, err := r.revertAndDeleteEnactment(policy)
+	if err != nil || !result.IsZero() {
+		return result, err
+	}
+	return ctrl.Result{}, r.removeCleanupFinalizerIfReverted(policy)
+}
+
+// revertAndDeleteEnactment applies the enactment revert state if the policy
+// has cleanup and deletes the enactment after it. Enactments that failed were
+// already rolled back and the pending or aborted ones did not apply the
+// desired state, so they are not reverted. A failed revert is retried up to
+// maxRevertAttempts times keeping the enactment as failed, after that the
+// enactment is kept with the RevertFailed reason, and so the cleanup
+// finalizer, until an admin releases it with the skip revert annotation.
+func (r *NodeNetworkConfigurationPolicyReconciler) revertAndDeleteEnactment(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy) (ctrl.Result, error) {
+	enactmentKey := nmstateapi.EnactmentKey(nodeName, policy.Name)
+	log := r.Log.WithName("revertAndDeleteEnactment").WithValues("policy", policy.Name, "enactment", enactmentKey.Name)
+	enactmentInstance := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err := r.APIClient.Get(context.TODO(), enactmentKey, &enactmentInstance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrap(err, "failed getting enactment")
+	}
+
+	if !policy.Spec.Cleanup || cleanup.IsEmpty(enactmentInstance.Status.RevertState) {
+		return ctrl.Result{}, r.deleteEnactmentForPolicy(policy)
+	}
+
+	if enactmentInstance.Annotations[nmstateapi.EnactmentSkipRevertAnnotation] == "true" {
+		log.Info("enactment revert skipped by annotation, the node configuration is not reverted")
+		return ctrl.Result{}, r.deleteEnactmentForPolicy(policy)
+	}
+
+	attempts := revertAttempts(enactmentInstance)
+	if attempts == 0 && !needsRevert(&enactmentInstance.Status.Conditions) {
+		log.Info("enactment did not apply the desired state, skipping revert")
+		return ctrl.Result{}, r.deleteEnactmentForPolicy(policy)
+	}
+
+	enactmentConditions := r.enactmentConditions(policy)
+	if attempts >= maxRevertAttempts {
+		// Keep polling the enactment since its annotations do not trigger
+		// a policy reconcile
+		if !isRevertFailed(&enactmentInstance.Status.Conditions) {
+			message := fmt.Sprintf("giving up reverting the desired state after %d attempts, fix the node configuration "+
+				"and set the %s annotation to \"true\" at the enactment to release the policy", attempts, nmstateapi.EnactmentSkipRevertAnnotation)
+			log.Info(message)
+			enactmentConditions.NotifyRevertFailed(message)
+		}
+		return ctrl.Result{RequeueAfter: revertRetryTime}, nil
+	}
+
+	err = r.incrementUnavailableNodeCount(policy)
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			enactmentConditions.NotifyPending()
+			return ctrl.Result{RequeueAfter: nodeRunningUpdateRetryTime}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	defer r.decrementUnavailableNodeCount(policy)
+
+	err = r.setRevertAttempts(enactmentKey, attempts+1)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	enactmentConditions.NotifyReverting()
+	nmstateOutput, err := nmstate.ApplyDesiredState(r.APIClient, r.Nmstate, enactmentInstance.Status.RevertState, r.timeouts(policy), nil)
+	if err != nil {
+		errmsg := errors.Wrapf(err, "error reverting NodeNetworkConfigurationPolicy desired state: %s", nmstateOutput)
+		recordApplyFailureEvents(&enactmentConditions, err)
+		enactmentConditions.NotifyFailedToConfigure(errmsg)
+		log.Error(errmsg, "Rolling back network configuration, retrying", "attempt", attempts+1)
+		return ctrl.Result{RequeueAfter: revertRetryTime}, nil
+	}
+	log.Info("nmstate", "output", nmstateOutput)
+	r.forceNNSRefresh(nodeName)
+
+	return ctrl.Result{}, r.deleteEnactmentForPolicy(policy)
+}
+
+// needsRevert returns true if the enactment may have applied the desired
+// state: it is available, it was progressing or its rollback failed
+func needsRevert(conditions *nmstateapi.ConditionList) bool {
+	if enactmentstatus.IsAvailable(conditions) || enactmentstatus.IsProgressing(conditions) {
+		return true
+	}
+	failingCondition := conditions.Find(nmstateapi.NodeNetworkConfigurationEnactmentConditionFailing)
+	return failingCondition != nil && failingCondition.Status == corev1.ConditionTrue &&
+		failingCondition.Reason == nmstateapi.NodeNetworkConfigurationEnactmentConditionRollbackFailed
+}
+
+// isRevertFailed returns true if the enactment already gave up reverting
+func isRevertFailed(conditions *nmstateapi.ConditionList) bool {
+	failingCondition := conditions.Find(nmstateapi.NodeNetworkConfigurationEnactmentConditionFailing)
+	return failingCondition != nil && failingCondition.Status == corev1.ConditionTrue &&
+		failingCondition.Reason == nmstateapi.NodeNetworkConfigurationEnactmentConditionRevertFailed
+}
+
+// revertAttempts returns the number of times the enactment revert state has
+// been applied
+func revertAttempts(enactmentInstance nmstatev1beta1.NodeNetworkConfigurationEnactment) int {
+	attempts, err := strconv.Atoi(enactmentInstance.Annotations[nmstateapi.EnactmentRevertAttemptsAnnotation])
+	if err != nil {
+		return 0
+	}
+	return attempts
+}
+
+func (r *NodeNetworkConfigurationPolicyReconciler) setRevertAttempts(enactmentKey types.NamespacedName, attempts int) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		instance := &nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+		err := r.APIClient.Get(context.TODO(), enactmentKey, instance)
+		if err != nil {
+			return errors.Wrap(err, "failed getting enactment")
+		}
+		if instance.Annotations == nil {
+			instance.Annotations = map[string]string{}
+		}
+		instance.Annotations[nmstateapi.EnactmentRevertAttemptsAnnotation] = strconv.Itoa(attempts)
+		return r.APIClient.Update(context.TODO(), instance)
+	})
+}
+
+// updateCleanupFinalizer adds the cleanup finalizer to policies with
+// cleanup and removes it if cleanup has being disabled.
+func (r *NodeNetworkConfigurationPolicyReconciler) updateCleanupFinalizer(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy) error {
+	hasFinalizer := controllerutil.ContainsFinalizer(policy, nmstateapi.NodeNetworkConfigurationPolicyCleanupFinalizer)
+	if policy.Spec.Cleanup == hasFinalizer {
+		return nil
+	}
+	policyKey := types.NamespacedName{Name: policy.GetName(), Namespace: policy.GetNamespace()}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		instance := &nmstatev1beta1.NodeNetworkConfigurationPolicy{}
+		err := r.APIClient.Get(context.TODO(), policyKey, instance)
+		if err != nil {
+			return err
+		}
+		if instance.Spec.Cleanup {
+			controllerutil.AddFinalizer(instance, nmstateapi.NodeNetworkConfigurationPolicyCleanupFinalizer)
+		} else {
+			controllerutil.RemoveFinalizer(instance, nmstateapi.NodeNetworkConfigurationPolicyCleanupFinalizer)
+		}
+		return r.Client.Update(context.TODO(), instance)
+	})
+}
+
+// removeCleanupFinalizerIfReverted removes the cleanup finalizer from the
+// policy when there are no more enactments, meaning that all the nodes have
+// reverted the policy configuration.
+func (r *NodeNetworkConfigurationPolicyReconciler) removeCleanupFinalizerIfReverted(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy) error {
+	enactmentsTotal, _, err := enactment.CountByPolicy(r.APIClient, policy)
+	if err != nil {
+		return err
+	}
+	if enactmentsTotal > 0 {
+		r.Log.Info("policy cleanup is still in progress at other nodes", "policy", policy.Name, "enactments", enactmentsTotal)
+		return nil
+	}
+	policyKey := types.NamespacedName{Name: policy.GetName(), Namespace: policy.GetNamespace()}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		instance := &nmstatev1beta1.NodeNetworkConfigurationPolicy{}
+		err := r.APIClient.Get(context.TODO(), policyKey, instance)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if !controllerutil.ContainsFinalizer(instance, nmstateapi.NodeNetworkConfigurationPolicyCleanupFinalizer) {
+			return nil
+		}
+		controllerutil.RemoveFinalizer(instance, nmstateapi.NodeNetworkConfigurationPolicyCleanupFinalizer)
+		return r.Client.Update(context.TODO(), instance)
+	})
+}
+
+// updateRevertState captures the node configuration that the desired state
+// is going to change so it can be reverted at cleanup.
+func (r *NodeNetworkConfigurationPolicyReconciler) updateRevertState(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy, desiredState nmstateapi.State) error {
+	enactmentKey := nmstateapi.EnactmentKey(nodeName, policy.Name)
+	enactmentInstance := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err := r.APIClient.Get(context.TODO(), enactmentKey, &enactmentInstance)
+	if err != nil {
+		return errors.Wrap(err, "failed getting enactment")
+	}
+
+	currentState, err := r.Nmstate.Show(context.TODO())
+	if err != nil {
+		return err
+	}
+
+	revertState, err := cleanup.UpdateRevertState(desiredState, nmstateapi.NewState(currentState), enactmentInstance.Status.RevertState)
+	if err != nil {
+		return err
+	}
+
+	return enactmentstatus.Update(r.APIClient, enactmentKey, func(status *nmstateapi.NodeNetworkConfigurationEnactmentStatus) {
+		status.RevertState = revertState
+	})
+}
+
+// timeouts returns the policy timeouts, the unset ones are taken from the
+// NMState CR and then from the defaults.
+func (r *NodeNetworkConfigurationPolicyReconciler) timeouts(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy) probe.Timeouts {
+	timeouts := probe.DefaultTimeouts()
+	nmstates := nmstatev1beta1.NMStateList{}
+	err := r.APIClient.List(context.TODO(), &nmstates)
+	if err != nil {
+		r.Log.Error(err, "failed listing NMState to get default timeouts, using builtin ones")
+	} else if len(nmstates.Items) > 0 {
+		timeouts = timeouts.Override(nmstates.Items[0].Spec.Timeouts)
+	}
+	return timeouts.Override(policy.Spec.Timeouts)
+}
+
+// userProbes returns the policy probes and the NodeNetworkProbes matching
+// this node, the NodeNetworkProbes are skipped if they cannot be retrieved.
+func (r *NodeNetworkConfigurationPolicyReconciler) userProbes(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy) []probe.Probe {
+	userProbes := probe.User(policy.Spec.Probes)
+
+	nodeNetworkProbes := nmstatev1beta1.NodeNetworkProbeList{}
+	err := r.APIClient.List(context.TODO(), &nodeNetworkProbes)
+	if err != nil {
+		r.Log.Error(err, "failed listing NodeNetworkProbes, running only policy probes")
+		return userProbes
+	}
+	if len(nodeNetworkProbes.Items) == 0 {
+		return userProbes
+	}
+
+	node := corev1.Node{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: nodeName}, &node)
+	if err != nil {
+		r.Log.Error(err, "failed getting node to select NodeNetworkProbes, running only policy probes")
+		return userProbes
+	}
+	nodeProbes, invalidProbes := probe.NodeNetwork(nodeNetworkProbes.Items, node.Labels)
+	for _, invalidProbe := range invalidProbes {
+		r.Log.Info(invalidProbe.Error())
+		if r.Recorder != nil {
+			r.Recorder.Event(policy, corev1.EventTypeWarning, invalidProbeEventReason, invalidProbe.Error())
+		}
+	}
+	return append(userProbes, nodeProbes...)
+}
+
+// updateProbeResults stores the results of the user probes at the enactment
+func (r *NodeNetworkConfigurationPolicyReconciler) updateProbeResults(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy, userProbes []probe.Probe) {
+	enactmentKey := nmstateapi.EnactmentKey(nodeName, policy.Name)
+	err := enactmentstatus.Update(r.APIClient, enactmentKey, func(status *nmstateapi.NodeNetworkConfigurationEnactmentStatus) {
+		status.ProbeResults = probe.Results(userProbes)
+	})
+	if err != nil {
+		r.Log.Error(err, "failed updating enactment probe results")
+	}
+}
+
+// rolloutStatus checks if this node rollout step has started for policies
+// with staged rollout
+func (r *NodeNetworkConfigurationPolicyReconciler) rolloutStatus(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy) (rollout.Status, error) {
+	if policy.Spec.Rollout == nil {
+		return rollout.Status{Ready: true}, nil
+	}
+	nodes, enactments, err := r.rolloutNodesAndEnactments(policy)
+	if err != nil {
+		return rollout.Status{}, err
+	}
+	return rollout.Check(*policy, nodes, enactments, nodeName, time.Now())
+}
+
+// recheckRolloutProbes re-runs the user probes at the end of the pause after
+// this node rollout step, so the next step only starts with healthy probes.
+// It is done when this node has already applied the current policy
+// generation, then the node waits for the pause instead of applying the
+// policy again.
+func (r *NodeNetworkConfigurationPolicyReconciler) recheckRolloutProbes(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy) (ctrl.Result, bool, error) {
+	if policy.Spec.Rollout == nil || policy.Spec.DryRun {
+		return ctrl.Result{}, false, nil
+	}
+	enactmentInstance := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	err := r.APIClient.Get(context.TODO(), nmstateapi.EnactmentKey(nodeName, policy.Name), &enactmentInstance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, false, nil
+		}
+		return ctrl.Result{}, false, errors.Wrap(err, "failed getting enactment")
+	}
+	if enactmentInstance.Status.PolicyGeneration != policy.Generation ||
+		!enactmentstatus.IsAvailable(&enactmentInstance.Status.Conditions) ||
+		len(enactmentInstance.Status.ProbeResults) == 0 {
+		return ctrl.Result{}, false, nil
+	}
+
+	nodes, enactments, err := r.rolloutNodesAndEnactments(policy)
+	if err != nil {
+		return ctrl.Result{}, false, err
+	}
+	pauseEnd, hasPause, err := rollout.PauseEnd(*policy, nodes, enactments, nodeName)
+	if err != nil || !hasPause {
+		return ctrl.Result{}, false, err
+	}
+	if pauseEnd.IsZero() {
+		return ctrl.Result{RequeueAfter: nodeRunningUpdateRetryTime}, true, nil
+	}
+	if now := time.Now(); now.Before(pauseEnd) {
+		return ctrl.Result{RequeueAfter: pauseEnd.Sub(now)}, true, nil
+	}
+	for _, result := range enactmentInstance.Status.ProbeResults {
+		if result.LastRunTime.Time.Before(pauseEnd) {
+			r.Log.Info("re-running probes after the rollout step pause", "policy", policy.Name)
+			userProbes := r.userProbes(policy)
+			err = probe.Run(r.APIClient, r.Nmstate, userProbes)
+			r.updateProbeResults(policy, userProbes)
+			if err != nil {
+				enactmentConditions := r.enactmentConditions(policy)
+				recordApplyFailureEvents(&enactmentConditions, err)
+				r.Log.Error(err, "probes failed after the rollout step pause", "policy", policy.Name)
+			}
+			return ctrl.Result{}, true, nil
+		}
+	}
+	return ctrl.Result{}, false, nil
+}
+
+// rolloutNodesAndEnactments returns the nodes matching the policy and its
+// enactments to follow the policy rollout
+func (r *NodeNetworkConfigurationPolicyReconciler) rolloutNodesAndEnactments(policy *nmstatev1beta1.NodeNetworkConfigurationPolicy) ([]corev1.Node, []nmstatev1beta1.NodeNetworkConfigurationEnactment, error) {
+	nodeSelector, err := selectors.NodeSelector(*policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	nodes, err := node.NodesRunningNmstate(r.APIClient, nodeSelector)
+	if err != nil {
+		return nil, nil, err
+	}
+	enactments := nmstatev1beta1.NodeNetworkConfigurationEnactmentList{}
+	err = r.APIClient.List(context.TODO(), &enactments, client.MatchingLabels{nmstateapi.EnactmentPolicyLabel: policy.Name})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed listing policy enactments")
+	}
+	return nodes, enactments.Items, nil
+}
+
 func (r *NodeNetworkConfigurationPolicyReconciler) shouldIncrementUnavailableNodeCount(conditions *nmstateapi.ConditionList) bool {
 	return !enactmentstatus.IsProgressing(conditions)
 }
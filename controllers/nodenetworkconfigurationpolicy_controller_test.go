@@ -2,24 +2,36 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
 	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 )
 
+// unavailableNmstate fails every nmstate operation, the policies cannot be
+// applied at unit tests
+func unavailableNmstate() *nmstatectl.Fake {
+	err := fmt.Errorf("nmstate is not available at unit tests")
+	return &nmstatectl.Fake{ShowErr: err, SetErr: err, CommitErr: err, RollbackErr: err}
+}
+
 var _ = Describe("NodeNetworkConfigurationPolicy controller predicates", func() {
 	type predicateCase struct {
 		GenerationOld   int64
@@ -124,6 +136,7 @@ var _ = Describe("NodeNetworkConfigurationPolicy controller predicates", func()
 			reconciler.Client = cl
 			reconciler.APIClient = cl
 			reconciler.Log = ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy")
+			reconciler.Nmstate = unavailableNmstate()
 
 			res, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
 				NamespacedName: types.NamespacedName{Name: nncp.Name},
@@ -190,3 +203,210 @@ var _ = Describe("NodeNetworkConfigurationPolicy controller predicates", func()
 			}),
 	)
 })
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller cleanup", func() {
+	var (
+		cl         client.Client
+		reconciler NodeNetworkConfigurationPolicyReconciler
+		nncp       nmstatev1beta1.NodeNetworkConfigurationPolicy
+		nnce       nmstatev1beta1.NodeNetworkConfigurationEnactment
+		res        ctrl.Result
+	)
+	BeforeEach(func() {
+		deletionTimestamp := metav1.Now()
+		nncp = nmstatev1beta1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test",
+				DeletionTimestamp: &deletionTimestamp,
+				Finalizers:        []string{shared.NodeNetworkConfigurationPolicyCleanupFinalizer},
+			},
+			Spec: shared.NodeNetworkConfigurationPolicySpec{
+				Cleanup: true,
+			},
+		}
+		nnce = nmstatev1beta1.NodeNetworkConfigurationEnactment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   shared.EnactmentKey(nodeName, nncp.Name).Name,
+				Labels: map[string]string{shared.EnactmentPolicyLabel: nncp.Name},
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		reconciler = NodeNetworkConfigurationPolicyReconciler{}
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationPolicy{},
+			&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+			&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+		)
+		node := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+		}
+
+		clb := fake.ClientBuilder{}
+		clb.WithScheme(s)
+		clb.WithRuntimeObjects(&nncp, &nnce, &node)
+		cl = clb.Build()
+
+		reconciler.Client = cl
+		reconciler.APIClient = cl
+		reconciler.Log = ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy")
+		reconciler.Nmstate = unavailableNmstate()
+
+		var err error
+		res, err = reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Name: nncp.Name},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	obtainEnactment := func() (nmstatev1beta1.NodeNetworkConfigurationEnactment, error) {
+		obtainedNNCE := nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+		err := cl.Get(context.TODO(), shared.EnactmentKey(nodeName, nncp.Name), &obtainedNNCE)
+		return obtainedNNCE, err
+	}
+
+	obtainPolicy := func() (nmstatev1beta1.NodeNetworkConfigurationPolicy, error) {
+		obtainedNNCP := nmstatev1beta1.NodeNetworkConfigurationPolicy{}
+		err := cl.Get(context.TODO(), types.NamespacedName{Name: nncp.Name}, &obtainedNNCP)
+		return obtainedNNCP, err
+	}
+
+	Context("when a policy with cleanup is deleted and there is nothing to revert", func() {
+		It("should delete the node enactment and remove the cleanup finalizer", func() {
+			Expect(res).To(Equal(ctrl.Result{}))
+			_, err := obtainEnactment()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			// Removing the last finalizer of a deleted policy deletes it
+			_, err = obtainPolicy()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("when a policy with cleanup is deleted and the enactment failed and was rolled back", func() {
+		BeforeEach(func() {
+			nnce.Status.RevertState = shared.NewState("interfaces:\n- name: eth1\n  state: absent\n")
+			conditions.SetFailedToConfigure(&nnce.Status.Conditions, "failed applying desired state")
+		})
+		It("should delete the node enactment without reverting and remove the cleanup finalizer", func() {
+			Expect(res).To(Equal(ctrl.Result{}))
+			_, err := obtainEnactment()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			// Removing the last finalizer of a deleted policy deletes it
+			_, err = obtainPolicy()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("when a policy with cleanup is deleted and the enactment never started", func() {
+		BeforeEach(func() {
+			nnce.Status.RevertState = shared.NewState("interfaces:\n- name: eth1\n  state: absent\n")
+			conditions.SetPending(&nnce.Status.Conditions, "Max unavailable node limit reached")
+		})
+		It("should delete the node enactment without reverting and remove the cleanup finalizer", func() {
+			Expect(res).To(Equal(ctrl.Result{}))
+			_, err := obtainEnactment()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			_, err = obtainPolicy()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("when a policy with cleanup is deleted and the enactment is progressing", func() {
+		BeforeEach(func() {
+			nnce.Status.RevertState = shared.NewState("interfaces:\n- name: eth1\n  state: absent\n")
+			conditions.SetProgressing(&nnce.Status.Conditions, "Applying desired state")
+		})
+		It("should revert it", func() {
+			Expect(res).To(Equal(ctrl.Result{RequeueAfter: revertRetryTime}))
+			obtainedNNCE, err := obtainEnactment()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainedNNCE.Annotations).To(HaveKeyWithValue(shared.EnactmentRevertAttemptsAnnotation, "1"))
+			obtainedNNCP, err := obtainPolicy()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainedNNCP.Finalizers).To(ContainElement(shared.NodeNetworkConfigurationPolicyCleanupFinalizer))
+		})
+	})
+
+	Context("when a policy with cleanup is deleted and reverting the available enactment fails", func() {
+		BeforeEach(func() {
+			nnce.Status.RevertState = shared.NewState("interfaces:\n- name: eth1\n  state: absent\n")
+			conditions.SetSuccess(&nnce.Status.Conditions, "successfully applied desired state")
+		})
+		It("should keep the failed enactment and the cleanup finalizer and retry", func() {
+			Expect(res).To(Equal(ctrl.Result{RequeueAfter: revertRetryTime}))
+			obtainedNNCE, err := obtainEnactment()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainedNNCE.Annotations).To(HaveKeyWithValue(shared.EnactmentRevertAttemptsAnnotation, "1"))
+			Expect(enactmentstatus.IsAvailable(&obtainedNNCE.Status.Conditions)).To(BeFalse())
+			obtainedNNCP, err := obtainPolicy()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainedNNCP.Finalizers).To(ContainElement(shared.NodeNetworkConfigurationPolicyCleanupFinalizer))
+		})
+	})
+
+	Context("when a policy with cleanup is deleted and reverting has failed too many times", func() {
+		BeforeEach(func() {
+			nnce.Status.RevertState = shared.NewState("interfaces:\n- name: eth1\n  state: absent\n")
+			conditions.SetFailedToConfigure(&nnce.Status.Conditions, "failed reverting desired state")
+			nnce.Annotations = map[string]string{shared.EnactmentRevertAttemptsAnnotation: fmt.Sprint(maxRevertAttempts)}
+		})
+		It("should give up keeping the enactment as RevertFailed and the cleanup finalizer", func() {
+			Expect(res).To(Equal(ctrl.Result{RequeueAfter: revertRetryTime}))
+			obtainedNNCE, err := obtainEnactment()
+			Expect(err).ToNot(HaveOccurred())
+			failingCondition := obtainedNNCE.Status.Conditions.Find(shared.NodeNetworkConfigurationEnactmentConditionFailing)
+			Expect(failingCondition).ToNot(BeNil())
+			Expect(failingCondition.Status).To(Equal(corev1.ConditionTrue))
+			Expect(failingCondition.Reason).To(Equal(shared.NodeNetworkConfigurationEnactmentConditionRevertFailed))
+			Expect(failingCondition.Message).To(ContainSubstring(shared.EnactmentSkipRevertAnnotation))
+			obtainedNNCP, err := obtainPolicy()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obtainedNNCP.Finalizers).To(ContainElement(shared.NodeNetworkConfigurationPolicyCleanupFinalizer))
+		})
+	})
+
+	Context("when a policy with cleanup is deleted and the failed revert is released by an admin", func() {
+		BeforeEach(func() {
+			nnce.Status.RevertState = shared.NewState("interfaces:\n- name: eth1\n  state: absent\n")
+			conditions.SetFailed(&nnce.Status.Conditions, shared.NodeNetworkConfigurationEnactmentConditionRevertFailed, "giving up reverting")
+			nnce.Annotations = map[string]string{
+				shared.EnactmentRevertAttemptsAnnotation: fmt.Sprint(maxRevertAttempts),
+				shared.EnactmentSkipRevertAnnotation:     "true",
+			}
+		})
+		It("should delete the node enactment and remove the cleanup finalizer", func() {
+			Expect(res).To(Equal(ctrl.Result{}))
+			_, err := obtainEnactment()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			_, err = obtainPolicy()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller deletion predicate", func() {
+	DescribeTable("testing onDeletionStarted",
+		func(oldDeletionTimestamp, newDeletionTimestamp *metav1.Time, expectedReconcile bool) {
+			oldNNCP := nmstatev1beta1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp: oldDeletionTimestamp,
+				},
+			}
+			newNNCP := nmstatev1beta1.NodeNetworkConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp: newDeletionTimestamp,
+				},
+			}
+			Expect(onDeletionStarted.UpdateFunc(event.UpdateEvent{
+				ObjectOld: &oldNNCP,
+				ObjectNew: &newNNCP,
+			})).To(Equal(expectedReconcile))
+		},
+		Entry("deletion has not started", nil, nil, false),
+		Entry("deletion has started", nil, &metav1.Time{}, true),
+		Entry("deletion was already started", &metav1.Time{}, &metav1.Time{}, false),
+	)
+})
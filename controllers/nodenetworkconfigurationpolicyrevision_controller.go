@@ -0,0 +1,97 @@
+/*
+Copyright The Kubernetes NMState Authors.
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/policyrevision"
+)
+
+// NodeNetworkConfigurationPolicyRevisionReconciler stores a revision for every
+// NodeNetworkConfigurationPolicy generation and prunes the ones exceeding
+// the policy revision history limit.
+type NodeNetworkConfigurationPolicyRevisionReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a NodeNetworkConfigurationPolicy object and
+// stores its spec at a NodeNetworkConfigurationPolicyRevision
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *NodeNetworkConfigurationPolicyRevisionReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("nodenetworkconfigurationpolicy", request.NamespacedName)
+
+	policy := &nmstatev1beta1.NodeNetworkConfigurationPolicy{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, policy)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// Revisions are removed by the garbage collector since
+			// they are owned by the policy
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !policy.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	policyRevision := nmstatev1beta1.NewPolicyRevision(*policy)
+	err = r.Client.Create(context.TODO(), &policyRevision)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return ctrl.Result{}, errors.Wrapf(err, "failed creating policy revision %d", policy.Generation)
+	}
+	if err == nil {
+		log.Info("policy revision created", "revision", policyRevision.Revision)
+	}
+
+	revisions, err := policyrevision.List(r.Client, policy.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	prunables := policyrevision.Prunable(*policy, revisions)
+	for i := range prunables {
+		prunable := &prunables[i]
+		log.Info("pruning policy revision", "revision", prunable.Revision)
+		err = r.Client.Delete(context.TODO(), prunable)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, errors.Wrapf(err, "failed pruning policy revision %d", prunable.Revision)
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *NodeNetworkConfigurationPolicyRevisionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&nmstatev1beta1.NodeNetworkConfigurationPolicy{}).
+		WithEventFilter(onCreateOrUpdateWithDifferentGeneration).
+		Complete(r)
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/policyrevision"
+)
+
+var _ = Describe("NodeNetworkConfigurationPolicyRevision controller reconcile", func() {
+	var (
+		cl           client.Client
+		reconciler   NodeNetworkConfigurationPolicyRevisionReconciler
+		historyLimit int32 = 1
+		policy             = nmstatev1beta1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "policy1",
+				Generation: 3,
+			},
+			Spec: shared.NodeNetworkConfigurationPolicySpec{
+				DesiredState:         shared.NewState("current: configuration"),
+				RevisionHistoryLimit: &historyLimit,
+			},
+		}
+		revisionAt = func(generation int64) *nmstatev1beta1.NodeNetworkConfigurationPolicyRevision {
+			previousPolicy := policy.DeepCopy()
+			previousPolicy.Generation = generation
+			policyRevision := nmstatev1beta1.NewPolicyRevision(*previousPolicy)
+			return &policyRevision
+		}
+	)
+	BeforeEach(func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationPolicy{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyRevision{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyRevisionList{},
+		)
+		cl = fake.NewFakeClientWithScheme(s, &policy, revisionAt(1), revisionAt(2))
+		reconciler = NodeNetworkConfigurationPolicyRevisionReconciler{
+			Client: cl,
+			Log:    ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicyRevision"),
+			Scheme: s,
+		}
+	})
+	It("should store the policy current generation and prune the revisions beyond the history limit", func() {
+		_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: policy.Name}})
+		Expect(err).ToNot(HaveOccurred())
+
+		revisions, err := policyrevision.List(cl, policy.Name)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(revisions).To(HaveLen(2))
+		Expect(revisions[0].Revision).To(Equal(int64(2)))
+		Expect(revisions[1].Revision).To(Equal(int64(3)))
+		Expect(revisions[1].Spec.DesiredState.String()).To(MatchYAML("current: configuration"))
+	})
+})
@@ -32,6 +32,7 @@ import (
 
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/hardware"
 	nmstate "github.com/nmstate/kubernetes-nmstate/pkg/helper"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	"github.com/nmstate/kubernetes-nmstate/pkg/state"
@@ -43,6 +44,8 @@ type NodeNetworkStateReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// Nmstate reports the node network state
+	Nmstate nmstatectl.Backend
 }
 
 // Reconcile reads that state of the cluster for a NodeNetworkState object and makes changes based on the state read
@@ -65,7 +68,7 @@ func (r *NodeNetworkStateReconciler) Reconcile(ctx context.Context, request ctrl
 		return ctrl.Result{}, err
 	}
 
-	currentStateRaw, err := nmstatectl.Show()
+	currentStateRaw, err := r.Nmstate.Show(ctx)
 	if err != nil {
 		// We cannot call nmstatectl show let's reconcile again
 		return ctrl.Result{}, err
@@ -76,7 +79,17 @@ func (r *NodeNetworkStateReconciler) Reconcile(ctx context.Context, request ctrl
 		return ctrl.Result{}, err
 	}
 
-	nmstate.CreateOrUpdateNodeNetworkState(r.Client, node, request.NamespacedName, currentState)
+	lldpNeighbors, err := state.LLDPNeighbors(shared.NewState(currentStateRaw))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	nicsHardware, err := hardware.Inventory()
+	if err != nil {
+		r.Log.Error(err, "failed reading NICs hardware")
+	}
+
+	nmstate.CreateOrUpdateNodeNetworkState(r.Client, node, request.NamespacedName, currentState, lldpNeighbors, nicsHardware)
 	if err != nil {
 		err = errors.Wrap(err, "error at node reconcile creating NodeNetworkStateNetworkState")
 		return ctrl.Result{}, err
@@ -8,6 +8,7 @@ require (
 	github.com/github-release/github-release v0.10.0
 	github.com/go-logr/logr v0.4.0
 	github.com/gobwas/glob v0.2.3
+	github.com/godbus/dbus v4.1.0+incompatible
 	github.com/gofrs/flock v0.8.0
 	github.com/gorilla/mux v1.7.4 // indirect
 	github.com/hashicorp/go-multierror v1.1.0 // indirect
@@ -19,8 +20,12 @@ require (
 	github.com/operator-framework/operator-registry v1.17.0
 	github.com/phoracek/networkmanager-go v0.1.0
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/prometheus/client_golang v1.11.0
 	github.com/qinqon/kube-admission-webhook v0.16.0
+	github.com/spf13/cobra v1.1.3
 	github.com/tidwall/gjson v1.6.8
+	golang.org/x/sys v0.0.0-20210603081109-ebe580a85c40
 	gopkg.in/yaml.v2 v2.4.0
 	k8s.io/api v0.21.1
 	k8s.io/apimachinery v0.21.1
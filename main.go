@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
@@ -25,6 +26,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -33,7 +35,9 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	// +kubebuilder:scaffold:imports
 
 	"github.com/gofrs/flock"
@@ -47,16 +51,43 @@ import (
 	"github.com/nmstate/kubernetes-nmstate/controllers"
 	"github.com/nmstate/kubernetes-nmstate/pkg/environment"
 	"github.com/nmstate/kubernetes-nmstate/pkg/file"
+	"github.com/nmstate/kubernetes-nmstate/pkg/metrics"
 	"github.com/nmstate/kubernetes-nmstate/pkg/names"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmsignals"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	"github.com/nmstate/kubernetes-nmstate/pkg/webhook"
 )
 
+type MetricsConfig struct {
+	// BindAddress is host:port, "0" disables metrics
+	BindAddress string `envconfig:"METRICS_BIND_ADDRESS" default:"0"`
+}
+
+type NodeNetworkStateConfig struct {
+	// RefreshPeriod overrides the NodeNetworkState polling period, zero
+	// keeps the default one
+	RefreshPeriod time.Duration `envconfig:"NETWORK_STATE_REFRESH_PERIOD"`
+}
+
+type NmstateConfig struct {
+	// Backend is exec to run nmstatectl or varlink to call the nmstate
+	// varlink service
+	Backend        string `envconfig:"NMSTATE_BACKEND" default:"exec"`
+	VarlinkAddress string `envconfig:"NMSTATE_VARLINK_ADDRESS"`
+}
+
 type ProfilerConfig struct {
 	EnableProfiler bool   `envconfig:"ENABLE_PROFILER"`
 	ProfilerPort   string `envconfig:"PROFILER_PORT" default:"6060"`
 }
 
+const (
+	policyReapplyBufferSize = 100
+	// nnsRefreshDebounce groups the NetworkManager signals of a network
+	// change into one NodeNetworkState refresh
+	nnsRefreshDebounce = 3 * time.Second
+)
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -90,13 +121,21 @@ func main() {
 		setupLog.Info("Successfully took nmstate exclusive lock")
 	}
 
+	metricsConfig := MetricsConfig{}
+	err := envconfig.Process("", &metricsConfig)
+	if err != nil {
+		setupLog.Error(err, "Failed processing metrics configuration")
+		os.Exit(1)
+	}
 	ctrlOptions := ctrl.Options{
 		Scheme:             scheme,
-		MetricsBindAddress: "0", // disable metrics
+		MetricsBindAddress: metricsConfig.BindAddress,
 	}
 
-	// We need to add LeaerElection for the webhook
-	// cert-manager the LeaderElectionID was generated by operator-sdk
+	// The webhook replicas serve admission requests but only the leader
+	// runs the revision controller and the policy metrics collector, the
+	// cert-manager needs it too. The LeaderElectionID was generated by
+	// operator-sdk.
 	if environment.IsWebhook() {
 		ctrlOptions.LeaderElection = true
 		ctrlOptions.LeaderElectionID = "5d2e944a.nmstate.io"
@@ -172,6 +211,24 @@ func main() {
 			setupLog.Error(err, "Cannot initialize webhook")
 			os.Exit(1)
 		}
+		// Policies status is exported from the webhook leader instead of
+		// the handlers, they would export the same series from every node
+		if err = mgr.Add(metrics.NewPolicyCollector(mgr.GetClient())); err != nil {
+			setupLog.Error(err, "unable to add policy metrics collector to controller-runtime manager")
+			os.Exit(1)
+		}
+		// Revisions are created by the webhook leader since it is the
+		// only component running once per cluster that sees all policies,
+		// controllers need leader election so the other replicas do not
+		// run it
+		if err = (&controllers.NodeNetworkConfigurationPolicyRevisionReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicyRevision"),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create NodeNetworkConfigurationPolicyRevision controller", "controller", "NMState")
+			os.Exit(1)
+		}
 	} else if environment.IsOperator() {
 		if err = (&controllers.NMStateReconciler{
 			Client: mgr.GetClient(),
@@ -182,18 +239,52 @@ func main() {
 			os.Exit(1)
 		}
 	} else if environment.IsHandler() {
-		if err = (&controllers.NodeReconciler{
-			Client: mgr.GetClient(),
-			Log:    ctrl.Log.WithName("controllers").WithName("Node"),
-			Scheme: mgr.GetScheme(),
-		}).SetupWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create Node controller", "controller", "NMState")
+		apiClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		if err != nil {
+			setupLog.Error(err, "failed creating non cached client")
 			os.Exit(1)
 		}
 
-		apiClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		// Drifted policies with remediation Reapply are sent from the Node
+		// controller to the NodeNetworkConfigurationPolicy one
+		policyReapply := make(chan event.GenericEvent, policyReapplyBufferSize)
+
+		nnsConfig := NodeNetworkStateConfig{}
+		err = envconfig.Process("", &nnsConfig)
 		if err != nil {
-			setupLog.Error(err, "failed creating non cached client")
+			setupLog.Error(err, "Failed processing NodeNetworkState configuration")
+			os.Exit(1)
+		}
+
+		nmstateConfig := NmstateConfig{}
+		err = envconfig.Process("", &nmstateConfig)
+		if err != nil {
+			setupLog.Error(err, "Failed processing nmstate configuration")
+			os.Exit(1)
+		}
+		nmstateBackend, err := nmstatectl.NewBackend(nmstateConfig.Backend, nmstateConfig.VarlinkAddress)
+		if err != nil {
+			setupLog.Error(err, "Failed creating nmstate backend")
+			os.Exit(1)
+		}
+
+		nnsRefresh, err := watchNetworkManagerSignals(mgr)
+		if err != nil {
+			setupLog.Error(err, "failed watching NetworkManager signals, NodeNetworkState will be refreshed only periodically")
+		}
+
+		if err = (&controllers.NodeReconciler{
+			Client:        mgr.GetClient(),
+			APIClient:     apiClient,
+			Log:           ctrl.Log.WithName("controllers").WithName("Node"),
+			Scheme:        mgr.GetScheme(),
+			Recorder:      mgr.GetEventRecorderFor("nmstate-handler"),
+			PolicyReapply: policyReapply,
+			Refresh:       nnsRefresh,
+			RefreshPeriod: nnsConfig.RefreshPeriod,
+			Nmstate:       nmstateBackend,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create Node controller", "controller", "NMState")
 			os.Exit(1)
 		}
 
@@ -202,21 +293,25 @@ func main() {
 			APIClient: apiClient,
 			Log:       ctrl.Log.WithName("controllers").WithName("NodeNetworkConfigurationPolicy"),
 			Scheme:    mgr.GetScheme(),
+			Recorder:  mgr.GetEventRecorderFor("nmstate-handler"),
+			Reapply:   policyReapply,
+			Nmstate:   nmstateBackend,
 		}).SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create NodeNetworkConfigurationPolicy controller", "controller", "NMState")
 			os.Exit(1)
 		}
 		if err = (&controllers.NodeNetworkStateReconciler{
-			Client: mgr.GetClient(),
-			Log:    ctrl.Log.WithName("controllers").WithName("NodeNetworkState"),
-			Scheme: mgr.GetScheme(),
+			Client:  mgr.GetClient(),
+			Log:     ctrl.Log.WithName("controllers").WithName("NodeNetworkState"),
+			Scheme:  mgr.GetScheme(),
+			Nmstate: nmstateBackend,
 		}).SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create NodeNetworkState controller", "controller", "NMState")
 			os.Exit(1)
 		}
 
-		// Check that nmstatectl is working
-		_, err = nmstatectl.Show()
+		// Check that nmstate is working
+		_, err = nmstateBackend.Show(context.TODO())
 		if err != nil {
 			setupLog.Error(err, "failed checking nmstatectl health")
 			os.Exit(1)
@@ -261,6 +356,32 @@ func setProfiler() {
 	}
 }
 
+// watchNetworkManagerSignals returns a channel that receives this node when
+// NetworkManager signals network changes, it is nil if D-Bus is not
+// reachable.
+func watchNetworkManagerSignals(mgr manager.Manager) (<-chan event.GenericEvent, error) {
+	err := nmsignals.Connect()
+	if err != nil {
+		return nil, err
+	}
+	refresh := make(chan event.GenericEvent, 1)
+	thisNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: environment.NodeName()}}
+	err = mgr.Add(nmsignals.Watcher{
+		Debounce: nnsRefreshDebounce,
+		Notify: func() {
+			select {
+			case refresh <- event.GenericEvent{Object: thisNode}:
+			default:
+				// A refresh is already pending
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refresh, nil
+}
+
 func lockHandler() (*flock.Flock, error) {
 	lockFilePath, ok := os.LookupEnv("NMSTATE_INSTANCE_NODE_LOCK_FILE")
 	if !ok {
@@ -0,0 +1,16 @@
+package cleanup
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.cleanup-cleanup_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Cleanup Test Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,208 @@
+package cleanup
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	yaml "sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+const (
+	interfacesKey  = "interfaces"
+	routesKey      = "routes"
+	dnsResolverKey = "dns-resolver"
+	configKey      = "config"
+	stateKey       = "state"
+	absent         = "absent"
+)
+
+type state map[string]interface{}
+
+// UpdateRevertState extends the revertState with the configuration needed to
+// revert the desiredState at a node with currentState. Entries already
+// present at revertState are kept, so it holds the node configuration
+// previous to the first time the policy was applied.
+//
+// Interfaces and routes that are not present at currentState are added
+// as absent, the ones that are present are stored as they are so they are
+// restored at revert. The DNS configuration is captured only if the
+// desiredState configures it.
+func UpdateRevertState(desiredState shared.State, currentState shared.State, revertState shared.State) (shared.State, error) {
+	desired, err := unmarshal(desiredState)
+	if err != nil {
+		return revertState, errors.Wrap(err, "failed unmarshaling desired state")
+	}
+	current, err := unmarshal(currentState)
+	if err != nil {
+		return revertState, errors.Wrap(err, "failed unmarshaling current state")
+	}
+	revert, err := unmarshal(revertState)
+	if err != nil {
+		return revertState, errors.Wrap(err, "failed unmarshaling revert state")
+	}
+
+	revertInterfaces(desired, current, revert)
+	revertRoutes(desired, current, revert)
+	revertDNS(desired, current, revert)
+
+	if len(revert) == 0 {
+		return shared.NewState(""), nil
+	}
+	output, err := yaml.Marshal(revert)
+	if err != nil {
+		return revertState, errors.Wrap(err, "failed marshaling revert state")
+	}
+	return shared.NewState(string(output)), nil
+}
+
+// IsEmpty returns true if there is nothing to revert at revertState, it takes
+// into account that an empty state is serialized as null.
+func IsEmpty(revertState shared.State) bool {
+	revert, err := unmarshal(revertState)
+	return err == nil && len(revert) == 0
+}
+
+func unmarshal(s shared.State) (state, error) {
+	result := state{}
+	if len(s.Raw) == 0 {
+		return result, nil
+	}
+	err := yaml.Unmarshal(s.Raw, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = state{}
+	}
+	return result, nil
+}
+
+func list(s state, key string) []interface{} {
+	value, ok := s[key].([]interface{})
+	if !ok {
+		return []interface{}{}
+	}
+	return value
+}
+
+func routesConfig(s state) []interface{} {
+	routes, ok := s[routesKey].(map[string]interface{})
+	if !ok {
+		return []interface{}{}
+	}
+	config, ok := routes[configKey].([]interface{})
+	if !ok {
+		return []interface{}{}
+	}
+	return config
+}
+
+func interfaceName(iface interface{}) string {
+	ifaceMap, ok := iface.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(ifaceMap["name"])
+}
+
+func findInterface(interfaces []interface{}, name string) map[string]interface{} {
+	for _, iface := range interfaces {
+		if interfaceName(iface) == name {
+			return iface.(map[string]interface{})
+		}
+	}
+	return nil
+}
+
+func revertInterfaces(desired, current, revert state) {
+	revertIfaces := list(revert, interfacesKey)
+	currentIfaces := list(current, interfacesKey)
+	for _, desiredIface := range list(desired, interfacesKey) {
+		name := interfaceName(desiredIface)
+		if name == "" || findInterface(revertIfaces, name) != nil {
+			continue
+		}
+		if currentIface := findInterface(currentIfaces, name); currentIface != nil {
+			revertIfaces = append(revertIfaces, currentIface)
+			continue
+		}
+		absentIface := map[string]interface{}{"name": name, stateKey: absent}
+		if ifaceType, hasType := desiredIface.(map[string]interface{})["type"]; hasType {
+			absentIface["type"] = ifaceType
+		}
+		revertIfaces = append(revertIfaces, absentIface)
+	}
+	if len(revertIfaces) > 0 {
+		revert[interfacesKey] = revertIfaces
+	}
+}
+
+// RouteKey identifies a route ignoring its state and metric, zero and
+// missing table-id both refer to the main table
+func RouteKey(route interface{}) string {
+	routeMap, ok := route.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	tableID := routeMap["table-id"]
+	if tableID == nil || fmt.Sprint(tableID) == "0" {
+		tableID = 254
+	}
+	return fmt.Sprintf("%v|%v|%v|%v", routeMap["destination"], routeMap["next-hop-interface"], routeMap["next-hop-address"], tableID)
+}
+
+func findRoute(routes []interface{}, key string) map[string]interface{} {
+	for _, route := range routes {
+		if RouteKey(route) == key {
+			return route.(map[string]interface{})
+		}
+	}
+	return nil
+}
+
+func revertRoutes(desired, current, revert state) {
+	revertRoutesConfig := routesConfig(revert)
+	currentRoutesConfig := routesConfig(current)
+	for _, desiredRoute := range routesConfig(desired) {
+		key := RouteKey(desiredRoute)
+		if key == "" || findRoute(revertRoutesConfig, key) != nil {
+			continue
+		}
+		if currentRoute := findRoute(currentRoutesConfig, key); currentRoute != nil {
+			revertRoutesConfig = append(revertRoutesConfig, currentRoute)
+			continue
+		}
+		absentRoute := map[string]interface{}{}
+		for field, value := range desiredRoute.(map[string]interface{}) {
+			absentRoute[field] = value
+		}
+		absentRoute[stateKey] = absent
+		revertRoutesConfig = append(revertRoutesConfig, absentRoute)
+	}
+	if len(revertRoutesConfig) > 0 {
+		revert[routesKey] = map[string]interface{}{configKey: revertRoutesConfig}
+	}
+}
+
+func revertDNS(desired, current, revert state) {
+	if _, desiredHasDNS := desired[dnsResolverKey]; !desiredHasDNS {
+		return
+	}
+	if _, revertHasDNS := revert[dnsResolverKey]; revertHasDNS {
+		return
+	}
+	config := map[string]interface{}{
+		"search": []interface{}{},
+		"server": []interface{}{},
+	}
+	if currentDNS, ok := current[dnsResolverKey].(map[string]interface{}); ok {
+		if currentConfig, ok := currentDNS[configKey].(map[string]interface{}); ok {
+			for field, value := range currentConfig {
+				config[field] = value
+			}
+		}
+	}
+	revert[dnsResolverKey] = map[string]interface{}{configKey: config}
+}
@@ -0,0 +1,178 @@
+package cleanup
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("UpdateRevertState", func() {
+	var (
+		currentState = nmstate.NewState(`
+dns-resolver:
+  config:
+    search:
+    - example.com
+    server:
+    - 8.8.8.8
+  running:
+    server:
+    - 192.168.66.2
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: false
+- name: eth2
+  type: ethernet
+  state: up
+routes:
+  config:
+  - destination: 198.51.100.0/24
+    next-hop-address: 192.0.2.1
+    next-hop-interface: eth1
+  running: []
+`)
+	)
+
+	Context("when desired state creates and modifies interfaces", func() {
+		desiredState := nmstate.NewState(`
+interfaces:
+- name: bond0
+  type: bond
+  state: up
+  link-aggregation:
+    mode: active-backup
+    port:
+    - eth1
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    dhcp: true
+`)
+		It("should mark created interfaces absent and capture modified ones", func() {
+			revertState, err := UpdateRevertState(desiredState, currentState, nmstate.NewState(""))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revertState.String()).To(MatchYAML(`
+interfaces:
+- name: bond0
+  type: bond
+  state: absent
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: false
+`))
+		})
+	})
+
+	Context("when revert state already captures an interface", func() {
+		desiredState := nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 9000
+- name: eth2
+  type: ethernet
+  state: down
+`)
+		previousRevertState := nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+`)
+		It("should keep the previously captured configuration", func() {
+			revertState, err := UpdateRevertState(desiredState, currentState, previousRevertState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revertState.String()).To(MatchYAML(`
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+- name: eth2
+  type: ethernet
+  state: up
+`))
+		})
+	})
+
+	Context("when desired state configures routes", func() {
+		desiredState := nmstate.NewState(`
+routes:
+  config:
+  - destination: 203.0.113.0/24
+    next-hop-address: 192.0.2.1
+    next-hop-interface: eth1
+  - destination: 198.51.100.0/24
+    next-hop-address: 192.0.2.1
+    next-hop-interface: eth1
+    state: absent
+`)
+		It("should mark added routes absent and capture removed ones", func() {
+			revertState, err := UpdateRevertState(desiredState, currentState, nmstate.NewState(""))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revertState.String()).To(MatchYAML(`
+routes:
+  config:
+  - destination: 203.0.113.0/24
+    next-hop-address: 192.0.2.1
+    next-hop-interface: eth1
+    state: absent
+  - destination: 198.51.100.0/24
+    next-hop-address: 192.0.2.1
+    next-hop-interface: eth1
+`))
+		})
+	})
+
+	Context("when desired state configures DNS", func() {
+		desiredState := nmstate.NewState(`
+dns-resolver:
+  config:
+    server:
+    - 1.1.1.1
+`)
+		It("should capture current DNS configuration", func() {
+			revertState, err := UpdateRevertState(desiredState, currentState, nmstate.NewState(""))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revertState.String()).To(MatchYAML(`
+dns-resolver:
+  config:
+    search:
+    - example.com
+    server:
+    - 8.8.8.8
+`))
+		})
+	})
+
+	Context("when desired state is empty", func() {
+		It("should return an empty revert state", func() {
+			revertState, err := UpdateRevertState(nmstate.NewState(""), currentState, nmstate.NewState(""))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revertState.String()).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("IsEmpty", func() {
+	DescribeTable("checking revert state",
+		func(revertState string, expectedEmpty bool) {
+			Expect(IsEmpty(nmstate.NewState(revertState))).To(Equal(expectedEmpty))
+		},
+		Entry("empty", "", true),
+		Entry("serialized as null", "null\n", true),
+		Entry("empty object", "{}\n", true),
+		Entry("with interfaces", "interfaces:\n- name: eth1\n  state: absent\n", false),
+	)
+})
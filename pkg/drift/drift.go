@@ -0,0 +1,199 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// Detect returns the differences between a desired state and the node
+// current state. Only the values present at the desired state are compared
+// so defaults filled in by nmstate are not reported, lists at the desired
+// state have to be contained at the current ones.
+func Detect(desiredState shared.State, currentState shared.State) ([]string, error) {
+	desired, err := unmarshal(desiredState)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed parsing desired state")
+	}
+	current, err := unmarshal(currentState)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed parsing current state")
+	}
+
+	differences := []string{}
+	differences = append(differences, interfacesDrift(desired, current)...)
+	differences = append(differences, routesDrift(desired, current)...)
+	if desiredDNS, ok := nested(desired, "dns-resolver", "config").(map[string]interface{}); ok {
+		differences = append(differences, compare("dns-resolver.config", desiredDNS, nested(current, "dns-resolver", "config"))...)
+	}
+	return differences, nil
+}
+
+func interfacesDrift(desired, current map[string]interface{}) []string {
+	differences := []string{}
+	currentInterfaces := map[string]map[string]interface{}{}
+	for _, iface := range list(current["interfaces"]) {
+		if ifaceMap, ok := iface.(map[string]interface{}); ok {
+			currentInterfaces[fmt.Sprint(ifaceMap["name"])] = ifaceMap
+		}
+	}
+	for _, iface := range list(desired["interfaces"]) {
+		desiredInterface, ok := iface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := fmt.Sprint(desiredInterface["name"])
+		desiredInterfaceState := fmt.Sprint(desiredInterface["state"])
+		currentInterface, found := currentInterfaces[name]
+		if desiredInterfaceState == "absent" {
+			if found && fmt.Sprint(currentInterface["state"]) != "absent" {
+				differences = append(differences, fmt.Sprintf("interface %s is present, desired absent", name))
+			}
+			continue
+		}
+		if !found {
+			// Virtual interfaces are removed from the node when they are
+			// down
+			if desiredInterfaceState != "down" {
+				differences = append(differences, fmt.Sprintf("interface %s is missing", name))
+			}
+			continue
+		}
+		differences = append(differences, compare("interfaces."+name, desiredInterface, currentInterface)...)
+	}
+	return differences
+}
+
+func routesDrift(desired, current map[string]interface{}) []string {
+	differences := []string{}
+	currentRoutes := list(nested(current, "routes", "config"))
+	for _, route := range list(nested(desired, "routes", "config")) {
+		desiredRoute, ok := route.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		absent := fmt.Sprint(desiredRoute["state"]) == "absent"
+		routeMatch := map[string]interface{}{}
+		for key, value := range desiredRoute {
+			if key != "state" {
+				routeMatch[key] = value
+			}
+		}
+		found := contains(currentRoutes, routeMatch)
+		if absent && found {
+			differences = append(differences, fmt.Sprintf("route %s is present, desired absent", toString(routeMatch)))
+		} else if !absent && !found {
+			differences = append(differences, fmt.Sprintf("route %s is missing", toString(routeMatch)))
+		}
+	}
+	return differences
+}
+
+// compare returns the paths where the current value does not contain the
+// desired one, values not reported at the current state are not compared.
+func compare(path string, desired interface{}, current interface{}) []string {
+	if current == nil {
+		return []string{}
+	}
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return []string{difference(path, desired, current)}
+		}
+		differences := []string{}
+		for _, key := range sortedKeys(desiredValue) {
+			differences = append(differences, compare(path+"."+key, desiredValue[key], currentMap[key])...)
+		}
+		return differences
+	case []interface{}:
+		currentList, ok := current.([]interface{})
+		if !ok {
+			return []string{difference(path, desired, current)}
+		}
+		for _, element := range desiredValue {
+			if !contains(currentList, element) {
+				return []string{difference(path, desired, current)}
+			}
+		}
+		return []string{}
+	default:
+		if !strings.EqualFold(fmt.Sprint(desired), fmt.Sprint(current)) {
+			return []string{difference(path, desired, current)}
+		}
+		return []string{}
+	}
+}
+
+func contains(currentList []interface{}, desired interface{}) bool {
+	for _, current := range currentList {
+		if len(compare("", desired, current)) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func difference(path string, desired interface{}, current interface{}) string {
+	return fmt.Sprintf("%s is %s, desired %s", path, toString(current), toString(desired))
+}
+
+func toString(value interface{}) string {
+	if stringValue, ok := value.(string); ok {
+		return stringValue
+	}
+	marshaled, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(marshaled)
+}
+
+func unmarshal(state shared.State) (map[string]interface{}, error) {
+	unmarshaled := map[string]interface{}{}
+	if len(state.Raw) == 0 {
+		return unmarshaled, nil
+	}
+	err := yaml.Unmarshal(state.Raw, &unmarshaled)
+	if err != nil {
+		return nil, err
+	}
+	if unmarshaled == nil {
+		unmarshaled = map[string]interface{}{}
+	}
+	return unmarshaled, nil
+}
+
+func nested(value map[string]interface{}, keys ...string) interface{} {
+	var current interface{} = value
+	for _, key := range keys {
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = currentMap[key]
+	}
+	return current
+}
+
+func list(value interface{}) []interface{} {
+	if valueList, ok := value.([]interface{}); ok {
+		return valueList
+	}
+	return []interface{}{}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,16 @@
+package drift
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.drift-drift_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Drift Test Suite", []Reporter{junitReporter})
+}
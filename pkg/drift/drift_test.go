@@ -0,0 +1,146 @@
+package drift
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("Drift detection", func() {
+	currentState := shared.NewState(`
+dns-resolver:
+  config:
+    search:
+    - example.com
+    server:
+    - 192.168.66.2
+  running:
+    server:
+    - 192.168.66.2
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  mac-address: 52:55:00:D1:56:01
+  mtu: 1500
+  ipv4:
+    enabled: true
+    dhcp: false
+    address:
+    - ip: 10.10.10.1
+      prefix-length: 24
+  bridge:
+    options:
+      stp:
+        enabled: false
+    port:
+    - name: eth1
+      stp-hairpin-mode: false
+      stp-path-cost: 100
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+routes:
+  config:
+  - destination: 198.51.100.0/24
+    metric: 150
+    next-hop-address: 10.10.10.254
+    next-hop-interface: br1
+    table-id: 254
+  running: []
+`)
+
+	DescribeTable("comparing desired state with current state",
+		func(desiredState string, expectedDifferences []string) {
+			differences, err := Detect(shared.NewState(desiredState), currentState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(differences).To(Equal(expectedDifferences))
+		},
+		Entry("when desired state is empty", "", []string{}),
+		Entry("when current state contains desired state", `
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  mac-address: 52:55:00:d1:56:01
+  ipv4:
+    enabled: true
+    address:
+    - ip: 10.10.10.1
+      prefix-length: 24
+  bridge:
+    port:
+    - name: eth1
+- name: br2
+  type: linux-bridge
+  state: down
+- name: eth2
+  type: ethernet
+  state: absent
+routes:
+  config:
+  - destination: 198.51.100.0/24
+    next-hop-address: 10.10.10.254
+    next-hop-interface: br1
+  - destination: 203.0.113.0/24
+    next-hop-address: 10.10.10.254
+    next-hop-interface: br1
+    state: absent
+dns-resolver:
+  config:
+    server:
+    - 192.168.66.2
+`, []string{}),
+		Entry("when interface values are different", `
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  mtu: 9000
+  ipv4:
+    enabled: true
+    address:
+    - ip: 10.10.10.2
+      prefix-length: 24
+`, []string{
+			`interfaces.br1.ipv4.address is [{"ip":"10.10.10.1","prefix-length":24}], desired [{"ip":"10.10.10.2","prefix-length":24}]`,
+			"interfaces.br1.mtu is 1500, desired 9000",
+		}),
+		Entry("when interfaces are missing or present", `
+interfaces:
+- name: bond0
+  type: bond
+  state: up
+- name: eth1
+  type: ethernet
+  state: absent
+`, []string{
+			"interface bond0 is missing",
+			"interface eth1 is present, desired absent",
+		}),
+		Entry("when routes are missing or present", `
+routes:
+  config:
+  - destination: 203.0.113.0/24
+    next-hop-address: 10.10.10.254
+    next-hop-interface: br1
+  - destination: 198.51.100.0/24
+    next-hop-interface: br1
+    state: absent
+`, []string{
+			`route {"destination":"203.0.113.0/24","next-hop-address":"10.10.10.254","next-hop-interface":"br1"} is missing`,
+			`route {"destination":"198.51.100.0/24","next-hop-interface":"br1"} is present, desired absent`,
+		}),
+		Entry("when DNS servers are different", `
+dns-resolver:
+  config:
+    server:
+    - 8.8.8.8
+`, []string{
+			`dns-resolver.config.server is ["192.168.66.2"], desired ["8.8.8.8"]`,
+		}),
+	)
+})
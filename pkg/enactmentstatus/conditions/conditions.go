@@ -7,18 +7,24 @@ import (
 
 	"github.com/go-logr/logr"
 
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
 	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
+	"github.com/nmstate/kubernetes-nmstate/pkg/metrics"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 )
 
 type EnactmentConditions struct {
 	client       client.Client
 	enactmentKey types.NamespacedName
 	logger       logr.Logger
+	recorder     record.EventRecorder
+	eventObjects []runtime.Object
 }
 
 func New(client client.Client, enactmentKey types.NamespacedName) EnactmentConditions {
@@ -30,9 +36,29 @@ func New(client client.Client, enactmentKey types.NamespacedName) EnactmentCondi
 	return conditions
 }
 
+// RecordEvents makes the lifecycle notifications emit events at the given
+// objects, usually the policy and the node.
+func (ec *EnactmentConditions) RecordEvents(recorder record.EventRecorder, objects ...runtime.Object) {
+	ec.recorder = recorder
+	ec.eventObjects = objects
+}
+
+// Event emits an event at the objects passed to RecordEvents
+func (ec *EnactmentConditions) Event(eventType string, reason string, message string) {
+	if ec.recorder == nil {
+		return
+	}
+	message = fmt.Sprintf("%s: %s", ec.enactmentKey.Name, message)
+	for _, object := range ec.eventObjects {
+		ec.recorder.Event(object, eventType, reason, message)
+	}
+}
+
 func (ec *EnactmentConditions) NotifyNodeSelectorFailure(err error) {
 	ec.logger.Info("NotifyNodeSelectorFailure")
+	ec.observeOutcome(nmstate.NodeNetworkConfigurationEnactmentConditionFailedToConfigure)
 	message := fmt.Sprintf("failure checking node selectors : %v", err)
+	ec.Event(corev1.EventTypeWarning, string(nmstate.NodeNetworkConfigurationEnactmentConditionFailedToConfigure), message)
 	err = ec.updateEnactmentConditions(SetFailedToConfigure, message)
 	if err != nil {
 		ec.logger.Error(err, "Error notifying state NodeSelectorNotMatching with failure")
@@ -41,36 +67,92 @@ func (ec *EnactmentConditions) NotifyNodeSelectorFailure(err error) {
 
 func (ec *EnactmentConditions) NotifyProgressing() {
 	ec.logger.Info("NotifyProgressing")
+	ec.Event(corev1.EventTypeNormal, string(nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationProgressing), "Applying desired state")
 	err := ec.updateEnactmentConditions(SetProgressing, "Applying desired state")
 	if err != nil {
 		ec.logger.Error(err, "Error notifying state Progressing")
 	}
 }
 
+func (ec *EnactmentConditions) NotifyReverting() {
+	ec.logger.Info("NotifyReverting")
+	ec.Event(corev1.EventTypeNormal, string(nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationProgressing), "Reverting desired state")
+	err := ec.updateEnactmentConditions(SetProgressing, "Reverting desired state")
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state Reverting")
+	}
+}
+
+// NotifyFailedToConfigure sets the Failing condition with the reason
+// classifying failedErr, a short message and the truncated failedErr as
+// details, the event has the same reason and message
 func (ec *EnactmentConditions) NotifyFailedToConfigure(failedErr error) {
 	ec.logger.Info("NotifyFailedToConfigure")
-	err := ec.updateEnactmentConditions(SetFailedToConfigure, failedErr.Error())
+	failure := classifyFailure(failedErr)
+	ec.Event(corev1.EventTypeWarning, string(failure.reason), failure.message)
+	ec.observeOutcome(failure.reason)
+	err := ec.updateEnactmentConditions(func(conditions *nmstate.ConditionList, message string) {
+		SetFailedWithDetails(conditions, failure.reason, message, failure.details)
+	}, failure.message)
 	if err != nil {
 		ec.logger.Error(err, "Error notifying state FailingToConfigure")
 	}
 }
 
+// NotifyAborted sets the Aborted condition with failedErr, the event only
+// has its short message
 func (ec *EnactmentConditions) NotifyAborted(failedErr error) {
 	ec.logger.Info("NotifyConfigurationAborted")
+	ec.Event(corev1.EventTypeWarning, string(nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationAborted), nmstatectl.ShortError(failedErr))
+	ec.observeOutcome(nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationAborted)
 	err := ec.updateEnactmentConditions(SetConfigurationAborted, failedErr.Error())
 	if err != nil {
 		ec.logger.Error(err, "Error notifying state ConfigurationAborted")
 	}
 }
 
+// NotifyRevertFailed sets the Failing condition with the RevertFailed reason
+// after giving up reverting the configuration of a policy with cleanup
+func (ec *EnactmentConditions) NotifyRevertFailed(message string) {
+	ec.logger.Info("NotifyRevertFailed")
+	ec.Event(corev1.EventTypeWarning, string(nmstate.NodeNetworkConfigurationEnactmentConditionRevertFailed), message)
+	ec.observeOutcome(nmstate.NodeNetworkConfigurationEnactmentConditionRevertFailed)
+	err := ec.updateEnactmentConditions(func(conditions *nmstate.ConditionList, message string) {
+		SetFailed(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionRevertFailed, message)
+	}, message)
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state RevertFailed")
+	}
+}
+
 func (ec *EnactmentConditions) NotifySuccess() {
 	ec.logger.Info("NotifySuccess")
+	ec.Event(corev1.EventTypeNormal, string(nmstate.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured), "Desired state successfully applied")
+	ec.observeOutcome(nmstate.NodeNetworkConfigurationEnactmentConditionSuccessfullyConfigured)
 	err := ec.updateEnactmentConditions(SetSuccess, "successfully reconciled")
 	if err != nil {
 		ec.logger.Error(err, "Error notifying state Success")
 	}
 }
 
+func (ec *EnactmentConditions) NotifyDryRunSucceeded(diff string) {
+	ec.logger.Info("NotifyDryRunSucceeded")
+	ec.observeOutcome(nmstate.NodeNetworkConfigurationEnactmentConditionDryRunConfigured)
+	err := ec.updateEnactmentDryRun(SetDryRunSucceeded, "desired state can be applied, configuration rolled back", diff)
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state DryRunSucceeded")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyDryRunFailed(failedErr error) {
+	ec.logger.Info("NotifyDryRunFailed")
+	ec.observeOutcome(nmstate.NodeNetworkConfigurationEnactmentConditionDryRunFailedToConfigure)
+	err := ec.updateEnactmentDryRun(SetDryRunFailed, failedErr.Error(), "")
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state DryRunFailed")
+	}
+}
+
 func (ec *EnactmentConditions) NotifyPending() {
 	ec.logger.Info("NotifyPending")
 	err := ec.updateEnactmentConditions(SetPending, "Max unavailable node limit reached")
@@ -79,6 +161,38 @@ func (ec *EnactmentConditions) NotifyPending() {
 	}
 }
 
+func (ec *EnactmentConditions) NotifyPaused() {
+	ec.logger.Info("NotifyPaused")
+	err := ec.updateEnactmentConditions(SetPaused, "Policy rollout is paused")
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state Paused")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyWaitingForRolloutStep(message string) {
+	ec.logger.Info("NotifyWaitingForRolloutStep")
+	err := ec.updateEnactmentConditions(SetWaitingForRolloutStep, message)
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state WaitingForRolloutStep")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyDrifted(message string) {
+	ec.logger.Info("NotifyDrifted")
+	err := ec.updateEnactmentConditions(SetDrifted, message)
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state Drifted")
+	}
+}
+
+func (ec *EnactmentConditions) NotifyInSync() {
+	ec.logger.Info("NotifyInSync")
+	err := ec.updateEnactmentConditions(SetInSync, "")
+	if err != nil {
+		ec.logger.Error(err, "Error notifying state InSync")
+	}
+}
+
 func (ec *EnactmentConditions) Reset() {
 	ec.logger.Info("Reset")
 	err := ec.updateEnactmentConditions(func(conditionList *nmstate.ConditionList, message string) {
@@ -89,6 +203,11 @@ func (ec *EnactmentConditions) Reset() {
 	}
 }
 
+// observeOutcome counts the enactment outcome at the metrics
+func (ec *EnactmentConditions) observeOutcome(reason nmstate.ConditionReason) {
+	metrics.EnactmentOutcomes.WithLabelValues(string(reason)).Inc()
+}
+
 func (ec *EnactmentConditions) updateEnactmentConditions(
 	conditionsSetter func(*nmstate.ConditionList, string),
 	message string,
@@ -99,16 +218,35 @@ func (ec *EnactmentConditions) updateEnactmentConditions(
 		})
 }
 
+func (ec *EnactmentConditions) updateEnactmentDryRun(
+	conditionsSetter func(*nmstate.ConditionList, string),
+	message string,
+	diff string,
+) error {
+	return enactmentstatus.Update(ec.client, ec.enactmentKey,
+		func(status *nmstate.NodeNetworkConfigurationEnactmentStatus) {
+			conditionsSetter(&status.Conditions, message)
+			status.DryRunDiff = diff
+		})
+}
+
 func SetFailedToConfigure(conditions *nmstate.ConditionList, message string) {
 	SetFailed(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionFailedToConfigure, message)
 }
 
 func SetFailed(conditions *nmstate.ConditionList, reason nmstate.ConditionReason, message string) {
-	conditions.Set(
+	SetFailedWithDetails(conditions, reason, message, "")
+}
+
+// SetFailedWithDetails sets the Failing condition like SetFailed and keeps
+// the whole failure at its details
+func SetFailedWithDetails(conditions *nmstate.ConditionList, reason nmstate.ConditionReason, message string, details string) {
+	conditions.SetWithDetails(
 		nmstate.NodeNetworkConfigurationEnactmentConditionFailing,
 		corev1.ConditionTrue,
 		reason,
 		message,
+		details,
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionAvailable,
@@ -240,34 +378,109 @@ func SetProgressing(conditions *nmstate.ConditionList, message string) {
 }
 
 func SetPending(conditions *nmstate.ConditionList, message string) {
+	setPending(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached, message)
+}
+
+// SetPaused keeps the enactment pending until the policy is resumed
+func SetPaused(conditions *nmstate.ConditionList, message string) {
+	setPending(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionPaused, message)
+}
+
+// SetWaitingForRolloutStep keeps the enactment pending until the node
+// rollout step starts
+func SetWaitingForRolloutStep(conditions *nmstate.ConditionList, message string) {
+	setPending(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionWaitingForRolloutStep, message)
+}
+
+func setPending(conditions *nmstate.ConditionList, reason nmstate.ConditionReason, message string) {
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionPending,
 		corev1.ConditionTrue,
-		nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached,
+		reason,
 		message,
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionAborted,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached,
+		reason,
 		"",
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionProgressing,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached,
+		reason,
 		message,
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionFailing,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached,
+		reason,
 		"",
 	)
 	conditions.Set(
 		nmstate.NodeNetworkConfigurationEnactmentConditionAvailable,
 		corev1.ConditionFalse,
-		nmstate.NodeNetworkConfigurationEnactmentConditionMaxUnavailableLimitReached,
+		reason,
 		"",
 	)
 }
+
+// SetDrifted marks that the node configuration does not match the applied
+// desired state anymore, the other conditions are kept
+func SetDrifted(conditions *nmstate.ConditionList, message string) {
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionDrifted,
+		corev1.ConditionTrue,
+		nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationDrifted,
+		message,
+	)
+}
+
+func SetInSync(conditions *nmstate.ConditionList, message string) {
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionDrifted,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationEnactmentConditionConfigurationInSync,
+		message,
+	)
+}
+
+func SetDryRunSucceeded(conditions *nmstate.ConditionList, message string) {
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionDryRunSucceeded,
+		corev1.ConditionTrue,
+		nmstate.NodeNetworkConfigurationEnactmentConditionDryRunConfigured,
+		message,
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionDryRunFailed,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationEnactmentConditionDryRunConfigured,
+		"",
+	)
+	setDryRunFinished(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionDryRunConfigured)
+}
+
+func SetDryRunFailed(conditions *nmstate.ConditionList, message string) {
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionDryRunFailed,
+		corev1.ConditionTrue,
+		nmstate.NodeNetworkConfigurationEnactmentConditionDryRunFailedToConfigure,
+		message,
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationEnactmentConditionDryRunSucceeded,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationEnactmentConditionDryRunFailedToConfigure,
+		"",
+	)
+	setDryRunFinished(conditions, nmstate.NodeNetworkConfigurationEnactmentConditionDryRunFailedToConfigure)
+}
+
+// setDryRunFinished sets the non dry run conditions to false since nothing
+// has being committed at the node
+func setDryRunFinished(conditions *nmstate.ConditionList, reason nmstate.ConditionReason) {
+	for _, conditionType := range nmstate.NodeNetworkConfigurationEnactmentConditionTypes {
+		conditions.Set(conditionType, corev1.ConditionFalse, reason, "")
+	}
+}
@@ -0,0 +1,60 @@
+package conditions
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+)
+
+var _ = Describe("Enactment conditions events", func() {
+	var (
+		recorder            *record.FakeRecorder
+		enactmentConditions EnactmentConditions
+	)
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		enactmentConditions = New(fake.NewClientBuilder().Build(), nmstate.EnactmentKey("node01", "policy1"))
+	})
+	It("should not emit events without recorder", func() {
+		enactmentConditions.NotifyProgressing()
+		Expect(recorder.Events).To(BeEmpty())
+	})
+	Context("with recorder", func() {
+		BeforeEach(func() {
+			policy := &nmstatev1beta1.NodeNetworkConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy1"}}
+			node := &corev1.ObjectReference{APIVersion: "v1", Kind: "Node", Name: "node01"}
+			enactmentConditions.RecordEvents(recorder, policy, node)
+		})
+		It("should emit a normal event at every object when progressing", func() {
+			enactmentConditions.NotifyProgressing()
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(Equal("Normal ConfigurationProgressing node01.policy1: Applying desired state"))
+		})
+		It("should emit a warning with the short nmstate error when failing", func() {
+			enactmentConditions.NotifyFailedToConfigure(fmt.Errorf("failed to execute nmstatectl set: 'exit status 1' '' 'libnmstate.error.NmstateValueError: Interface eth666 not found'"))
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(Equal("Warning FailedToConfigure node01.policy1: NmstateValueError: Interface eth666 not found"))
+		})
+		It("should emit a warning with the classified reason when failing", func() {
+			enactmentConditions.NotifyFailedToConfigure(nmstateError(nmstatectl.OperationSet, "NmstateValueError", "Interface eth666 not found"))
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(Equal("Warning InvalidArgument node01.policy1: NmstateValueError: Interface eth666 not found"))
+		})
+		It("should emit a warning with the short error when aborted", func() {
+			enactmentConditions.NotifyAborted(fmt.Errorf("policy has failing enactments, aborting\n%s", strings.Repeat("nmstatectl output\n", 100)))
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(Equal("Warning ConfigurationAborted node01.policy1: policy has failing enactments, aborting"))
+		})
+	})
+})
@@ -15,7 +15,8 @@ type ConditionCount map[nmstate.ConditionType]CountByConditionStatus
 
 func Count(enactments nmstatev1beta1.NodeNetworkConfigurationEnactmentList, policyGeneration int64) ConditionCount {
 	conditionCount := ConditionCount{}
-	for _, conditionType := range nmstate.NodeNetworkConfigurationEnactmentConditionTypes {
+	conditionTypes := append(nmstate.NodeNetworkConfigurationEnactmentConditionTypes[:], nmstate.NodeNetworkConfigurationEnactmentDryRunConditionTypes[:]...)
+	for _, conditionType := range conditionTypes {
 		conditionCount[conditionType] = CountByConditionStatus{
 			corev1.ConditionTrue:    0,
 			corev1.ConditionFalse:   0,
@@ -50,6 +51,13 @@ func (c ConditionCount) aborted() CountByConditionStatus {
 	return c[nmstate.NodeNetworkConfigurationEnactmentConditionAborted]
 }
 
+func (c ConditionCount) dryRunSucceeded() CountByConditionStatus {
+	return c[nmstate.NodeNetworkConfigurationEnactmentConditionDryRunSucceeded]
+}
+func (c ConditionCount) dryRunFailed() CountByConditionStatus {
+	return c[nmstate.NodeNetworkConfigurationEnactmentConditionDryRunFailed]
+}
+
 func (c CountByConditionStatus) true() int {
 	return c[corev1.ConditionTrue]
 }
@@ -93,8 +101,15 @@ func (c ConditionCount) NotAborted() int {
 	return c.aborted().false()
 }
 
+func (c ConditionCount) DryRunSucceeded() int {
+	return c.dryRunSucceeded().true()
+}
+func (c ConditionCount) DryRunFailed() int {
+	return c.dryRunFailed().true()
+}
+
 func (c ConditionCount) String() string {
-	return fmt.Sprintf("{failed: %s, progressing: %s, pending: %s, available: %s, aborted: %s}", c.failed(), c.progressing(), c.pending(), c.available(), c.aborted())
+	return fmt.Sprintf("{failed: %s, progressing: %s, pending: %s, available: %s, aborted: %s, dryRunSucceeded: %s, dryRunFailed: %s}", c.failed(), c.progressing(), c.pending(), c.available(), c.aborted(), c.dryRunSucceeded(), c.dryRunFailed())
 }
 
 func (c CountByConditionStatus) String() string {
@@ -17,6 +17,8 @@ const (
 	progressing = nmstate.NodeNetworkConfigurationEnactmentConditionProgressing
 	pending     = nmstate.NodeNetworkConfigurationEnactmentConditionPending
 	aborted     = nmstate.NodeNetworkConfigurationEnactmentConditionAborted
+	dryRunOK    = nmstate.NodeNetworkConfigurationEnactmentConditionDryRunSucceeded
+	dryRunKO    = nmstate.NodeNetworkConfigurationEnactmentConditionDryRunFailed
 	t           = corev1.ConditionTrue
 	f           = corev1.ConditionFalse
 	u           = corev1.ConditionUnknown
@@ -65,6 +67,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 0, u: 2},
 				pending:     CountByConditionStatus{t: 0, f: 0, u: 2},
 				aborted:     CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("e(Failed), e(Progressing)", EnactmentCounterCase{
@@ -79,6 +83,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 1, f: 1, u: 0},
 				pending:     CountByConditionStatus{t: 0, f: 2, u: 0},
 				aborted:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("e(Success), e(Progressing)", EnactmentCounterCase{
@@ -93,6 +99,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 1, f: 1, u: 0},
 				pending:     CountByConditionStatus{t: 0, f: 2, u: 0},
 				aborted:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("e(Progressing), e(Progressing)", EnactmentCounterCase{
@@ -107,6 +115,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 2, f: 0, u: 0},
 				pending:     CountByConditionStatus{t: 0, f: 2, u: 0},
 				aborted:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("e(Success), e(Success)", EnactmentCounterCase{
@@ -121,6 +131,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 2, u: 0},
 				pending:     CountByConditionStatus{t: 0, f: 2, u: 0},
 				aborted:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("e(Failed), e(Failed)", EnactmentCounterCase{
@@ -135,6 +147,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 2, u: 0},
 				pending:     CountByConditionStatus{t: 0, f: 2, u: 0},
 				aborted:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("e(Failed), e(Aborted)", EnactmentCounterCase{
@@ -149,6 +163,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 2, u: 0},
 				pending:     CountByConditionStatus{t: 0, f: 2, u: 0},
 				aborted:     CountByConditionStatus{t: 1, f: 1, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("e(Pending), e(Progressing)", EnactmentCounterCase{
@@ -163,6 +179,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 1, f: 1, u: 0},
 				pending:     CountByConditionStatus{t: 1, f: 1, u: 0},
 				aborted:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("e(Pending), e(Success)", EnactmentCounterCase{
@@ -177,6 +195,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 2, u: 0},
 				pending:     CountByConditionStatus{t: 1, f: 1, u: 0},
 				aborted:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("e(Pending), e(Failed)", EnactmentCounterCase{
@@ -191,6 +211,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 2, u: 0},
 				pending:     CountByConditionStatus{t: 1, f: 1, u: 0},
 				aborted:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("e(Pending), e(Aborted)", EnactmentCounterCase{
@@ -205,6 +227,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 2, u: 0},
 				pending:     CountByConditionStatus{t: 1, f: 1, u: 0},
 				aborted:     CountByConditionStatus{t: 1, f: 1, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("p(2), e(1,Progressing), e(2,Progressing)", EnactmentCounterCase{
@@ -219,6 +243,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 1, f: 0, u: 1},
 				pending:     CountByConditionStatus{t: 0, f: 1, u: 1},
 				aborted:     CountByConditionStatus{t: 0, f: 1, u: 1},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("p(2), e(1,Pending), e(2,Pending)", EnactmentCounterCase{
@@ -233,6 +259,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 1, u: 1},
 				pending:     CountByConditionStatus{t: 1, f: 0, u: 1},
 				aborted:     CountByConditionStatus{t: 0, f: 1, u: 1},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("p(2), e(1,Success), e(2,Success)", EnactmentCounterCase{
@@ -247,6 +275,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 1, u: 1},
 				pending:     CountByConditionStatus{t: 0, f: 1, u: 1},
 				aborted:     CountByConditionStatus{t: 0, f: 1, u: 1},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("p(2), e(1,Failed), e(2,Failed)", EnactmentCounterCase{
@@ -261,6 +291,8 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 1, u: 1},
 				pending:     CountByConditionStatus{t: 0, f: 1, u: 1},
 				aborted:     CountByConditionStatus{t: 0, f: 1, u: 1},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
 			},
 		}),
 		Entry("p(2), e(1,Failed), e(2,Aborted)", EnactmentCounterCase{
@@ -275,6 +307,40 @@ var _ = Describe("Enactment condition counter", func() {
 				progressing: CountByConditionStatus{t: 0, f: 1, u: 1},
 				pending:     CountByConditionStatus{t: 0, f: 1, u: 1},
 				aborted:     CountByConditionStatus{t: 1, f: 0, u: 1},
+				dryRunOK:    CountByConditionStatus{t: 0, f: 0, u: 2},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 0, u: 2},
+			},
+		}),
+		Entry("e(DryRunSucceeded), e(DryRunFailed)", EnactmentCounterCase{
+			policyGeneration: 1,
+			enactmentsToCount: enactments(
+				enactment(1, SetDryRunSucceeded),
+				enactment(1, SetDryRunFailed),
+			),
+			expectedCount: ConditionCount{
+				available:   CountByConditionStatus{t: 0, f: 2, u: 0},
+				failing:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				progressing: CountByConditionStatus{t: 0, f: 2, u: 0},
+				pending:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				aborted:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 1, f: 1, u: 0},
+				dryRunKO:    CountByConditionStatus{t: 1, f: 1, u: 0},
+			},
+		}),
+		Entry("e(Progressing), e(DryRunSucceeded)", EnactmentCounterCase{
+			policyGeneration: 1,
+			enactmentsToCount: enactments(
+				enactment(1, SetProgressing),
+				enactment(1, SetDryRunSucceeded),
+			),
+			expectedCount: ConditionCount{
+				available:   CountByConditionStatus{t: 0, f: 1, u: 1},
+				failing:     CountByConditionStatus{t: 0, f: 1, u: 1},
+				progressing: CountByConditionStatus{t: 1, f: 1, u: 0},
+				pending:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				aborted:     CountByConditionStatus{t: 0, f: 2, u: 0},
+				dryRunOK:    CountByConditionStatus{t: 1, f: 0, u: 1},
+				dryRunKO:    CountByConditionStatus{t: 0, f: 1, u: 1},
 			},
 		}),
 	)
@@ -0,0 +1,77 @@
+package conditions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+	"github.com/nmstate/kubernetes-nmstate/pkg/probe"
+)
+
+// failureDetailsMaxLength keeps the Failing condition details small, the
+// nmstatectl output can be huge
+const failureDetailsMaxLength = 4096
+
+// nmstateErrorReasons maps the nmstate errors to the Failing reasons, both
+// the python exceptions and the rust error kinds reported by varlink
+var nmstateErrorReasons = map[string]nmstate.ConditionReason{
+	"NmstateValueError":                nmstate.NodeNetworkConfigurationEnactmentConditionInvalidArgument,
+	"NmstateKernelIntegerRoundedError": nmstate.NodeNetworkConfigurationEnactmentConditionInvalidArgument,
+	"InvalidArgument":                  nmstate.NodeNetworkConfigurationEnactmentConditionInvalidArgument,
+	"KernelIntegerRoundedError":        nmstate.NodeNetworkConfigurationEnactmentConditionInvalidArgument,
+	"NmstateDependencyError":           nmstate.NodeNetworkConfigurationEnactmentConditionDependencyError,
+	"DependencyError":                  nmstate.NodeNetworkConfigurationEnactmentConditionDependencyError,
+	"NmstateVerificationError":         nmstate.NodeNetworkConfigurationEnactmentConditionVerificationFailed,
+	"VerificationError":                nmstate.NodeNetworkConfigurationEnactmentConditionVerificationFailed,
+	"NmstateTimeoutError":              nmstate.NodeNetworkConfigurationEnactmentConditionNetworkManagerTimeout,
+	"Timeout":                          nmstate.NodeNetworkConfigurationEnactmentConditionNetworkManagerTimeout,
+}
+
+// failure is a classified failure applying the desired state
+type failure struct {
+	reason  nmstate.ConditionReason
+	message string
+	details string
+}
+
+// classifyFailure returns the Failing reason for err with a short message,
+// the rollback failures come first since they need manual intervention,
+// then the probes since they trigger the rollback, then the nmstate errors.
+func classifyFailure(err error) failure {
+	classified := failure{
+		reason:  nmstate.NodeNetworkConfigurationEnactmentConditionFailedToConfigure,
+		message: nmstatectl.ShortError(err),
+		details: truncateDetails(err.Error()),
+	}
+
+	var nmstateErr *nmstatectl.Error
+	isNmstateErr := errors.As(err, &nmstateErr)
+
+	if nmstatectl.IsRollbackFailure(err) {
+		classified.reason = nmstate.NodeNetworkConfigurationEnactmentConditionRollbackFailed
+		classified.message = fmt.Sprintf("failed rolling back desired state, manual intervention needed: %s", classified.message)
+	} else if probeName := probe.FailedName(err); probeName != "" {
+		classified.reason = nmstate.NodeNetworkConfigurationEnactmentConditionProbeFailed
+		classified.message = fmt.Sprintf("probe '%s' failed after applying desired state, rolled back", probeName)
+	} else if isNmstateErr && nmstateErr.Operation == nmstatectl.OperationCommit {
+		classified.reason = nmstate.NodeNetworkConfigurationEnactmentConditionCommitFailed
+		classified.message = fmt.Sprintf("failed committing desired state: %s", classified.message)
+	} else if isNmstateErr {
+		if reason, ok := nmstateErrorReasons[nmstateErr.Class]; ok {
+			classified.reason = reason
+		}
+	}
+	return classified
+}
+
+// truncateDetails keeps the end of details since the nmstate error is
+// usually at the last lines of its output
+func truncateDetails(details string) string {
+	if len(details) <= failureDetailsMaxLength {
+		return details
+	}
+	return strings.ToValidUTF8("..."+details[len(details)-failureDetailsMaxLength+3:], "")
+}
@@ -0,0 +1,107 @@
+package conditions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+)
+
+func nmstateError(operation, class, message string) error {
+	return errors.Wrap(&nmstatectl.Error{
+		Operation: operation,
+		Class:     class,
+		Message:   message,
+		Details:   fmt.Sprintf("failed to execute nmstatectl %s: 'exit status 1' '' 'libnmstate.error.%s: %s'", operation, class, message),
+	}, "error reconciling NodeNetworkConfigurationPolicy at desired state apply")
+}
+
+var _ = Describe("Enactment failure classification", func() {
+	DescribeTable("classifying nmstate failures",
+		func(err error, expectedReason nmstate.ConditionReason, expectedMessage string) {
+			failure := classifyFailure(err)
+			Expect(failure.reason).To(Equal(expectedReason))
+			Expect(failure.message).To(Equal(expectedMessage))
+			Expect(failure.details).To(Equal(err.Error()))
+		},
+		Entry("invalid argument",
+			nmstateError(nmstatectl.OperationSet, "NmstateValueError", "Interface eth666 not found"),
+			nmstate.NodeNetworkConfigurationEnactmentConditionInvalidArgument,
+			"NmstateValueError: Interface eth666 not found"),
+		Entry("invalid argument from varlink",
+			nmstateError(nmstatectl.OperationSet, "InvalidArgument", "Invalid bond mode"),
+			nmstate.NodeNetworkConfigurationEnactmentConditionInvalidArgument,
+			"InvalidArgument: Invalid bond mode"),
+		Entry("dependency error",
+			nmstateError(nmstatectl.OperationSet, "NmstateDependencyError", "NetworkManager-ovs is not installed"),
+			nmstate.NodeNetworkConfigurationEnactmentConditionDependencyError,
+			"NmstateDependencyError: NetworkManager-ovs is not installed"),
+		Entry("verification error",
+			nmstateError(nmstatectl.OperationSet, "NmstateVerificationError", "desired state does not match current state"),
+			nmstate.NodeNetworkConfigurationEnactmentConditionVerificationFailed,
+			"NmstateVerificationError: desired state does not match current state"),
+		Entry("NetworkManager timeout",
+			nmstateError(nmstatectl.OperationSet, "NmstateTimeoutError", "Connection activation timed out"),
+			nmstate.NodeNetworkConfigurationEnactmentConditionNetworkManagerTimeout,
+			"NmstateTimeoutError: Connection activation timed out"),
+		Entry("commit failure",
+			nmstateError(nmstatectl.OperationCommit, "NmstateLibnmError", "Checkpoint not found"),
+			nmstate.NodeNetworkConfigurationEnactmentConditionCommitFailed,
+			"failed committing desired state: NmstateLibnmError: Checkpoint not found"),
+		Entry("unclassified nmstate error",
+			nmstateError(nmstatectl.OperationSet, "NmstateLibnmError", "Unexpected failure of libnm"),
+			nmstate.NodeNetworkConfigurationEnactmentConditionFailedToConfigure,
+			"NmstateLibnmError: Unexpected failure of libnm"),
+		Entry("rollback failure",
+			nmstatectl.NewRollbackFailedError(errors.Wrap(nmstateError(nmstatectl.OperationRollback, "NmstateLibnmError", "Checkpoint not found"), "rolling back desired state configuration")),
+			nmstate.NodeNetworkConfigurationEnactmentConditionRollbackFailed,
+			"failed rolling back desired state, manual intervention needed: NmstateLibnmError: Checkpoint not found"),
+		Entry("not a nmstate failure",
+			errors.New("failed rendering desired state for node: template: missing value"),
+			nmstate.NodeNetworkConfigurationEnactmentConditionFailedToConfigure,
+			"failed rendering desired state for node: template: missing value"),
+	)
+
+	It("should keep the end of long details", func() {
+		err := nmstateError(nmstatectl.OperationSet, "NmstateValueError", strings.Repeat("a", 5000)+"end")
+		details := classifyFailure(err).details
+		Expect(details).To(HaveLen(failureDetailsMaxLength))
+		Expect(details).To(HavePrefix("..."))
+		Expect(details).To(HaveSuffix("aend'"))
+	})
+
+	It("should set the Failing condition with the classified reason and details", func() {
+		enactmentKey := nmstate.EnactmentKey("node01", "policy1")
+		enactment := nmstatev1beta1.NewEnactment("node01", nmstatev1beta1.NodeNetworkConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy1"}})
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion, &nmstatev1beta1.NodeNetworkConfigurationEnactment{})
+		cli := fake.NewFakeClientWithScheme(s, &enactment)
+
+		err := nmstateError(nmstatectl.OperationSet, "NmstateValueError", "Interface eth666 not found")
+		enactmentConditions := New(cli, enactmentKey)
+		enactmentConditions.NotifyFailedToConfigure(err)
+
+		Expect(cli.Get(context.TODO(), enactmentKey, &enactment)).To(Succeed())
+		failing := enactment.Status.Conditions.Find(nmstate.NodeNetworkConfigurationEnactmentConditionFailing)
+		Expect(failing).ToNot(BeNil())
+		Expect(failing.Status).To(Equal(corev1.ConditionTrue))
+		Expect(failing.Reason).To(Equal(nmstate.NodeNetworkConfigurationEnactmentConditionInvalidArgument))
+		Expect(failing.Message).To(Equal("NmstateValueError: Interface eth666 not found"))
+		Expect(failing.Details).To(Equal(err.Error()))
+		available := enactment.Status.Conditions.Find(nmstate.NodeNetworkConfigurationEnactmentConditionAvailable)
+		Expect(available.Reason).To(Equal(nmstate.NodeNetworkConfigurationEnactmentConditionInvalidArgument))
+	})
+})
@@ -63,3 +63,11 @@ func IsProgressing(conditions *nmstate.ConditionList) bool {
 	}
 	return false
 }
+
+func IsAvailable(conditions *nmstate.ConditionList) bool {
+	availableCondition := conditions.Find(nmstate.NodeNetworkConfigurationEnactmentConditionAvailable)
+	if availableCondition != nil && availableCondition.Status == corev1.ConditionTrue {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+//go:build linux
+// +build linux
+
+package hardware
+
+import (
+	"net"
+	"runtime"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// maxLinkModeMasksNwords is the maximum of the s8 the kernel uses to
+	// size the link mode masks
+	maxLinkModeMasksNwords = 127
+	maxAddrLen             = 32
+)
+
+// ifreqData is the struct ifreq with the ifr_data member, padded to the size
+// of the union
+type ifreqData struct {
+	name [unix.IFNAMSIZ]byte
+	data unsafe.Pointer
+	_    [16]byte
+}
+
+// ethtoolLinkSettings is the struct ethtool_link_settings followed by the
+// supported, advertising and lp_advertising link mode masks
+type ethtoolLinkSettings struct {
+	cmd                 uint32
+	speed               uint32
+	duplex              uint8
+	port                uint8
+	phyAddress          uint8
+	autoneg             uint8
+	mdioSupport         uint8
+	ethTPMDIX           uint8
+	ethTPMDIXCtrl       uint8
+	linkModeMasksNwords int8
+	transceiver         uint8
+	masterSlaveCfg      uint8
+	masterSlaveState    uint8
+	reserved1           [1]uint8
+	reserved            [7]uint32
+	linkModeMasks       [3 * maxLinkModeMasksNwords]uint32
+}
+
+// ethtoolPermAddr is the struct ethtool_perm_addr
+type ethtoolPermAddr struct {
+	cmd  uint32
+	size uint32
+	data [maxAddrLen]byte
+}
+
+// ioctlEthtool sends the ethtool ioctls through a socket
+type ioctlEthtool struct {
+	fd int
+}
+
+func openEthtool() (ethtool, error) {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed opening ethtool socket")
+	}
+	return &ioctlEthtool{fd: fd}, nil
+}
+
+func (e *ioctlEthtool) close() error {
+	return unix.Close(e.fd)
+}
+
+func (e *ioctlEthtool) ioctl(name string, data unsafe.Pointer) error {
+	// Leave room for terminating NULL byte.
+	if len(name) >= unix.IFNAMSIZ {
+		return unix.EINVAL
+	}
+	ifreq := ifreqData{data: data}
+	copy(ifreq.name[:], name)
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(e.fd), unix.SIOCETHTOOL, uintptr(unsafe.Pointer(&ifreq)))
+	runtime.KeepAlive(ifreq)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func (e *ioctlEthtool) driverInfo(name string) (driverInfo, error) {
+	info, err := unix.IoctlGetEthtoolDrvinfo(e.fd, name)
+	if err != nil {
+		return driverInfo{}, err
+	}
+	return driverInfo{
+		driver:          unix.ByteSliceToString(info.Driver[:]),
+		firmwareVersion: unix.ByteSliceToString(info.Fw_version[:]),
+	}, nil
+}
+
+func (e *ioctlEthtool) supportedLinkModes(name string) ([]string, error) {
+	// The kernel answers the first request with the negated number of
+	// words of the masks, the second one gets them
+	settings := ethtoolLinkSettings{cmd: unix.ETHTOOL_GLINKSETTINGS}
+	if err := e.ioctl(name, unsafe.Pointer(&settings)); err != nil {
+		return nil, err
+	}
+	nwords := -settings.linkModeMasksNwords
+	if nwords <= 0 {
+		return nil, errors.New("link mode masks handshake failed")
+	}
+
+	settings = ethtoolLinkSettings{cmd: unix.ETHTOOL_GLINKSETTINGS, linkModeMasksNwords: nwords}
+	if err := e.ioctl(name, unsafe.Pointer(&settings)); err != nil {
+		return nil, err
+	}
+	return linkModeNames(settings.linkModeMasks[:nwords]), nil
+}
+
+func (e *ioctlEthtool) permanentAddress(name string) (string, error) {
+	permAddr := ethtoolPermAddr{cmd: unix.ETHTOOL_GPERMADDR, size: maxAddrLen}
+	if err := e.ioctl(name, unsafe.Pointer(&permAddr)); err != nil {
+		return "", err
+	}
+	address := permAddr.data[:permAddr.size]
+	for _, b := range address {
+		if b != 0 {
+			return net.HardwareAddr(address).String(), nil
+		}
+	}
+	return "", nil
+}
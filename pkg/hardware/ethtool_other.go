@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package hardware
+
+import (
+	"fmt"
+)
+
+func openEthtool() (ethtool, error) {
+	return nil, fmt.Errorf("reading the NICs hardware is only supported on linux")
+}
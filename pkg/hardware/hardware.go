@@ -0,0 +1,138 @@
+package hardware
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var (
+	log = logf.Log.WithName("hardware")
+)
+
+const (
+	sysClassNet  = "/sys/class/net"
+	pciSubsystem = "pci"
+)
+
+// ethtool reads the NIC settings that are not exposed at sysfs
+type ethtool interface {
+	driverInfo(name string) (driverInfo, error)
+	supportedLinkModes(name string) ([]string, error)
+	permanentAddress(name string) (string, error)
+	close() error
+}
+
+type driverInfo struct {
+	driver          string
+	firmwareVersion string
+}
+
+// Inventory returns the hardware of the physical NICs, the interfaces with a
+// device at sysfs, the settings that cannot be read are not set.
+func Inventory() ([]shared.InterfaceHardware, error) {
+	ethtool, err := openEthtool()
+	if err != nil {
+		return nil, err
+	}
+	defer ethtool.close()
+	return inventory(sysClassNet, ethtool)
+}
+
+func inventory(sysfsNet string, ethtool ethtool) ([]shared.InterfaceHardware, error) {
+	entries, err := ioutil.ReadDir(sysfsNet)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed listing interfaces at %s", sysfsNet)
+	}
+
+	hardware := []shared.InterfaceHardware{}
+	for _, entry := range entries {
+		name := entry.Name()
+		device, err := filepath.EvalSymlinks(filepath.Join(sysfsNet, name, "device"))
+		if err != nil {
+			// Virtual interfaces have no device
+			continue
+		}
+		hardware = append(hardware, nicHardware(filepath.Join(sysfsNet, name), device, ethtool))
+	}
+	if len(hardware) == 0 {
+		return nil, nil
+	}
+	return hardware, nil
+}
+
+func nicHardware(ifacePath, device string, ethtool ethtool) shared.InterfaceHardware {
+	name := filepath.Base(ifacePath)
+	nic := shared.InterfaceHardware{Interface: name}
+
+	// PCI devices are named after their address
+	if linkBase(filepath.Join(device, "subsystem")) == pciSubsystem {
+		nic.PCIAddress = filepath.Base(device)
+	}
+	nic.Driver = linkBase(filepath.Join(device, "driver"))
+	if numaNode, err := readInt(filepath.Join(device, "numa_node")); err == nil && numaNode >= 0 {
+		nic.NUMANode = &numaNode
+	}
+	if totalVFs, err := readInt(filepath.Join(device, "sriov_totalvfs")); err == nil {
+		nic.SRIOVTotalVFs = totalVFs
+	}
+
+	// speed and duplex cannot be read while the link is down
+	if speed, err := readInt(filepath.Join(ifacePath, "speed")); err == nil && speed > 0 {
+		nic.Speed = speed
+	}
+	if duplex, err := readString(filepath.Join(ifacePath, "duplex")); err == nil && duplex != "unknown" {
+		nic.Duplex = duplex
+	}
+
+	info, err := ethtool.driverInfo(name)
+	if err != nil {
+		log.V(1).Info("failed reading driver info", "interface", name, "error", err.Error())
+	} else {
+		nic.FirmwareVersion = info.firmwareVersion
+		if nic.Driver == "" {
+			nic.Driver = info.driver
+		}
+	}
+	nic.SupportedLinkModes, err = ethtool.supportedLinkModes(name)
+	if err != nil {
+		log.V(1).Info("failed reading supported link modes", "interface", name, "error", err.Error())
+	}
+	nic.PermanentMACAddress, err = ethtool.permanentAddress(name)
+	if err != nil {
+		log.V(1).Info("failed reading permanent address", "interface", name, "error", err.Error())
+	}
+	return nic
+}
+
+// linkBase returns the base of the symlink target, empty if it is missing
+func linkBase(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
+
+func readString(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func readInt(path string) (int, error) {
+	content, err := readString(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(content)
+}
@@ -0,0 +1,16 @@
+package hardware
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.hardware-hardware_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Hardware Test Suite", []Reporter{junitReporter})
+}
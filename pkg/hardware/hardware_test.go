@@ -0,0 +1,157 @@
+package hardware
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+type fakeEthtool struct {
+	infos     map[string]driverInfo
+	linkModes map[string][]string
+	addresses map[string]string
+}
+
+func (f fakeEthtool) driverInfo(name string) (driverInfo, error) {
+	info, ok := f.infos[name]
+	if !ok {
+		return driverInfo{}, fmt.Errorf("operation not supported")
+	}
+	return info, nil
+}
+
+func (f fakeEthtool) supportedLinkModes(name string) ([]string, error) {
+	linkModes, ok := f.linkModes[name]
+	if !ok {
+		return nil, fmt.Errorf("operation not supported")
+	}
+	return linkModes, nil
+}
+
+func (f fakeEthtool) permanentAddress(name string) (string, error) {
+	return f.addresses[name], nil
+}
+
+func (f fakeEthtool) close() error {
+	return nil
+}
+
+var _ = Describe("Inventory", func() {
+	var (
+		sysfs    string
+		sysfsNet string
+		ethtool  fakeEthtool
+	)
+
+	writeFile := func(path, content string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content+"\n"), 0644)).To(Succeed())
+	}
+
+	symlink := func(target, path string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.Symlink(target, path)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		sysfs, err = ioutil.TempDir("", "hardware-sysfs")
+		Expect(err).ToNot(HaveOccurred())
+		sysfsNet = filepath.Join(sysfs, "class", "net")
+
+		pciDevice := filepath.Join(sysfs, "devices", "pci0000:3a", "0000:3b:00.0")
+		writeFile(filepath.Join(pciDevice, "numa_node"), "1")
+		writeFile(filepath.Join(pciDevice, "sriov_totalvfs"), "64")
+		symlink("../../../bus/pci/drivers/ice", filepath.Join(pciDevice, "driver"))
+		symlink("../../../bus/pci", filepath.Join(pciDevice, "subsystem"))
+
+		writeFile(filepath.Join(sysfsNet, "ens1f0", "speed"), "25000")
+		writeFile(filepath.Join(sysfsNet, "ens1f0", "duplex"), "full")
+		symlink(pciDevice, filepath.Join(sysfsNet, "ens1f0", "device"))
+
+		noNUMADevice := filepath.Join(sysfs, "devices", "pci0000:00", "0000:00:03.0")
+		writeFile(filepath.Join(noNUMADevice, "numa_node"), "-1")
+		symlink("../../../bus/pci", filepath.Join(noNUMADevice, "subsystem"))
+
+		// Reading the speed of a link down fails with EINVAL, an empty
+		// file fails parsing the same way
+		writeFile(filepath.Join(sysfsNet, "eth1", "speed"), "")
+		writeFile(filepath.Join(sysfsNet, "eth1", "duplex"), "unknown")
+		symlink(noNUMADevice, filepath.Join(sysfsNet, "eth1", "device"))
+
+		writeFile(filepath.Join(sysfsNet, "br1", "speed"), "")
+
+		ethtool = fakeEthtool{
+			infos: map[string]driverInfo{
+				"ens1f0": {driver: "ice", firmwareVersion: "2.50 0x800077a8 1.2960.0"},
+				"eth1":   {driver: "virtio_net"},
+			},
+			linkModes: map[string][]string{
+				"ens1f0": {"10000baseSR/Full", "25000baseSR/Full"},
+			},
+			addresses: map[string]string{
+				"ens1f0": "b4:96:91:aa:bb:cc",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(sysfs)).To(Succeed())
+	})
+
+	It("should report only the interfaces with a device", func() {
+		numaNode := 1
+		hardware, err := inventory(sysfsNet, ethtool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hardware).To(Equal([]shared.InterfaceHardware{
+			{
+				Interface:           "ens1f0",
+				PCIAddress:          "0000:3b:00.0",
+				Driver:              "ice",
+				FirmwareVersion:     "2.50 0x800077a8 1.2960.0",
+				Speed:               25000,
+				Duplex:              "full",
+				SupportedLinkModes:  []string{"10000baseSR/Full", "25000baseSR/Full"},
+				NUMANode:            &numaNode,
+				SRIOVTotalVFs:       64,
+				PermanentMACAddress: "b4:96:91:aa:bb:cc",
+			},
+			{
+				Interface:  "eth1",
+				PCIAddress: "0000:00:03.0",
+				Driver:     "virtio_net",
+			},
+		}))
+	})
+
+	Context("when there are no physical NICs", func() {
+		BeforeEach(func() {
+			Expect(os.RemoveAll(filepath.Join(sysfsNet, "ens1f0"))).To(Succeed())
+			Expect(os.RemoveAll(filepath.Join(sysfsNet, "eth1"))).To(Succeed())
+		})
+		It("should return no hardware", func() {
+			hardware, err := inventory(sysfsNet, ethtool)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hardware).To(BeNil())
+		})
+	})
+})
+
+var _ = Describe("linkModeNames", func() {
+	It("should return the link modes at the mask words", func() {
+		Expect(linkModeNames([]uint32{1<<5 | 1<<6 | 1<<12, 1 << 1, 0})).To(Equal([]string{
+			"1000baseT/Full",
+			"10000baseT/Full",
+			"25000baseSR/Full",
+		}))
+	})
+	It("should return no link modes if there are no known bits", func() {
+		Expect(linkModeNames([]uint32{1 << 6, 0, 1 << 31})).To(BeNil())
+	})
+})
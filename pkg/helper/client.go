@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"reflect"
 	"time"
 
 	"github.com/pkg/errors"
@@ -16,9 +17,11 @@ import (
 
 	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/metrics"
 	"github.com/nmstate/kubernetes-nmstate/pkg/names"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 	"github.com/nmstate/kubernetes-nmstate/pkg/probe"
+	"github.com/nmstate/kubernetes-nmstate/pkg/state"
 )
 
 var (
@@ -27,8 +30,6 @@ var (
 
 const vlanFilteringCommand = "vlan-filtering"
 const defaultGwRetrieveTimeout = 120 * time.Second
-const defaultGwProbeTimeout = 120 * time.Second
-const apiServerProbeTimeout = 120 * time.Second
 
 func applyVlanFiltering(bridgeName string, ports []string) (string, error) {
 	command := []string{bridgeName}
@@ -64,7 +65,7 @@ func InitializeNodeNetworkState(client client.Client, node *corev1.Node) (*nmsta
 	return &nodeNetworkState, nil
 }
 
-func CreateOrUpdateNodeNetworkState(client client.Client, node *corev1.Node, namespace client.ObjectKey, observedState shared.State) error {
+func CreateOrUpdateNodeNetworkState(client client.Client, node *corev1.Node, namespace client.ObjectKey, observedState shared.State, lldpNeighbors []shared.LLDPNeighbor, hardware []shared.InterfaceHardware) error {
 	nnsInstance := &nmstatev1beta1.NodeNetworkState{}
 	err := client.Get(context.TODO(), namespace, nnsInstance)
 	if err != nil {
@@ -77,17 +78,21 @@ func CreateOrUpdateNodeNetworkState(client client.Client, node *corev1.Node, nam
 			}
 		}
 	}
-	return UpdateCurrentState(client, nnsInstance, observedState)
+	return UpdateCurrentState(client, nnsInstance, observedState, lldpNeighbors, hardware)
 }
 
-func UpdateCurrentState(client client.Client, nodeNetworkState *nmstatev1beta1.NodeNetworkState, observedState shared.State) error {
+func UpdateCurrentState(client client.Client, nodeNetworkState *nmstatev1beta1.NodeNetworkState, observedState shared.State, lldpNeighbors []shared.LLDPNeighbor, hardware []shared.InterfaceHardware) error {
 
-	if observedState.String() == nodeNetworkState.Status.CurrentState.String() {
+	if observedState.String() == nodeNetworkState.Status.CurrentState.String() &&
+		reflect.DeepEqual(lldpNeighbors, nodeNetworkState.Status.LLDPNeighbors) &&
+		reflect.DeepEqual(hardware, nodeNetworkState.Status.Hardware) {
 		log.Info("Skipping NodeNetworkState update, node network configuration not changed")
 		return nil
 	}
 
 	nodeNetworkState.Status.CurrentState = observedState
+	nodeNetworkState.Status.LLDPNeighbors = lldpNeighbors
+	nodeNetworkState.Status.Hardware = hardware
 	nodeNetworkState.Status.LastSuccessfulUpdateTime = metav1.Time{Time: time.Now()}
 
 	err := client.Status().Update(context.Background(), nodeNetworkState)
@@ -102,37 +107,100 @@ func UpdateCurrentState(client client.Client, nodeNetworkState *nmstatev1beta1.N
 	return nil
 }
 
-func rollback(client client.Client, probes []probe.Probe, cause error) error {
+func rollback(client client.Client, nmstate nmstatectl.Backend, probes []probe.Probe, cause error) error {
 	message := fmt.Sprintf("rolling back desired state configuration: %s", cause)
-	err := nmstatectl.Rollback()
+	metrics.Rollbacks.WithLabelValues(probe.FailedName(cause)).Inc()
+	err := nmstate.Rollback(context.TODO())
 	if err != nil {
-		return errors.Wrap(err, message)
+		return nmstatectl.NewRollbackFailedError(errors.Wrap(err, message))
 	}
 
 	// wait for system to settle after rollback
-	probesErr := probe.Run(client, probes)
+	probesErr := probe.Run(client, nmstate, probes)
 	if probesErr != nil {
-		return errors.Wrap(errors.Wrap(err, "failed running probes after rollback"), message)
+		return nmstatectl.NewRollbackFailedError(errors.Wrap(errors.Wrap(probesErr, "failed running probes after rollback"), message))
 	}
-	return errors.New(message)
+	return nmstatectl.NewRollbackError(errors.Wrap(cause, "rolling back desired state configuration"))
 }
 
-func ApplyDesiredState(client client.Client, desiredState shared.State) (string, error) {
+// ApplyDesiredState applies the desired state with nmstate and commits it
+// if the probes pass, otherwise the previous configuration is rolled back.
+func ApplyDesiredState(client client.Client, nmstate nmstatectl.Backend, desiredState shared.State, timeouts probe.Timeouts, userProbes []probe.Probe) (string, error) {
 	if len(string(desiredState.Raw)) == 0 {
 		return "Ignoring empty desired state", nil
 	}
 
+	commandOutput, _, err := setDesiredState(client, nmstate, desiredState, timeouts, userProbes)
+	if err != nil {
+		return commandOutput, err
+	}
+
+	commitStart := time.Now()
+	commitOutput, err := nmstate.Commit(context.TODO())
+	metrics.ObserveApplyDuration(metrics.PhaseCommit, commitStart)
+	if err != nil {
+		// We cannot rollback if commit fails, just return the error
+		return commitOutput, err
+	}
+
+	return commandOutput, nil
+}
+
+// DryRunDesiredState applies the desired state without committing it, runs
+// the probes and always rolls back to the previous configuration. It
+// returns the diff between the node current state and the state the node
+// had with the desired state applied.
+func DryRunDesiredState(client client.Client, nmstate nmstatectl.Backend, desiredState shared.State, timeouts probe.Timeouts, userProbes []probe.Probe) (string, error) {
+	if len(string(desiredState.Raw)) == 0 {
+		return "", nil
+	}
+
+	currentState, err := showState(nmstate)
+	if err != nil {
+		return "", errors.Wrap(err, "failed retrieving current state")
+	}
+
+	commandOutput, probes, err := setDesiredState(client, nmstate, desiredState, timeouts, userProbes)
+	if err != nil {
+		return "", errors.Wrapf(err, "dry run failed: %s", commandOutput)
+	}
+
+	wouldBeState, err := showState(nmstate)
+	if err != nil {
+		return "", rollback(client, nmstate, probes, errors.Wrap(err, "failed retrieving dry run state"))
+	}
+
+	err = nmstate.Rollback(context.TODO())
+	if err != nil {
+		return "", errors.Wrap(err, "failed rolling back dry run")
+	}
+
+	// wait for system to settle after rollback
+	err = probe.Run(client, nmstate, probes)
+	if err != nil {
+		return "", errors.Wrap(err, "failed running probes after dry run rollback")
+	}
+
+	return stateDiff(currentState, wouldBeState)
+}
+
+// setDesiredState applies the desired state without committing it and checks
+// that the probes are still working, if something fails the previous
+// configuration is rolled back. It returns the probes selected before
+// applying so the caller can commit or rollback, the user probes are not
+// returned since they usually depend on the desired state.
+func setDesiredState(client client.Client, nmstate nmstatectl.Backend, desiredState shared.State, timeouts probe.Timeouts, userProbes []probe.Probe) (string, []probe.Probe, error) {
 	// Before apply we get the probes that are working fine, they should be
 	// working fine after apply
-	probes := probe.Select(client)
+	probes := probe.Select(client, nmstate, timeouts)
 
-	// commit timeout doubles the default gw ping probe and check API server
-	// connectivity timeout, to
-	// ensure the Checkpoint is alive before rolling it back
-	// https://nmstate.github.io/cli_guide#manual-transaction-control
-	setOutput, err := nmstatectl.Set(desiredState, (defaultGwProbeTimeout+apiServerProbeTimeout)*2)
+	// The checkpoint has to be alive until the probes finish, see
+	// probe.Timeouts
+	setStart := time.Now()
+	setOutput, err := nmstate.Set(context.TODO(), desiredState, timeouts.WithUserProbes(userProbes).Checkpoint)
+	metrics.ObserveApplyDuration(metrics.PhaseSet, setStart)
 	if err != nil {
-		return setOutput, err
+		return setOutput, probes, err
 	}
 
 	// Future versions of nmstate/NM will support vlan-filtering meanwhile
@@ -141,7 +209,7 @@ func ApplyDesiredState(client client.Client, desiredState shared.State) (string,
 	// set
 	bridgesUpWithPorts, err := getBridgesUp(desiredState)
 	if err != nil {
-		return "", rollback(client, probes, fmt.Errorf("error retrieving up bridges from desired state"))
+		return "", probes, rollback(client, nmstate, probes, fmt.Errorf("error retrieving up bridges from desired state"))
 	}
 
 	commandOutput := ""
@@ -149,21 +217,30 @@ func ApplyDesiredState(client client.Client, desiredState shared.State) (string,
 		outputVlanFiltering, err := applyVlanFiltering(bridge, ports)
 		commandOutput += fmt.Sprintf("bridge %s ports %v applyVlanFiltering command output: %s\n", bridge, ports, outputVlanFiltering)
 		if err != nil {
-			return commandOutput, rollback(client, probes, err)
+			return commandOutput, probes, rollback(client, nmstate, probes, err)
 		}
 	}
 
-	err = probe.Run(client, probes)
+	probesStart := time.Now()
+	err = probe.Run(client, nmstate, probes)
 	if err != nil {
-		return "", rollback(client, probes, errors.Wrap(err, "failed runnig probes after network changes"))
+		return "", probes, rollback(client, nmstate, probes, errors.Wrap(err, "failed runnig probes after network changes"))
 	}
 
-	commitOutput, err := nmstatectl.Commit()
+	err = probe.Run(client, nmstate, userProbes)
+	metrics.ObserveApplyDuration(metrics.PhaseProbes, probesStart)
 	if err != nil {
-		// We cannot rollback if commit fails, just return the error
-		return commitOutput, err
+		return "", probes, rollback(client, nmstate, probes, errors.Wrap(err, "failed runnig user probes after network changes"))
 	}
 
 	commandOutput += fmt.Sprintf("setOutput: %s \n", setOutput)
-	return commandOutput, nil
+	return commandOutput, probes, nil
+}
+
+func showState(nmstate nmstatectl.Backend) (shared.State, error) {
+	currentState, err := nmstate.Show(context.TODO())
+	if err != nil {
+		return shared.State{}, err
+	}
+	return state.FilterOut(shared.NewState(currentState))
 }
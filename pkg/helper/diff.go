@@ -0,0 +1,25 @@
+package helper
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// stateDiff returns an unified diff from the node current state to the
+// state the node would have with the desired state applied.
+func stateDiff(currentState, wouldBeState shared.State) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(currentState),
+		B:        splitLines(wouldBeState),
+		FromFile: "currentState",
+		ToFile:   "desiredState",
+		Context:  3,
+	})
+}
+
+func splitLines(s shared.State) []string {
+	return difflib.SplitLines(strings.TrimSuffix(s.String(), "\n"))
+}
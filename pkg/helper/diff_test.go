@@ -0,0 +1,45 @@
+package helper
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("stateDiff", func() {
+	var (
+		currentState = nmstate.NewState(`interfaces:
+- name: eth1
+  state: down
+  type: ethernet
+`)
+		wouldBeState = nmstate.NewState(`interfaces:
+- name: eth1
+  state: up
+  type: ethernet
+`)
+	)
+	Context("when the state does not change", func() {
+		It("should return an empty diff", func() {
+			diff, err := stateDiff(currentState, currentState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff).To(BeEmpty())
+		})
+	})
+	Context("when the state changes", func() {
+		It("should return the changed lines", func() {
+			diff, err := stateDiff(currentState, wouldBeState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff).To(Equal(`--- currentState
++++ desiredState
+@@ -1,4 +1,4 @@
+ interfaces:
+ - name: eth1
+-  state: down
++  state: up
+   type: ethernet
+`))
+		})
+	})
+})
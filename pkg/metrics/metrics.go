@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "kubernetes_nmstate"
+
+// Desired state apply phases measured by ApplyDuration
+const (
+	PhaseSet    = "set"
+	PhaseProbes = "probes"
+	PhaseCommit = "commit"
+)
+
+var (
+	// EnactmentOutcomes counts the enactments finished at this node by
+	// condition reason
+	EnactmentOutcomes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "enactment_outcomes_total",
+			Help:      "Number of finished enactments by condition reason",
+		},
+		[]string{"reason"},
+	)
+
+	// ApplyDuration measures the time spent at the different phases of
+	// applying a desired state
+	ApplyDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "apply_duration_seconds",
+			Help:      "Duration of the desired state apply phases: set, probes and commit",
+			Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 240, 480},
+		},
+		[]string{"phase"},
+	)
+
+	// Rollbacks counts the desired state rollbacks by the probe that has
+	// failed, probe is empty if the rollback was not caused by a probe
+	Rollbacks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rollbacks_total",
+			Help:      "Number of desired state rollbacks by failed probe",
+		},
+		[]string{"probe"},
+	)
+
+	// NmstatectlDuration measures nmstatectl execution latency by command
+	NmstatectlDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "nmstatectl_duration_seconds",
+			Help:      "Duration of nmstatectl executions by command",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 240},
+		},
+		[]string{"command"},
+	)
+
+	// NmstatectlErrors counts the failed nmstatectl executions by command
+	NmstatectlErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "nmstatectl_errors_total",
+			Help:      "Number of failed nmstatectl executions by command",
+		},
+		[]string{"command"},
+	)
+
+	// NodeNetworkStateRefreshes counts the NodeNetworkState refreshes by
+	// whether the network state had changed and the NNS was updated
+	NodeNetworkStateRefreshes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "nodenetworkstate_refreshes_total",
+			Help:      "Number of NodeNetworkState refreshes by whether it was updated",
+		},
+		[]string{"updated"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		EnactmentOutcomes,
+		ApplyDuration,
+		Rollbacks,
+		NmstatectlDuration,
+		NmstatectlErrors,
+		NodeNetworkStateRefreshes,
+	)
+}
+
+// ObserveApplyDuration records the duration of a desired state apply phase
+// that started at start
+func ObserveApplyDuration(phase string, start time.Time) {
+	ApplyDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,16 @@
+package metrics
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.metrics-metrics_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Metrics Test Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+var (
+	log = logf.Log.WithName("metrics")
+
+	unavailableNodeCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "policy", "unavailable_node_count"),
+		"Number of nodes applying the policy at the moment",
+		[]string{"policy"}, nil,
+	)
+)
+
+// PolicyCollector exports the status of the policies, it reads them at
+// scrape time so there are no stale series from deleted policies. It is a
+// leader elected runnable so only one replica exports the series.
+type PolicyCollector struct {
+	client client.Client
+}
+
+func NewPolicyCollector(client client.Client) *PolicyCollector {
+	return &PolicyCollector{client: client}
+}
+
+// NeedLeaderElection is true since every replica would export the same
+// series
+func (c *PolicyCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start registers the collector at the controller-runtime metrics registry
+// until the context is done
+func (c *PolicyCollector) Start(ctx context.Context) error {
+	err := ctrlmetrics.Registry.Register(c)
+	if err != nil {
+		return errors.Wrap(err, "failed registering policy metrics collector")
+	}
+	<-ctx.Done()
+	ctrlmetrics.Registry.Unregister(c)
+	return nil
+}
+
+func (c *PolicyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- unavailableNodeCountDesc
+}
+
+func (c *PolicyCollector) Collect(ch chan<- prometheus.Metric) {
+	policies := nmstatev1beta1.NodeNetworkConfigurationPolicyList{}
+	err := c.client.List(context.TODO(), &policies)
+	if err != nil {
+		log.Error(err, "failed listing policies to collect metrics")
+		return
+	}
+	for _, policy := range policies.Items {
+		ch <- prometheus.MustNewConstMetric(unavailableNodeCountDesc, prometheus.GaugeValue, float64(policy.Status.UnavailableNodeCount), policy.Name)
+	}
+}
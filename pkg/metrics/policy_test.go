@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+// unavailableNodeCountSeries returns the number of exported unavailable node
+// count series
+func unavailableNodeCountSeries() (int, error) {
+	families, err := ctrlmetrics.Registry.Gather()
+	if err != nil {
+		return 0, err
+	}
+	for _, family := range families {
+		if family.GetName() == "kubernetes_nmstate_policy_unavailable_node_count" {
+			return len(family.GetMetric()), nil
+		}
+	}
+	return 0, nil
+}
+
+var _ = Describe("Policy collector", func() {
+	var collector *PolicyCollector
+
+	BeforeEach(func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationPolicy{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyList{},
+		)
+		policy := nmstatev1beta1.NodeNetworkConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy1"},
+		}
+		policy.Status.UnavailableNodeCount = 2
+		collector = NewPolicyCollector(fake.NewFakeClientWithScheme(s, &policy))
+	})
+
+	It("should need leader election", func() {
+		Expect(collector.NeedLeaderElection()).To(BeTrue())
+	})
+
+	It("should export the policies status only while started", func() {
+		Expect(unavailableNodeCountSeries()).To(Equal(0))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- collector.Start(ctx)
+		}()
+		Eventually(unavailableNodeCountSeries).Should(Equal(1))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(unavailableNodeCountSeries()).To(Equal(0))
+	})
+})
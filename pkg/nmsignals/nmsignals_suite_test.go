@@ -0,0 +1,16 @@
+package nmsignals
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.nmsignals-nmsignals_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "NetworkManager Signals Test Suite", []Reporter{junitReporter})
+}
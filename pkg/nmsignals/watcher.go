@@ -0,0 +1,132 @@
+package nmsignals
+
+import (
+	"context"
+	"time"
+
+	"github.com/godbus/dbus"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	log = logf.Log.WithName("nmsignals")
+
+	// matchedSignals are the NetworkManager signals emitted when devices,
+	// connections or their activation change
+	matchedSignals = []struct {
+		iface  string
+		member string
+	}{
+		{"org.freedesktop.NetworkManager", "DeviceAdded"},
+		{"org.freedesktop.NetworkManager", "DeviceRemoved"},
+		{"org.freedesktop.NetworkManager.Device", "StateChanged"},
+		{"org.freedesktop.NetworkManager.Connection.Active", "StateChanged"},
+		{"org.freedesktop.NetworkManager.Settings", "NewConnection"},
+		{"org.freedesktop.NetworkManager.Settings", "ConnectionRemoved"},
+		{"org.freedesktop.NetworkManager.Settings.Connection", "Updated"},
+	}
+)
+
+const (
+	signalsBufferSize = 100
+	reconnectPeriod   = 5 * time.Second
+)
+
+// Watcher calls Notify when NetworkManager devices or connections change,
+// the signals received during Debounce after the first one are notified
+// once.
+type Watcher struct {
+	Debounce time.Duration
+	Notify   func()
+}
+
+// NeedLeaderElection is false since every handler watches its own node
+func (w Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start watches the NetworkManager signals until ctx is done, it connects
+// again to D-Bus if the connection is lost.
+func (w Watcher) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		err := w.watch(ctx)
+		if err != nil {
+			log.Error(err, "failed watching NetworkManager signals, connecting again", "after", reconnectPeriod)
+		}
+	}, reconnectPeriod)
+	return nil
+}
+
+// Connect checks that the D-Bus system bus can be reached
+func Connect() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func connect() (*dbus.Conn, error) {
+	conn, err := dbus.SystemBusPrivate()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed connecting to D-Bus system bus")
+	}
+	if err := conn.Auth(nil); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "failed authenticating at D-Bus system bus")
+	}
+	if err := conn.Hello(); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "failed saying hello to D-Bus system bus")
+	}
+	return conn, nil
+}
+
+func (w Watcher) watch(ctx context.Context) error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, signal := range matchedSignals {
+		rule := "type='signal',sender='org.freedesktop.NetworkManager',interface='" + signal.iface + "',member='" + signal.member + "'"
+		err = conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule).Err
+		if err != nil {
+			return errors.Wrapf(err, "failed subscribing to %s.%s", signal.iface, signal.member)
+		}
+	}
+
+	signals := make(chan *dbus.Signal, signalsBufferSize)
+	conn.Signal(signals)
+	log.Info("watching NetworkManager signals")
+
+	// Notify the changes that may have been missed while disconnected
+	w.Notify()
+
+	return debounce(ctx, signals, w.Debounce, w.Notify)
+}
+
+// debounce calls notify once after d for the signals received since the
+// first one not notified yet, it returns an error if signals is closed.
+func debounce(ctx context.Context, signals <-chan *dbus.Signal, d time.Duration, notify func()) error {
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-signals:
+			if !ok {
+				return errors.New("D-Bus connection closed")
+			}
+			if timer == nil {
+				timer = time.After(d)
+			}
+		case <-timer:
+			timer = nil
+			notify()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package nmsignals
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/godbus/dbus"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NetworkManager signals debounce", func() {
+	var (
+		signals       chan *dbus.Signal
+		notifications int32
+		ctx           context.Context
+		cancel        context.CancelFunc
+		done          chan error
+	)
+	const debouncePeriod = 100 * time.Millisecond
+
+	BeforeEach(func() {
+		signals = make(chan *dbus.Signal, 10)
+		atomic.StoreInt32(&notifications, 0)
+		ctx, cancel = context.WithCancel(context.Background())
+		done = make(chan error, 1)
+		go func() {
+			done <- debounce(ctx, signals, debouncePeriod, func() { atomic.AddInt32(&notifications, 1) })
+		}()
+	})
+	AfterEach(func() {
+		cancel()
+	})
+
+	notified := func() int32 {
+		return atomic.LoadInt32(&notifications)
+	}
+
+	It("should notify once the signals received during the debounce period", func() {
+		for i := 0; i < 5; i++ {
+			signals <- &dbus.Signal{Name: "org.freedesktop.NetworkManager.Device.StateChanged"}
+		}
+		Eventually(notified).Should(Equal(int32(1)))
+		Consistently(notified, 3*debouncePeriod).Should(Equal(int32(1)))
+	})
+	It("should notify again the signals received after the debounce period", func() {
+		signals <- &dbus.Signal{}
+		Eventually(notified).Should(Equal(int32(1)))
+		signals <- &dbus.Signal{}
+		Eventually(notified).Should(Equal(int32(2)))
+	})
+	It("should not notify without signals", func() {
+		Consistently(notified, 3*debouncePeriod).Should(BeZero())
+	})
+	It("should fail when the signals channel is closed", func() {
+		close(signals)
+		Eventually(done).Should(Receive(HaveOccurred()))
+	})
+	It("should stop when the context is done", func() {
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
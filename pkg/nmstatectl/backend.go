@@ -0,0 +1,67 @@
+package nmstatectl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+const (
+	// BackendExec runs the nmstatectl binary
+	BackendExec = "exec"
+	// BackendVarlink calls the nmstate varlink service
+	BackendVarlink = "varlink"
+)
+
+// Operations of the Backend, they match the nmstatectl commands
+const (
+	OperationShow     = "show"
+	OperationSet      = "set"
+	OperationCommit   = "commit"
+	OperationRollback = "rollback"
+)
+
+// Backend reports and configures the node network with nmstate, Set
+// applies the desired state under a checkpoint that is rolled back after
+// timeout unless Commit is called before.
+type Backend interface {
+	Show(ctx context.Context) (string, error)
+	Set(ctx context.Context, desiredState nmstate.State, timeout time.Duration) (string, error)
+	Commit(ctx context.Context) (string, error)
+	Rollback(ctx context.Context) error
+}
+
+// NewBackend returns the backend with the given name, varlinkAddress is
+// only used by the varlink one and the default address is used if it is
+// empty
+func NewBackend(name string, varlinkAddress string) (Backend, error) {
+	switch name {
+	case "", BackendExec:
+		return Exec{}, nil
+	case BackendVarlink:
+		if varlinkAddress == "" {
+			varlinkAddress = DefaultVarlinkAddress
+		}
+		return Varlink{Address: varlinkAddress}, nil
+	}
+	return nil, fmt.Errorf("unknown nmstate backend %q, it has to be %s or %s", name, BackendExec, BackendVarlink)
+}
+
+// Error is a failed nmstate operation
+type Error struct {
+	// Operation is the failed Backend operation, like OperationSet
+	Operation string
+	// Class is the nmstate exception, like NmstateValueError, it is empty
+	// if nmstate did not report one
+	Class string
+	// Message is the nmstate exception message
+	Message string
+	// Details is the whole failure, including the nmstate output
+	Details string
+}
+
+func (e *Error) Error() string {
+	return e.Details
+}
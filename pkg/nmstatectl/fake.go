@@ -0,0 +1,80 @@
+package nmstatectl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// Fake is an in-memory backend for tests, Set replaces the current state
+// with the desired state as is and Rollback restores the state from before
+// Set.
+type Fake struct {
+	// CurrentState is returned by Show
+	CurrentState string
+	// ShowErr, SetErr, CommitErr and RollbackErr are returned by the
+	// operations if they are set, nothing is changed then
+	ShowErr     error
+	SetErr      error
+	CommitErr   error
+	RollbackErr error
+	// Applied are the desired states passed to Set
+	Applied []nmstate.State
+	// Commits and Rollbacks count the calls
+	Commits   int
+	Rollbacks int
+
+	mutex      sync.Mutex
+	checkpoint *string
+}
+
+func (f *Fake) Show(ctx context.Context) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.ShowErr != nil {
+		return "", f.ShowErr
+	}
+	return f.CurrentState, nil
+}
+
+func (f *Fake) Set(ctx context.Context, desiredState nmstate.State, timeout time.Duration) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.SetErr != nil {
+		return "", f.SetErr
+	}
+	f.Applied = append(f.Applied, desiredState)
+	if f.checkpoint == nil {
+		checkpoint := f.CurrentState
+		f.checkpoint = &checkpoint
+	}
+	f.CurrentState = string(desiredState.Raw)
+	return "", nil
+}
+
+func (f *Fake) Commit(ctx context.Context) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.CommitErr != nil {
+		return "", f.CommitErr
+	}
+	f.Commits++
+	f.checkpoint = nil
+	return "", nil
+}
+
+func (f *Fake) Rollback(ctx context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.RollbackErr != nil {
+		return f.RollbackErr
+	}
+	f.Rollbacks++
+	if f.checkpoint != nil {
+		f.CurrentState = *f.checkpoint
+		f.checkpoint = nil
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package nmstatectl
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("Fake backend", func() {
+	var fake *Fake
+	BeforeEach(func() {
+		fake = &Fake{CurrentState: "interfaces: []\n"}
+		_, err := fake.Set(context.Background(), nmstate.NewState("interfaces:\n- name: eth1\n"), time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("should show the desired state after set", func() {
+		Expect(fake.Show(context.Background())).To(Equal("interfaces:\n- name: eth1\n"))
+		Expect(fake.Applied).To(Equal([]nmstate.State{nmstate.NewState("interfaces:\n- name: eth1\n")}))
+	})
+	It("should restore the previous state at rollback", func() {
+		Expect(fake.Rollback(context.Background())).To(Succeed())
+		Expect(fake.Show(context.Background())).To(Equal("interfaces: []\n"))
+	})
+	It("should keep the desired state at rollback after commit", func() {
+		_, err := fake.Commit(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fake.Rollback(context.Background())).To(Succeed())
+		Expect(fake.Show(context.Background())).To(Equal("interfaces:\n- name: eth1\n"))
+	})
+})
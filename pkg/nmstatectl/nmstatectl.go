@@ -2,28 +2,43 @@ package nmstatectl
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/metrics"
 )
 
 var (
 	log = logf.Log.WithName("nmstatectl")
 )
 
-const nmstateCommand = "nmstatectl"
+const (
+	nmstateCommand      = "nmstatectl"
+	shortErrorMaxLength = 256
+)
+
+// nmstateErrorLine matches the exception raised by nmstate, like
+// "libnmstate.error.NmstateValueError: Interface eth1 not found"
+var nmstateErrorLine = regexp.MustCompile(`(?m)(?:^|[\s'])(?:\w+\.)*(\w*Error)\s*:[ \t]*(.*?)'*\s*$`)
 
-func nmstatectlWithInput(arguments []string, input string) (string, error) {
-	cmd := exec.Command(nmstateCommand, arguments...)
+// Exec is the backend running the nmstatectl binary, the failures are
+// parsed from its output
+type Exec struct{}
+
+func nmstatectlWithInput(ctx context.Context, arguments []string, input string) (string, error) {
+	cmd := exec.CommandContext(ctx, nmstateCommand, arguments...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stderr = &stderr
 	cmd.Stdout = &stdout
@@ -41,38 +56,91 @@ func nmstatectlWithInput(arguments []string, input string) (string, error) {
 		}()
 
 	}
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to execute %s %s: '%v' '%s' '%s'", nmstateCommand, strings.Join(arguments, " "), err, stdout.String(), stderr.String())
+	command := ""
+	if len(arguments) > 0 {
+		command = arguments[0]
+	}
+	start := time.Now()
+	err := cmd.Run()
+	metrics.NmstatectlDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.NmstatectlErrors.WithLabelValues(command).Inc()
+		nmstateErr := &Error{
+			Operation: command,
+			Details:   fmt.Sprintf("failed to execute %s %s: '%v' '%s' '%s'", nmstateCommand, strings.Join(arguments, " "), err, stdout.String(), stderr.String()),
+		}
+		nmstateErr.Class, nmstateErr.Message = lastNmstateError(stderr.String())
+		return "", nmstateErr
 	}
 	return stdout.String(), nil
 
 }
 
-func nmstatectl(arguments []string) (string, error) {
-	return nmstatectlWithInput(arguments, "")
+func nmstatectl(ctx context.Context, arguments []string) (string, error) {
+	return nmstatectlWithInput(ctx, arguments, "")
 }
 
-func Show() (string, error) {
-	return nmstatectl([]string{"show"})
+func (Exec) Show(ctx context.Context) (string, error) {
+	return nmstatectl(ctx, []string{"show"})
 }
 
-func Set(desiredState nmstate.State, timeout time.Duration) (string, error) {
+func (Exec) Set(ctx context.Context, desiredState nmstate.State, timeout time.Duration) (string, error) {
 	var setDoneCh = make(chan struct{})
 	go setUnavailableUp(setDoneCh)
 	defer close(setDoneCh)
 
-	setOutput, err := nmstatectlWithInput([]string{"set", "--no-commit", "--timeout", strconv.Itoa(int(timeout.Seconds()))}, string(desiredState.Raw))
+	setOutput, err := nmstatectlWithInput(ctx, []string{"set", "--no-commit", "--timeout", strconv.Itoa(int(timeout.Seconds()))}, string(desiredState.Raw))
 	return setOutput, err
 }
 
-func Commit() (string, error) {
-	return nmstatectl([]string{"commit"})
+func (Exec) Commit(ctx context.Context) (string, error) {
+	return nmstatectl(ctx, []string{"commit"})
 }
 
-func Rollback() error {
-	_, err := nmstatectl([]string{"rollback"})
+func (Exec) Rollback(ctx context.Context) error {
+	_, err := nmstatectl(ctx, []string{"rollback"})
 	if err != nil {
 		return errors.Wrapf(err, "failed calling nmstatectl rollback")
 	}
 	return nil
 }
+
+// lastNmstateError returns the class and message of the last nmstate
+// exception found at output
+func lastNmstateError(output string) (string, string) {
+	matches := nmstateErrorLine.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return "", ""
+	}
+	lastMatch := matches[len(matches)-1]
+	return lastMatch[1], lastMatch[2]
+}
+
+// ShortError returns the nmstate error reported by the backend or the last
+// one found at the err output so it can be shown without the whole
+// nmstatectl stdout and stderr, if there is none the first line of err is
+// returned. It is truncated on a rune boundary to fit events.
+func ShortError(err error) string {
+	short := ""
+	class, message := lastNmstateError(err.Error())
+	var nmstateErr *Error
+	if errors.As(err, &nmstateErr) && nmstateErr.Class != "" {
+		class, message = nmstateErr.Class, nmstateErr.Message
+	}
+	if class != "" {
+		short = class
+		if message != "" {
+			short += ": " + message
+		}
+	} else {
+		short = strings.SplitN(err.Error(), "\n", 2)[0]
+	}
+	if len(short) > shortErrorMaxLength {
+		end := shortErrorMaxLength - 3
+		for end > 0 && !utf8.RuneStart(short[end]) {
+			end--
+		}
+		short = short[:end] + "..."
+	}
+	return short
+}
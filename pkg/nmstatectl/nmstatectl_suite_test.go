@@ -0,0 +1,16 @@
+package nmstatectl
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.nmstatectl-nmstatectl_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Nmstatectl Test Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,65 @@
+package nmstatectl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ShortError", func() {
+	DescribeTable("summarizing nmstatectl failures",
+		func(message, expected string) {
+			Expect(ShortError(fmt.Errorf(message))).To(Equal(expected))
+		},
+		Entry("nmstate exception at stderr",
+			`error reconciling NodeNetworkConfigurationPolicy at desired state apply: , failed to execute nmstatectl set --no-commit --timeout 480: 'exit status 1' '' 'Traceback (most recent call last):
+  File "/usr/bin/nmstatectl", line 11, in <module>
+    load_entry_point('nmstate==1.0.2', 'console_scripts', 'nmstatectl')()
+libnmstate.error.NmstateValueError: Interface eth666 not found'`,
+			"NmstateValueError: Interface eth666 not found"),
+		Entry("nmstate exception without message at the same line",
+			`failed to execute nmstatectl set: 'exit status 1' '' 'libnmstate.error.NmstateVerificationError:
+desired
+=======
+---
+name: eth1'`,
+			"NmstateVerificationError"),
+		Entry("not a nmstate failure",
+			"rolling back desired state configuration: failed runnig probes after network changes\nmore details",
+			"rolling back desired state configuration: failed runnig probes after network changes"),
+		Entry("long message",
+			"NmstateValueError: "+strings.Repeat("a", 300),
+			"NmstateValueError: "+strings.Repeat("a", 256-len("NmstateValueError: ")-3)+"..."),
+		Entry("long non-ASCII message",
+			"NmstateValueError: invalid: "+strings.Repeat("é", 300),
+			"NmstateValueError: invalid: "+strings.Repeat("é", 112)+"..."),
+	)
+	It("should use the class and message reported by the backend", func() {
+		err := &Error{
+			Operation: OperationSet,
+			Class:     "InvalidArgument",
+			Message:   "Interface eth666 not found",
+			Details:   "failed to call io.nmstate.Apply: InvalidArgument: Interface eth666 not found",
+		}
+		Expect(ShortError(errors.Wrap(err, "error reconciling NodeNetworkConfigurationPolicy"))).To(Equal("InvalidArgument: Interface eth666 not found"))
+	})
+})
+
+var _ = Describe("Rollback errors", func() {
+	It("should tell rolled back desired states from failed rollbacks", func() {
+		cause := errors.New("failed runnig probes after network changes")
+		rolledBack := errors.Wrap(NewRollbackError(cause), "error reconciling NodeNetworkConfigurationPolicy")
+		Expect(IsRollback(rolledBack)).To(BeTrue())
+		Expect(IsRollbackFailure(rolledBack)).To(BeFalse())
+		Expect(errors.Is(rolledBack, cause)).To(BeTrue())
+
+		rollbackFailed := errors.Wrap(NewRollbackFailedError(cause), "error reconciling NodeNetworkConfigurationPolicy")
+		Expect(IsRollback(rollbackFailed)).To(BeFalse())
+		Expect(IsRollbackFailure(rollbackFailed)).To(BeTrue())
+	})
+})
@@ -0,0 +1,55 @@
+package nmstatectl
+
+import (
+	"github.com/pkg/errors"
+)
+
+// RollbackError is returned when the desired state has been rolled back
+type RollbackError struct {
+	err error
+}
+
+// NewRollbackError wraps the cause of rolling back the desired state
+func NewRollbackError(cause error) *RollbackError {
+	return &RollbackError{err: cause}
+}
+
+func (e *RollbackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *RollbackError) Unwrap() error {
+	return e.err
+}
+
+// IsRollback returns true if the desired state has been rolled back
+func IsRollback(err error) bool {
+	var rollbackErr *RollbackError
+	return errors.As(err, &rollbackErr)
+}
+
+// RollbackFailedError is returned when the desired state could not be
+// rolled back or the probes still fail after rolling it back, so the node
+// may need manual intervention
+type RollbackFailedError struct {
+	err error
+}
+
+// NewRollbackFailedError wraps the failure rolling back the desired state
+func NewRollbackFailedError(err error) *RollbackFailedError {
+	return &RollbackFailedError{err: err}
+}
+
+func (e *RollbackFailedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *RollbackFailedError) Unwrap() error {
+	return e.err
+}
+
+// IsRollbackFailure returns true if rolling back the desired state failed
+func IsRollbackFailure(err error) bool {
+	var rollbackFailedErr *RollbackFailedError
+	return errors.As(err, &rollbackFailedErr)
+}
@@ -0,0 +1,209 @@
+package nmstatectl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	yaml "sigs.k8s.io/yaml"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	"github.com/nmstate/kubernetes-nmstate/pkg/metrics"
+)
+
+const (
+	// DefaultVarlinkAddress is where nmstate varlink service listens by
+	// default
+	DefaultVarlinkAddress = "unix:/run/nmstate/nmstate.so"
+	varlinkInterface      = "io.nmstate"
+)
+
+// Varlink is the backend calling the nmstate varlink service [1], the
+// failures are the nmstate exceptions returned as varlink errors.
+//
+// [1] https://varlink.org/Method-Call
+type Varlink struct {
+	// Address is unix:<socket path>, abstract sockets start with @
+	Address string
+}
+
+type varlinkCall struct {
+	Method     string      `json:"method"`
+	Parameters interface{} `json:"parameters,omitempty"`
+}
+
+type varlinkReply struct {
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+type varlinkErrorParameters struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+type varlinkShowArguments struct {
+	IncludeStatusData bool `json:"include_status_data"`
+}
+
+type varlinkApplyArguments struct {
+	DesiredState    json.RawMessage `json:"desired_state"`
+	VerifyChange    bool            `json:"verify_change"`
+	Commit          bool            `json:"commit"`
+	RollbackTimeout int             `json:"rollback_timeout"`
+}
+
+type varlinkArguments struct {
+	Arguments interface{} `json:"arguments"`
+}
+
+func (v Varlink) Show(ctx context.Context) (string, error) {
+	reply := struct {
+		State json.RawMessage `json:"state"`
+	}{}
+	err := v.call(ctx, OperationShow, "Show", varlinkArguments{varlinkShowArguments{}}, &reply)
+	if err != nil {
+		return "", err
+	}
+	state, err := yaml.JSONToYAML(reply.State)
+	if err != nil {
+		return "", errors.Wrap(err, "failed converting varlink state to YAML")
+	}
+	return string(state), nil
+}
+
+func (v Varlink) Set(ctx context.Context, desiredState nmstate.State, timeout time.Duration) (string, error) {
+	var setDoneCh = make(chan struct{})
+	go setUnavailableUp(setDoneCh)
+	defer close(setDoneCh)
+
+	desiredStateJSON, err := yaml.YAMLToJSON(desiredState.Raw)
+	if err != nil {
+		return "", errors.Wrap(err, "failed converting desired state to JSON")
+	}
+	arguments := varlinkApplyArguments{
+		DesiredState:    desiredStateJSON,
+		VerifyChange:    true,
+		Commit:          false,
+		RollbackTimeout: int(timeout.Seconds()),
+	}
+	return "", v.call(ctx, OperationSet, "Apply", varlinkArguments{arguments}, nil)
+}
+
+func (v Varlink) Commit(ctx context.Context) (string, error) {
+	return "", v.call(ctx, OperationCommit, "Commit", nil, nil)
+}
+
+func (v Varlink) Rollback(ctx context.Context) error {
+	return v.call(ctx, OperationRollback, "Rollback", nil, nil)
+}
+
+// call calls the method of the nmstate interface and decodes the reply
+// parameters into reply if it is not nil, the connection is closed if ctx
+// is done before the reply arrives
+func (v Varlink) call(ctx context.Context, operation string, method string, parameters interface{}, reply interface{}) error {
+	start := time.Now()
+	err := v.callWithoutMetrics(ctx, method, parameters, reply)
+	metrics.NmstatectlDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.NmstatectlErrors.WithLabelValues(operation).Inc()
+		var nmstateErr *Error
+		if errors.As(err, &nmstateErr) {
+			nmstateErr.Operation = operation
+			return nmstateErr
+		}
+		return &Error{
+			Operation: operation,
+			Details:   fmt.Sprintf("failed to call %s.%s: %v", varlinkInterface, method, err),
+		}
+	}
+	return nil
+}
+
+func (v Varlink) callWithoutMetrics(ctx context.Context, method string, parameters interface{}, reply interface{}) error {
+	network, address, err := varlinkNetworkAddress(v.Address)
+	if err != nil {
+		return err
+	}
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	call, err := json.Marshal(varlinkCall{Method: varlinkInterface + "." + method, Parameters: parameters})
+	if err != nil {
+		return err
+	}
+	// Varlink messages are terminated by a NUL byte
+	_, err = conn.Write(append(call, 0))
+	if err != nil {
+		return contextError(ctx, err)
+	}
+
+	message, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+	varlinkReply := varlinkReply{}
+	err = json.Unmarshal(message[:len(message)-1], &varlinkReply)
+	if err != nil {
+		return errors.Wrap(err, "failed decoding varlink reply")
+	}
+	if varlinkReply.Error != "" {
+		return varlinkError(method, varlinkReply)
+	}
+	if reply != nil {
+		err = json.Unmarshal(varlinkReply.Parameters, reply)
+		if err != nil {
+			return errors.Wrap(err, "failed decoding varlink reply parameters")
+		}
+	}
+	return nil
+}
+
+// varlinkError returns the nmstate exception from the varlink error, the
+// nmstate errors are named after its exception like
+// io.nmstate.error.NmstateValueError
+func varlinkError(method string, reply varlinkReply) *Error {
+	parameters := varlinkErrorParameters{}
+	_ = json.Unmarshal(reply.Parameters, &parameters)
+	nameParts := strings.Split(reply.Error, ".")
+	nmstateErr := &Error{
+		Class:   nameParts[len(nameParts)-1],
+		Message: parameters.ErrorMessage,
+	}
+	nmstateErr.Details = fmt.Sprintf("failed to call %s.%s: %s: %s", varlinkInterface, method, nmstateErr.Class, nmstateErr.Message)
+	return nmstateErr
+}
+
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func varlinkNetworkAddress(address string) (string, string, error) {
+	if !strings.HasPrefix(address, "unix:") {
+		return "", "", fmt.Errorf("unsupported varlink address %q, it has to be unix:<socket path>", address)
+	}
+	// Parameters like ;mode=0666 are only meant for the service
+	path := strings.SplitN(strings.TrimPrefix(address, "unix:"), ";", 2)[0]
+	return "unix", path, nil
+}
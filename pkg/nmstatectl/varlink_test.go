@@ -0,0 +1,141 @@
+package nmstatectl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// varlinkServer replies to every call with the reply returned by handle
+// and sends the calls to calls, the calls without reply hang until stop is
+// closed
+func varlinkServer(listener net.Listener, calls chan<- map[string]interface{}, stop <-chan struct{}, handle func(method string) string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			message, err := bufio.NewReader(conn).ReadBytes(0)
+			if err != nil {
+				return
+			}
+			call := map[string]interface{}{}
+			if json.Unmarshal(message[:len(message)-1], &call) != nil {
+				return
+			}
+			calls <- call
+			reply := handle(call["method"].(string))
+			if reply == "" {
+				<-stop
+				return
+			}
+			conn.Write(append([]byte(reply), 0))
+		}(conn)
+	}
+}
+
+var _ = Describe("Varlink backend", func() {
+	var (
+		dir      string
+		listener net.Listener
+		calls    chan map[string]interface{}
+		stop     chan struct{}
+		backend  Backend
+		replies  map[string]string
+	)
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "nmstate-varlink")
+		Expect(err).ToNot(HaveOccurred())
+		socket := filepath.Join(dir, "nmstate.so")
+		listener, err = net.Listen("unix", socket)
+		Expect(err).ToNot(HaveOccurred())
+
+		calls = make(chan map[string]interface{}, 10)
+		stop = make(chan struct{})
+		replies = map[string]string{}
+		go varlinkServer(listener, calls, stop, func(method string) string {
+			return replies[method]
+		})
+
+		backend, err = NewBackend(BackendVarlink, "unix:"+socket)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	AfterEach(func() {
+		close(stop)
+		listener.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("should return the state as YAML at show", func() {
+		replies["io.nmstate.Show"] = `{"parameters":{"state":{"interfaces":[{"name":"eth1","state":"up"}]}}}`
+		state, err := backend.Show(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal("interfaces:\n- name: eth1\n  state: up\n"))
+	})
+
+	It("should apply the desired state without commit at set", func() {
+		replies["io.nmstate.Apply"] = `{}`
+		_, err := backend.Set(context.Background(), nmstate.NewState("interfaces:\n- name: eth1\n  state: up\n"), 4*time.Minute)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(<-calls).To(Equal(map[string]interface{}{
+			"method": "io.nmstate.Apply",
+			"parameters": map[string]interface{}{
+				"arguments": map[string]interface{}{
+					"desired_state": map[string]interface{}{
+						"interfaces": []interface{}{
+							map[string]interface{}{"name": "eth1", "state": "up"},
+						},
+					},
+					"verify_change":    true,
+					"commit":           false,
+					"rollback_timeout": float64(240),
+				},
+			},
+		}))
+	})
+
+	It("should return the nmstate exception as a structured error", func() {
+		replies["io.nmstate.Apply"] = `{"error":"io.nmstate.error.NmstateValueError","parameters":{"error_message":"Interface eth666 not found"}}`
+		_, err := backend.Set(context.Background(), nmstate.NewState("interfaces: []"), time.Minute)
+		Expect(err).To(MatchError("failed to call io.nmstate.Apply: NmstateValueError: Interface eth666 not found"))
+		nmstateErr, ok := err.(*Error)
+		Expect(ok).To(BeTrue())
+		Expect(nmstateErr.Operation).To(Equal("set"))
+		Expect(nmstateErr.Class).To(Equal("NmstateValueError"))
+		Expect(nmstateErr.Message).To(Equal("Interface eth666 not found"))
+		Expect(ShortError(err)).To(Equal("NmstateValueError: Interface eth666 not found"))
+	})
+
+	It("should stop waiting for the reply when the context is done", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		err := backend.Rollback(ctx)
+		Expect(err).To(MatchError(ContainSubstring(context.DeadlineExceeded.Error())))
+	})
+})
+
+var _ = Describe("NewBackend", func() {
+	It("should default to exec", func() {
+		Expect(NewBackend("", "")).To(Equal(Exec{}))
+	})
+	It("should default the varlink address", func() {
+		Expect(NewBackend(BackendVarlink, "")).To(Equal(Varlink{Address: DefaultVarlinkAddress}))
+	})
+	It("should fail with unknown backends", func() {
+		_, err := NewBackend("library", "")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -7,6 +7,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/pkg/errors"
@@ -16,9 +17,9 @@ const (
 	DEFAULT_MAXUNAVAILABLE = "50%"
 )
 
-func NodesRunningNmstate(cli client.Reader, nodeSelector map[string]string) ([]corev1.Node, error) {
+func NodesRunningNmstate(cli client.Reader, nodeSelector labels.Selector) ([]corev1.Node, error) {
 	nodes := corev1.NodeList{}
-	err := cli.List(context.TODO(), &nodes, client.MatchingLabels(nodeSelector))
+	err := cli.List(context.TODO(), &nodes, client.MatchingLabelsSelector{Selector: nodeSelector})
 	if err != nil {
 		return []corev1.Node{}, errors.Wrap(err, "getting nodes failed")
 	}
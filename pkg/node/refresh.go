@@ -9,6 +9,9 @@ import (
 const (
 	NetworkStateRefresh          = time.Minute
 	NetworkStateRefreshMaxFactor = 0.1
+	// NetworkStateSafetyRefresh is the refresh period when the network state
+	// is refreshed on NetworkManager signals, it covers changes not signaled
+	NetworkStateSafetyRefresh = 15 * time.Minute
 )
 
 // NodeNetworkStateRefreshWithJitter add some jitter to to the refresh rate so it does
@@ -16,3 +19,14 @@ const (
 func NetworkStateRefreshWithJitter() time.Duration {
 	return wait.Jitter(NetworkStateRefresh, NetworkStateRefreshMaxFactor)
 }
+
+// NetworkStateRefreshPeriodWithJitter adds jitter to a configured refresh
+// period
+func NetworkStateRefreshPeriodWithJitter(period time.Duration) time.Duration {
+	return wait.Jitter(period, NetworkStateRefreshMaxFactor)
+}
+
+// NetworkStateSafetyRefreshWithJitter adds jitter to the safety refresh period
+func NetworkStateSafetyRefreshWithJitter() time.Duration {
+	return wait.Jitter(NetworkStateSafetyRefresh, NetworkStateRefreshMaxFactor)
+}
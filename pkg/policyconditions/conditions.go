@@ -19,6 +19,7 @@ import (
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
 	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
 	"github.com/nmstate/kubernetes-nmstate/pkg/node"
+	"github.com/nmstate/kubernetes-nmstate/pkg/selectors"
 )
 
 var (
@@ -47,6 +48,22 @@ func SetPolicyProgressing(conditions *nmstate.ConditionList, message string) {
 	)
 }
 
+func SetPolicyPaused(conditions *nmstate.ConditionList, message string) {
+	log.Info("SetPolicyPaused")
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionDegraded,
+		corev1.ConditionUnknown,
+		nmstate.NodeNetworkConfigurationPolicyConditionPaused,
+		"",
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionAvailable,
+		corev1.ConditionUnknown,
+		nmstate.NodeNetworkConfigurationPolicyConditionPaused,
+		message,
+	)
+}
+
 func SetPolicySuccess(conditions *nmstate.ConditionList, message string) {
 	log.Info("SetPolicySuccess")
 	conditions.Set(
@@ -95,6 +112,38 @@ func SetPolicyFailedToConfigure(conditions *nmstate.ConditionList, message strin
 	)
 }
 
+func SetPolicyDryRunSucceeded(conditions *nmstate.ConditionList, message string) {
+	log.Info("SetPolicyDryRunSucceeded")
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionDegraded,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationPolicyConditionDryRunConfigured,
+		"",
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionAvailable,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationPolicyConditionDryRunConfigured,
+		message,
+	)
+}
+
+func SetPolicyDryRunFailed(conditions *nmstate.ConditionList, message string) {
+	log.Info("SetPolicyDryRunFailed")
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionDegraded,
+		corev1.ConditionTrue,
+		nmstate.NodeNetworkConfigurationPolicyConditionDryRunFailedToConfigure,
+		message,
+	)
+	conditions.Set(
+		nmstate.NodeNetworkConfigurationPolicyConditionAvailable,
+		corev1.ConditionFalse,
+		nmstate.NodeNetworkConfigurationPolicyConditionDryRunFailedToConfigure,
+		"",
+	)
+}
+
 func Update(cli client.Client, apiReader client.Reader, policyKey types.NamespacedName) error {
 	logger := log.WithValues("policy", policyKey.Name)
 
@@ -118,7 +167,11 @@ func Update(cli client.Client, apiReader client.Reader, policyKey types.Namespac
 		// Count only nodes that runs nmstate handler and match the policy
 		// nodeSelector, could be that users don't want to run knmstate at control-plane for example
 		// so they don't want to change net config there.
-		nmstateMatchingNodes, err := node.NodesRunningNmstate(apiReader, policy.Spec.NodeSelector)
+		nodeSelector, err := selectors.NodeSelector(*policy)
+		if err != nil {
+			return errors.Wrap(err, "getting policy node selector failed")
+		}
+		nmstateMatchingNodes, err := node.NodesRunningNmstate(apiReader, nodeSelector)
 		if err != nil {
 			return errors.Wrap(err, "getting nodes running kubernets-nmstate pods failed")
 		}
@@ -128,14 +181,27 @@ func Update(cli client.Client, apiReader client.Reader, policyKey types.Namespac
 		enactmentsCountByCondition := enactmentconditions.Count(enactments, policy.Generation)
 
 		numberOfFinishedEnactments := enactmentsCountByCondition.Available() + enactmentsCountByCondition.Failed() + enactmentsCountByCondition.Aborted()
+		if policy.Spec.DryRun {
+			numberOfFinishedEnactments = enactmentsCountByCondition.DryRunSucceeded() + enactmentsCountByCondition.DryRunFailed()
+		}
 
 		logger.Info(fmt.Sprintf("numberOfNmstateMatchingNodes: %d, enactments count: %s", numberOfNmstateMatchingNodes, enactmentsCountByCondition))
 
 		if numberOfNmstateMatchingNodes == 0 {
 			message := "Policy does not match any node"
 			SetPolicyNotMatching(&policy.Status.Conditions, message)
+		} else if numberOfFinishedEnactments < numberOfNmstateMatchingNodes && policy.Spec.Paused {
+			SetPolicyPaused(&policy.Status.Conditions, fmt.Sprintf("Policy is paused %d/%d nodes finished", numberOfFinishedEnactments, numberOfNmstateMatchingNodes))
 		} else if numberOfFinishedEnactments < numberOfNmstateMatchingNodes {
 			SetPolicyProgressing(&policy.Status.Conditions, fmt.Sprintf("Policy is progressing %d/%d nodes finished", numberOfFinishedEnactments, numberOfNmstateMatchingNodes))
+		} else if policy.Spec.DryRun {
+			if enactmentsCountByCondition.DryRunFailed() > 0 {
+				message := fmt.Sprintf("%d/%d nodes failed dry run", enactmentsCountByCondition.DryRunFailed(), numberOfNmstateMatchingNodes)
+				SetPolicyDryRunFailed(&policy.Status.Conditions, message)
+			} else {
+				message := fmt.Sprintf("%d/%d nodes succeeded dry run, nothing was committed", enactmentsCountByCondition.DryRunSucceeded(), numberOfNmstateMatchingNodes)
+				SetPolicyDryRunSucceeded(&policy.Status.Conditions, message)
+			}
 		} else {
 			if enactmentsCountByCondition.Failed() > 0 || enactmentsCountByCondition.Aborted() > 0 {
 				message := fmt.Sprintf("%d/%d nodes failed to configure", enactmentsCountByCondition.Failed(), numberOfNmstateMatchingNodes)
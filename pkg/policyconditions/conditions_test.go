@@ -57,6 +57,21 @@ func s(nodeSelector map[string]string, policy nmstatev1beta1.NodeNetworkConfigur
 	return policy
 }
 
+func ls(nodeLabelSelector *metav1.LabelSelector, policy nmstatev1beta1.NodeNetworkConfigurationPolicy) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	policy.Spec.NodeLabelSelector = nodeLabelSelector
+	return policy
+}
+
+func d(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	policy.Spec.DryRun = true
+	return policy
+}
+
+func paused(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	policy.Spec.Paused = true
+	return policy
+}
+
 func nodeName(idx int) string {
 	return fmt.Sprintf("node%d", idx)
 }
@@ -238,6 +253,55 @@ var _ = Describe("Policy Conditions", func() {
 			Pods:   newNmstatePods(3),
 			Policy: p(SetPolicyFailedToConfigure, "3/3 nodes failed to configure"),
 		}),
+		Entry("when all enactments succeeded dry run then policy succeeded dry run", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetDryRunSucceeded),
+				e("node2", "policy1", enactmentconditions.SetDryRunSucceeded),
+				e("node3", "policy1", enactmentconditions.SetDryRunSucceeded),
+			},
+			Nodes:  newNodes(3),
+			Pods:   newNmstatePods(3),
+			Policy: d(p(SetPolicyDryRunSucceeded, "3/3 nodes succeeded dry run, nothing was committed")),
+		}),
+		Entry("when some dry run enactments are progressing then policy is progressing", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetDryRunSucceeded),
+				e("node2", "policy1", enactmentconditions.SetDryRunFailed),
+				e("node3", "policy1", enactmentconditions.SetProgressing),
+			},
+			Nodes:  newNodes(3),
+			Pods:   newNmstatePods(3),
+			Policy: d(p(SetPolicyProgressing, "Policy is progressing 2/3 nodes finished")),
+		}),
+		Entry("when some enactments failed dry run then policy failed dry run", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetDryRunSucceeded),
+				e("node2", "policy1", enactmentconditions.SetDryRunFailed),
+				e("node3", "policy1", enactmentconditions.SetDryRunSucceeded),
+			},
+			Nodes:  newNodes(3),
+			Pods:   newNmstatePods(3),
+			Policy: d(p(SetPolicyDryRunFailed, "1/3 nodes failed dry run")),
+		}),
+		Entry("when policy is paused with some enactments pending then policy is paused", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetSuccess),
+				e("node2", "policy1", enactmentconditions.SetPaused),
+				e("node3", "policy1", enactmentconditions.SetPaused),
+			},
+			Nodes:  newNodes(3),
+			Pods:   newNmstatePods(3),
+			Policy: paused(p(SetPolicyPaused, "Policy is paused 1/3 nodes finished")),
+		}),
+		Entry("when policy is paused with all enactments finished then policy is success", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetSuccess),
+				e("node2", "policy1", enactmentconditions.SetSuccess),
+			},
+			Nodes:  newNodes(2),
+			Pods:   newNmstatePods(2),
+			Policy: paused(p(SetPolicySuccess, "2/2 nodes successfully configured")),
+		}),
 		Entry("when no node matches policy node selector, policy state is not matching", ConditionsCase{
 			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{},
 			Nodes:      newNodes(3),
@@ -266,6 +330,19 @@ var _ = Describe("Policy Conditions", func() {
 			Pods:   newNmstatePods(3),
 			Policy: p(SetPolicySuccess, "3/3 nodes successfully configured"),
 		}),
+		Entry("when node label selector excludes some nodes they are ignored for policy conditions calculations", ConditionsCase{
+			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node1", "policy1", enactmentconditions.SetSuccess),
+				e("node2", "policy1", enactmentconditions.SetSuccess),
+			},
+			Nodes: newNodes(3),
+			Pods:  newNmstatePods(3),
+			Policy: ls(&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "kubernetes.io/hostname", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"node3"}},
+				},
+			}, p(SetPolicySuccess, "2/2 nodes successfully configured")),
+		}),
 		Entry("when a node does not run nmstate pod ignore it for policy conditions calculations", ConditionsCase{
 			Enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
 				e("node1", "policy1", enactmentconditions.SetSuccess),
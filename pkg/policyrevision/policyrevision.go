@@ -0,0 +1,93 @@
+package policyrevision
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+// DefaultHistoryLimit is the number of previous revisions kept when the
+// policy does not set revisionHistoryLimit
+const DefaultHistoryLimit = 10
+
+// List returns the revisions of a policy sorted from the oldest to the newest
+func List(cli client.Reader, policyName string) ([]nmstatev1beta1.NodeNetworkConfigurationPolicyRevision, error) {
+	revisions := nmstatev1beta1.NodeNetworkConfigurationPolicyRevisionList{}
+	policyLabelFilter := client.MatchingLabels{nmstateapi.PolicyRevisionPolicyLabel: policyName}
+	err := cli.List(context.TODO(), &revisions, policyLabelFilter)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting policy revision list failed")
+	}
+	sort.Slice(revisions.Items, func(i, j int) bool {
+		return revisions.Items[i].Revision < revisions.Items[j].Revision
+	})
+	return revisions.Items, nil
+}
+
+// Get returns the revision of a policy to roll back to, revision 0 is the
+// newest one before the policy current generation.
+func Get(cli client.Reader, policy nmstatev1beta1.NodeNetworkConfigurationPolicy, revision int64) (*nmstatev1beta1.NodeNetworkConfigurationPolicyRevision, error) {
+	if revision == 0 {
+		revisions, err := List(cli, policy.Name)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(revisions) - 1; i >= 0; i-- {
+			if revisions[i].Revision < policy.Generation {
+				return &revisions[i], nil
+			}
+		}
+		return nil, fmt.Errorf("policy %s has no previous revision", policy.Name)
+	}
+
+	policyRevision := nmstatev1beta1.NodeNetworkConfigurationPolicyRevision{}
+	err := cli.Get(context.TODO(), nmstatev1beta1.PolicyRevisionKey(policy.Name, revision), &policyRevision)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("policy %s has no revision %d", policy.Name, revision)
+		}
+		return nil, errors.Wrapf(err, "failed getting policy %s revision %d", policy.Name, revision)
+	}
+	return &policyRevision, nil
+}
+
+// Rollback returns the policy with the spec restored from the revision, the
+// policy keeps its paused field so a rollback does not resume or pause it.
+func Rollback(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, policyRevision nmstatev1beta1.NodeNetworkConfigurationPolicyRevision) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	paused := policy.Spec.Paused
+	policy.Spec = *policyRevision.Spec.DeepCopy()
+	policy.Spec.Paused = paused
+	policy.Spec.RollbackTo = nil
+	return policy
+}
+
+// HistoryLimit returns how many revisions besides the current one are kept
+func HistoryLimit(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) int {
+	if policy.Spec.RevisionHistoryLimit == nil {
+		return DefaultHistoryLimit
+	}
+	return int(*policy.Spec.RevisionHistoryLimit)
+}
+
+// Prunable returns the revisions exceeding the policy history limit, the
+// revision of the current generation is never pruned.
+func Prunable(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, revisions []nmstatev1beta1.NodeNetworkConfigurationPolicyRevision) []nmstatev1beta1.NodeNetworkConfigurationPolicyRevision {
+	previous := []nmstatev1beta1.NodeNetworkConfigurationPolicyRevision{}
+	for _, policyRevision := range revisions {
+		if policyRevision.Revision != policy.Generation {
+			previous = append(previous, policyRevision)
+		}
+	}
+	exceeding := len(previous) - HistoryLimit(policy)
+	if exceeding <= 0 {
+		return []nmstatev1beta1.NodeNetworkConfigurationPolicyRevision{}
+	}
+	return previous[:exceeding]
+}
@@ -0,0 +1,16 @@
+package policyrevision
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.policyrevision-policyrevision_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Policy Revision Test Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,87 @@
+package policyrevision
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nmstateapi "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+func p(generation int64, revisionHistoryLimit *int32) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	return nmstatev1beta1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "policy1",
+			Generation: generation,
+		},
+		Spec: nmstateapi.NodeNetworkConfigurationPolicySpec{
+			RevisionHistoryLimit: revisionHistoryLimit,
+		},
+	}
+}
+
+func revisions(generations ...int64) []nmstatev1beta1.NodeNetworkConfigurationPolicyRevision {
+	policyRevisions := []nmstatev1beta1.NodeNetworkConfigurationPolicyRevision{}
+	for _, generation := range generations {
+		policyRevisions = append(policyRevisions, nmstatev1beta1.NewPolicyRevision(p(generation, nil)))
+	}
+	return policyRevisions
+}
+
+func limit(revisionHistoryLimit int32) *int32 {
+	return &revisionHistoryLimit
+}
+
+var _ = Describe("Policy revisions", func() {
+	DescribeTable("pruning revisions",
+		func(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, existing []nmstatev1beta1.NodeNetworkConfigurationPolicyRevision, expected []nmstatev1beta1.NodeNetworkConfigurationPolicyRevision) {
+			Expect(Prunable(policy, existing)).To(Equal(expected))
+		},
+		Entry("with revisions below the default limit", p(3, nil), revisions(1, 2, 3), revisions()),
+		Entry("with revisions above the limit", p(5, limit(2)), revisions(1, 2, 3, 4, 5), revisions(1, 2)),
+		Entry("with zero limit keeps the current revision", p(3, limit(0)), revisions(1, 2, 3), revisions(1, 2)),
+		Entry("with the current revision not created yet", p(4, limit(1)), revisions(1, 2, 3), revisions(1, 2)),
+	)
+
+	It("should list only the revisions of the policy sorted by revision", func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyRevision{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyRevisionList{},
+		)
+		second := nmstatev1beta1.NewPolicyRevision(p(2, nil))
+		first := nmstatev1beta1.NewPolicyRevision(p(1, nil))
+		otherPolicy := p(1, nil)
+		otherPolicy.Name = "policy2"
+		other := nmstatev1beta1.NewPolicyRevision(otherPolicy)
+		// Labeled like an enactment of policy1 instead of a revision
+		mislabeled := nmstatev1beta1.NewPolicyRevision(p(3, nil))
+		mislabeled.Labels = map[string]string{nmstateapi.EnactmentPolicyLabel: "policy1"}
+		cli := fake.NewFakeClientWithScheme(s, &second, &first, &other, &mislabeled)
+
+		listed, err := List(cli, "policy1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(listed).To(HaveLen(2))
+		Expect(listed[0].Revision).To(Equal(int64(1)))
+		Expect(listed[1].Revision).To(Equal(int64(2)))
+		Expect(listed[0].Labels).To(HaveKeyWithValue(nmstateapi.PolicyRevisionPolicyLabel, "policy1"))
+	})
+
+	It("should restore the revision spec keeping paused and dropping rollbackTo", func() {
+		policy := p(3, nil)
+		policy.Spec.Paused = true
+		revision := int64(1)
+		policy.Spec.RollbackTo = &revision
+		policyRevision := nmstatev1beta1.NewPolicyRevision(p(1, limit(5)))
+
+		rolledBack := Rollback(policy, policyRevision)
+		Expect(rolledBack.Spec.Paused).To(BeTrue())
+		Expect(rolledBack.Spec.RollbackTo).To(BeNil())
+		Expect(rolledBack.Spec.RevisionHistoryLimit).To(Equal(limit(5)))
+	})
+})
@@ -0,0 +1,16 @@
+package policytemplate
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.policytemplate-policytemplate_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Policy Template Test Suite", []Reporter{junitReporter})
+}
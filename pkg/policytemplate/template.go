@@ -0,0 +1,241 @@
+package policytemplate
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	corev1 "k8s.io/api/core/v1"
+	yaml "sigs.k8s.io/yaml"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+const (
+	templateMarker              = "{{"
+	defaultGwInterfaceGJsonPath = "routes.running.#(destination==\"0.0.0.0/0\").next-hop-interface"
+)
+
+// NodeFacts contains the node values that can be referenced from a
+// policy desiredState template
+type NodeFacts struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+type templateData struct {
+	Node NodeFacts
+}
+
+// IsTemplate returns true if the desired state contains template actions
+// that have to be rendered per node.
+func IsTemplate(desiredState shared.State) bool {
+	return strings.Contains(string(desiredState.Raw), templateMarker)
+}
+
+// Render resolves the desiredState template for a specific node, every
+// string value at the desiredState is handled as a golang text/template
+// with the node facts as data, `label` and `annotation` return the node
+// label or annotation and fail if it is missing. The node's current network
+// state can be accessed with `currentState "<gjson path>"` [1] that returns
+// the value at path from the node's NodeNetworkState currentState, and with
+// `defaultGatewayInterface` that returns the interface holding the IPv4
+// default route. Rendered values are strings unless the template output is
+// exactly the result of `toInt` or `toBool`.
+//
+// [1] https://github.com/tidwall/gjson/blob/master/SYNTAX.md
+func Render(desiredState shared.State, node corev1.Node, currentState shared.State) (shared.State, error) {
+	if !IsTemplate(desiredState) {
+		return desiredState, nil
+	}
+
+	var desiredStateData interface{}
+	err := yaml.Unmarshal(desiredState.Raw, &desiredStateData)
+	if err != nil {
+		return desiredState, errors.Wrap(err, "failed unmarshaling desired state template")
+	}
+
+	currentStateJSON, err := yaml.YAMLToJSON(currentState.Raw)
+	if err != nil {
+		return desiredState, errors.Wrap(err, "failed converting current state to JSON")
+	}
+
+	r := renderer{
+		data: templateData{
+			Node: NodeFacts{
+				Name:        node.Name,
+				Labels:      node.Labels,
+				Annotations: node.Annotations,
+			},
+		},
+		currentState: gjson.ParseBytes(currentStateJSON),
+	}
+
+	renderedData, err := r.renderValue("", desiredStateData)
+	if err != nil {
+		return desiredState, err
+	}
+
+	renderedState, err := yaml.Marshal(renderedData)
+	if err != nil {
+		return desiredState, errors.Wrap(err, "failed marshaling rendered desired state")
+	}
+	return shared.NewState(string(renderedState)), nil
+}
+
+type renderer struct {
+	data         templateData
+	currentState gjson.Result
+}
+
+// funcs returns the template functions, the values converted by toInt and
+// toBool are appended to converted so the rendered value can keep their type
+func (r renderer) funcs(converted *[]interface{}) template.FuncMap {
+	return template.FuncMap{
+		"toInt": func(value interface{}) (int64, error) {
+			i, err := toInt(value)
+			if err != nil {
+				return 0, err
+			}
+			*converted = append(*converted, i)
+			return i, nil
+		},
+		"toBool": func(value interface{}) (bool, error) {
+			b, err := toBool(value)
+			if err != nil {
+				return false, err
+			}
+			*converted = append(*converted, b)
+			return b, nil
+		},
+		"currentState": func(path string) (string, error) {
+			value := r.currentState.Get(path)
+			if !value.Exists() {
+				return "", fmt.Errorf("path '%s' not found at node current state", path)
+			}
+			return value.String(), nil
+		},
+		"label": func(key string) (string, error) {
+			value, ok := r.data.Node.Labels[key]
+			if !ok {
+				return "", fmt.Errorf("label '%s' not found at node", key)
+			}
+			return value, nil
+		},
+		"annotation": func(key string) (string, error) {
+			value, ok := r.data.Node.Annotations[key]
+			if !ok {
+				return "", fmt.Errorf("annotation '%s' not found at node", key)
+			}
+			return value, nil
+		},
+		"defaultGatewayInterface": func() (string, error) {
+			value := r.currentState.Get(defaultGwInterfaceGJsonPath)
+			if !value.Exists() || value.String() == "" {
+				return "", fmt.Errorf("default gateway interface not found at node current state")
+			}
+			return value.String(), nil
+		},
+	}
+}
+
+func (r renderer) renderValue(path string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			renderedChild, err := r.renderValue(joinPath(path, key), child)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = renderedChild
+		}
+		return v, nil
+	case []interface{}:
+		for i, child := range v {
+			renderedChild, err := r.renderValue(fmt.Sprintf("%s[%d]", path, i), child)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = renderedChild
+		}
+		return v, nil
+	case string:
+		return r.renderString(path, v)
+	default:
+		return v, nil
+	}
+}
+
+// renderString renders the template at value as a string, numbers and
+// booleans like mtu or vlan id have to be converted explicitly with toInt or
+// toBool since values like "on", "1.10" or "0123" are valid strings for
+// names, descriptions and versions. Templates rendering an empty value fail
+// since `index` does not fail on missing keys.
+func (r renderer) renderString(path string, value string) (interface{}, error) {
+	if !strings.Contains(value, templateMarker) {
+		return value, nil
+	}
+	converted := []interface{}{}
+	tmpl, err := template.New(path).Option("missingkey=error").Funcs(r.funcs(&converted)).Parse(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed parsing template at '%s'", path)
+	}
+	var rendered bytes.Buffer
+	err = tmpl.Execute(&rendered, r.data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed rendering template at '%s'", path)
+	}
+	if strings.TrimSpace(rendered.String()) == "" {
+		return nil, fmt.Errorf("failed rendering template at '%s': rendered an empty value", path)
+	}
+	// The converted type is kept only if the whole value is the converted
+	// one, e.g. not for 'mtu-{{ toInt "1500" }}'
+	if len(converted) == 1 && rendered.String() == fmt.Sprint(converted[0]) {
+		return converted[0], nil
+	}
+	return rendered.String(), nil
+}
+
+func toInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), nil
+		}
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err == nil {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("toInt: %q is not an integer", fmt.Sprint(value))
+}
+
+func toBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("toBool: %q is not a boolean", fmt.Sprint(value))
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,248 @@
+package policytemplate
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("Render", func() {
+	var (
+		node = corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node01",
+				Labels: map[string]string{
+					"nic": "eth1",
+				},
+				Annotations: map[string]string{
+					"example.com/address": "192.168.1.10",
+				},
+			},
+		}
+		currentState = nmstate.NewState(`
+interfaces:
+- name: eth0
+  type: ethernet
+  state: up
+  mtu: 1500
+routes:
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.66.1
+    next-hop-interface: eth0
+`)
+	)
+
+	Context("when desired state has no templates", func() {
+		desiredState := nmstate.NewState(`interfaces:
+  - name: eth1
+    state: up
+`)
+		It("should return it unmodified", func() {
+			renderedState, err := Render(desiredState, node, currentState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renderedState).To(Equal(desiredState))
+		})
+	})
+
+	Context("when desired state reference node facts", func() {
+		desiredState := nmstate.NewState(`interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  description: 'bridge at {{ .Node.Name }}'
+  ipv4:
+    enabled: true
+    address:
+    - ip: '{{ index .Node.Annotations "example.com/address" }}'
+      prefix-length: 24
+  bridge:
+    port:
+    - name: '{{ .Node.Labels.nic }}'
+`)
+		It("should render them with node values", func() {
+			renderedState, err := Render(desiredState, node, currentState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renderedState.String()).To(MatchYAML(`interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  description: bridge at node01
+  ipv4:
+    enabled: true
+    address:
+    - ip: 192.168.1.10
+      prefix-length: 24
+  bridge:
+    port:
+    - name: eth1
+`))
+		})
+	})
+
+	Context("when desired state reference node current state", func() {
+		desiredState := nmstate.NewState(`interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  description: 'mtu {{ currentState "interfaces.#(name==\"eth0\").mtu" }}'
+  bridge:
+    port:
+    - name: '{{ defaultGatewayInterface }}'
+`)
+		It("should render them with current state values", func() {
+			renderedState, err := Render(desiredState, node, currentState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renderedState.String()).To(MatchYAML(`interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  description: mtu 1500
+  bridge:
+    port:
+    - name: eth0
+`))
+		})
+	})
+
+	Context("when desired state reference a missing label", func() {
+		desiredState := nmstate.NewState(`interfaces:
+- name: '{{ .Node.Labels.missing }}'
+  state: up
+`)
+		It("should fail pointing to the template path", func() {
+			_, err := Render(desiredState, node, currentState)
+			Expect(err).To(MatchError(ContainSubstring("failed rendering template at 'interfaces[0].name'")))
+		})
+	})
+
+	Context("when desired state index a missing label", func() {
+		desiredState := nmstate.NewState(`interfaces:
+- name: '{{ index .Node.Labels "example.com/missing" }}'
+  state: up
+`)
+		It("should fail since the rendered value is empty", func() {
+			_, err := Render(desiredState, node, currentState)
+			Expect(err).To(MatchError(ContainSubstring("failed rendering template at 'interfaces[0].name': rendered an empty value")))
+		})
+	})
+
+	Context("when desired state reference a missing label with label function", func() {
+		desiredState := nmstate.NewState(`interfaces:
+- name: '{{ label "example.com/missing" }}'
+  state: up
+`)
+		It("should fail naming the label", func() {
+			_, err := Render(desiredState, node, currentState)
+			Expect(err).To(MatchError(ContainSubstring("label 'example.com/missing' not found at node")))
+		})
+	})
+
+	Context("when desired state converts numbers and booleans", func() {
+		desiredState := nmstate.NewState(`interfaces:
+- name: '{{ label "nic" }}.{{ index .Node.Annotations "example.com/vlan" }}'
+  type: vlan
+  state: up
+  mtu: '{{ currentState "interfaces.#(name==\"eth0\").mtu" | toInt }}'
+  vlan:
+    base-iface: '{{ label "nic" }}'
+    id: '{{ annotation "example.com/vlan" | toInt }}'
+  ipv4:
+    enabled: '{{ eq .Node.Name "node01" | toBool }}'
+    address:
+    - ip: '{{ annotation "example.com/address" }}'
+      prefix-length: '{{ annotation "example.com/prefix-length" | toInt }}'
+  description: 'vlan {{ annotation "example.com/vlan" | toInt }}'
+`)
+		It("should render them with their type", func() {
+			node := node.DeepCopy()
+			node.Annotations["example.com/vlan"] = "102"
+			node.Annotations["example.com/prefix-length"] = "24"
+			renderedState, err := Render(desiredState, *node, currentState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renderedState.String()).To(MatchYAML(`interfaces:
+- name: eth1.102
+  type: vlan
+  state: up
+  mtu: 1500
+  vlan:
+    base-iface: eth1
+    id: 102
+  ipv4:
+    enabled: true
+    address:
+    - ip: 192.168.1.10
+      prefix-length: 24
+  description: vlan 102
+`))
+		})
+	})
+
+	Context("when desired state templates values that look like other YAML types", func() {
+		desiredState := nmstate.NewState(`interfaces:
+- name: '{{ label "name" }}'
+  type: ethernet
+  state: up
+  description: '{{ label "mode" }} {{ label "version" }}'
+  ethtool:
+    feature:
+      mode: '{{ label "mode" }}'
+      version: '{{ label "version" }}'
+`)
+		It("should keep them as strings", func() {
+			node := node.DeepCopy()
+			node.Labels["name"] = "0123"
+			node.Labels["mode"] = "on"
+			node.Labels["version"] = "1.10"
+			renderedState, err := Render(desiredState, *node, currentState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renderedState.String()).To(MatchYAML(`interfaces:
+- name: "0123"
+  type: ethernet
+  state: up
+  description: on 1.10
+  ethtool:
+    feature:
+      mode: "on"
+      version: "1.10"
+`))
+		})
+	})
+
+	Context("when desired state converts a value that is not a number", func() {
+		desiredState := nmstate.NewState(`interfaces:
+- name: eth1
+  mtu: '{{ label "nic" | toInt }}'
+`)
+		It("should fail", func() {
+			_, err := Render(desiredState, node, currentState)
+			Expect(err).To(MatchError(ContainSubstring(`toInt: "eth1" is not an integer`)))
+		})
+	})
+
+	Context("when desired state reference a missing current state path", func() {
+		desiredState := nmstate.NewState(`interfaces:
+- name: '{{ currentState "interfaces.#(name==\"eth9\").name" }}'
+  state: up
+`)
+		It("should fail", func() {
+			_, err := Render(desiredState, node, currentState)
+			Expect(err).To(MatchError(ContainSubstring("not found at node current state")))
+		})
+	})
+
+	Context("when desired state template is malformed", func() {
+		desiredState := nmstate.NewState(`interfaces:
+- name: '{{ .Node.Name'
+  state: up
+`)
+		It("should fail parsing it", func() {
+			_, err := Render(desiredState, node, currentState)
+			Expect(err).To(MatchError(ContainSubstring("failed parsing template at 'interfaces[0].name'")))
+		})
+	})
+})
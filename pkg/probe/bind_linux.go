@@ -0,0 +1,23 @@
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"syscall"
+)
+
+// bindToDevice binds the socket to the interface so the traffic goes
+// through it regardless of the routes
+func bindToDevice(sourceInterface string) func(string, string, syscall.RawConn) error {
+	return func(_, _ string, rawConn syscall.RawConn) error {
+		var bindErr error
+		err := rawConn.Control(func(fd uintptr) {
+			bindErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, sourceInterface)
+		})
+		if err != nil {
+			return err
+		}
+		return bindErr
+	}
+}
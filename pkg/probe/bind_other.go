@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package probe
+
+import (
+	"fmt"
+	"syscall"
+)
+
+func bindToDevice(sourceInterface string) func(string, string, syscall.RawConn) error {
+	return func(string, string, syscall.RawConn) error {
+		return fmt.Errorf("binding to interface %s is only supported on linux", sourceInterface)
+	}
+}
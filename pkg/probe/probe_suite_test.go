@@ -0,0 +1,16 @@
+package probe
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.probe-probe_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Probe Test Suite", []Reporter{junitReporter})
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/tidwall/gjson"
 
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
 	"github.com/nmstate/kubernetes-nmstate/pkg/environment"
 	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
 )
@@ -31,7 +32,9 @@ var (
 type Probe struct {
 	name    string
 	timeout time.Duration
-	run     func(client.Client, time.Duration) error
+	run     func(client.Client, nmstatectl.Backend, time.Duration) error
+	// result is filled in by Run for the probes reported at the enactment
+	result *shared.ProbeResult
 }
 
 const (
@@ -42,8 +45,8 @@ const (
 	nodeReadinessProbeTimeout = 120 * time.Second
 )
 
-func currentStateAsGJson() (gjson.Result, error) {
-	observedStateRaw, err := nmstatectl.Show()
+func currentStateAsGJson(nmstate nmstatectl.Backend) (gjson.Result, error) {
+	observedStateRaw, err := nmstate.Show(context.TODO())
 	if err != nil {
 		return gjson.Result{}, errors.Wrap(err, "failed retrieving current state")
 	}
@@ -56,10 +59,14 @@ func currentStateAsGJson() (gjson.Result, error) {
 
 }
 
-func ping(target string, timeout time.Duration) (string, error) {
+func ping(target string, sourceInterface string, timeout time.Duration) (string, error) {
 	output := ""
+	arguments := []string{"-c", "1", target}
+	if sourceInterface != "" {
+		arguments = append(arguments, "-I", sourceInterface)
+	}
 	return output, wait.PollImmediate(time.Second, timeout, func() (bool, error) {
-		cmd := exec.Command("ping", "-c", "1", target)
+		cmd := exec.Command("ping", arguments...)
 		var outputBuffer bytes.Buffer
 		cmd.Stdout = &outputBuffer
 		cmd.Stderr = &outputBuffer
@@ -99,7 +106,7 @@ func checkApiServerConnectivity(timeout time.Duration) error {
 	})
 }
 
-func checkNodeReadiness(client client.Client, timeout time.Duration) error {
+func checkNodeReadiness(client client.Client, _ nmstatectl.Backend, timeout time.Duration) error {
 	return wait.PollImmediate(time.Second, timeout, func() (bool, error) {
 		nodeName := environment.NodeName()
 		node := corev1.Node{}
@@ -117,10 +124,10 @@ func checkNodeReadiness(client client.Client, timeout time.Duration) error {
 	})
 }
 
-func defaultGw() (string, error) {
+func defaultGw(nmstate nmstatectl.Backend) (string, error) {
 	defaultGw := ""
 	return defaultGw, wait.PollImmediate(time.Second, defaultGwRetrieveTimeout, func() (bool, error) {
-		gjsonCurrentState, err := currentStateAsGJson()
+		gjsonCurrentState, err := currentStateAsGJson(nmstate)
 		if err != nil {
 			return false, errors.Wrap(err, "failed retrieving current state to retrieve default gw")
 		}
@@ -143,13 +150,13 @@ func defaultGw() (string, error) {
 	})
 }
 
-func runPing(client client.Client, timeout time.Duration) error {
-	defaultGw, err := defaultGw()
+func runPing(_ client.Client, nmstate nmstatectl.Backend, timeout time.Duration) error {
+	defaultGw, err := defaultGw(nmstate)
 	if err != nil {
 		return errors.Wrap(err, "failed to retrieve default gw at runProbes")
 	}
 
-	pingOutput, err := ping(defaultGw, timeout)
+	pingOutput, err := ping(defaultGw, "", timeout)
 	if err != nil {
 		return errors.Wrapf(err, "error pinging default gateway -> output: %s", pingOutput)
 	}
@@ -176,8 +183,8 @@ func lookupRootNS(nameServer string, timeout time.Duration) error {
 	return nil
 }
 
-func runDNS(client client.Client, timeout time.Duration) error {
-	currentStateAsGJson, err := currentStateAsGJson()
+func runDNS(_ client.Client, nmstate nmstatectl.Backend, timeout time.Duration) error {
+	currentStateAsGJson, err := currentStateAsGJson(nmstate)
 	if err != nil {
 		return errors.Wrap(err, "failed retrieving current state to get name resolving config")
 	}
@@ -191,7 +198,7 @@ func runDNS(client client.Client, timeout time.Duration) error {
 
 	errs := []error{}
 	for _, runningNameServer := range runningNameServers {
-		err = lookupRootNS(runningNameServer.String(), defaultDnsProbeTimeout)
+		err = lookupRootNS(runningNameServer.String(), timeout)
 		if err != nil {
 			errs = append(errs, err)
 		} else {
@@ -202,25 +209,25 @@ func runDNS(client client.Client, timeout time.Duration) error {
 }
 
 // Select will return the external connectivity probes that are working (ping and dns) and
-// the internal connectivity probes
-func Select(cli client.Client) []Probe {
+// the internal connectivity probes, they will run with the given timeouts
+func Select(cli client.Client, nmstate nmstatectl.Backend, timeouts Timeouts) []Probe {
 	probes := []Probe{}
 
-	err := runPing(cli, time.Second)
+	err := runPing(cli, nmstate, time.Second)
 	if err == nil {
 		probes = append(probes, Probe{
 			name:    "ping",
-			timeout: defaultGwProbeTimeout,
+			timeout: timeouts.DefaultGateway,
 			run:     runPing,
 		})
 	} else {
 		log.Info("WARNING not selecting 'ping' probe")
 	}
-	err = runDNS(cli, time.Second)
+	err = runDNS(cli, nmstate, timeouts.DNS)
 	if err == nil {
 		probes = append(probes, Probe{
 			name:    "dns",
-			timeout: defaultDnsProbeTimeout,
+			timeout: timeouts.DNS,
 			run:     runDNS,
 		})
 	} else {
@@ -229,15 +236,15 @@ func Select(cli client.Client) []Probe {
 
 	probes = append(probes, Probe{
 		name:    "api-server",
-		timeout: apiServerProbeTimeout,
-		run: func(_ client.Client, timeout time.Duration) error {
+		timeout: timeouts.APIServer,
+		run: func(_ client.Client, _ nmstatectl.Backend, timeout time.Duration) error {
 			return checkApiServerConnectivity(timeout)
 		},
 	})
 
 	probes = append(probes, Probe{
 		name:    "node-readiness",
-		timeout: nodeReadinessProbeTimeout,
+		timeout: timeouts.NodeReadiness,
 		run:     checkNodeReadiness,
 	})
 
@@ -245,20 +252,49 @@ func Select(cli client.Client) []Probe {
 }
 
 // Run will run the externalConnectivityProbes and also some internal
-// kubernetes cluster connectivity and node readiness probes
-func Run(client client.Client, probes []Probe) error {
-	currentState, err := nmstatectl.Show()
+// kubernetes cluster connectivity and node readiness probes, the current
+// state is read with nmstate to report it if a probe fails
+func Run(client client.Client, nmstate nmstatectl.Backend, probes []Probe) error {
+	currentState, err := nmstate.Show(context.TODO())
 	if err != nil {
 		return errors.Wrap(err, "failed to retrieve currentState at runProbes")
 	}
 
 	for _, p := range probes {
 		log.Info(fmt.Sprintf("Running '%s' probe", p.name))
-		err = p.run(client, p.timeout)
+		err = p.run(client, nmstate, p.timeout)
+		p.report(err)
 		if err != nil {
-			return errors.Wrapf(err, "failed runnig probe '%s' with after network reconfiguration -> currentState: %s", p.name, currentState)
+			return &FailedError{
+				Name: p.name,
+				err:  errors.Wrapf(err, "failed runnig probe '%s' with after network reconfiguration -> currentState: %s", p.name, currentState),
+			}
 		}
 
 	}
 	return nil
 }
+
+// FailedError is returned by Run when one of the probes fails
+type FailedError struct {
+	Name string
+	err  error
+}
+
+func (e *FailedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *FailedError) Unwrap() error {
+	return e.err
+}
+
+// FailedName returns the name of the probe that has failed if err comes
+// from Run, otherwise it returns an empty string
+func FailedName(err error) string {
+	var failedErr *FailedError
+	if errors.As(err, &failedErr) {
+		return failedErr.Name
+	}
+	return ""
+}
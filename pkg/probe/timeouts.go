@@ -0,0 +1,84 @@
+package probe
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// Timeouts are the checkpoint and probe timeouts used to apply a desired
+// state
+type Timeouts struct {
+	Checkpoint         time.Duration
+	DefaultGateway     time.Duration
+	DNS                time.Duration
+	APIServer          time.Duration
+	NodeReadiness      time.Duration
+	userProbes         time.Duration
+	explicitCheckpoint bool
+}
+
+// DefaultTimeouts returns the timeouts used when neither the NMState CR nor
+// the policy set them
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		DefaultGateway: defaultGwProbeTimeout,
+		DNS:            defaultDnsProbeTimeout,
+		APIServer:      apiServerProbeTimeout,
+		NodeReadiness:  nodeReadinessProbeTimeout,
+	}.withCheckpoint()
+}
+
+// Override returns the timeouts with the positive values set at overrides,
+// the checkpoint is recalculated from the probe timeouts unless it has been
+// set explicitly, an explicit one shorter than the probes is extended to
+// them.
+func (t Timeouts) Override(overrides *shared.NodeNetworkConfigurationPolicyTimeouts) Timeouts {
+	if overrides == nil {
+		return t
+	}
+	override := func(timeout *time.Duration, value *metav1.Duration) bool {
+		if value == nil || value.Duration <= 0 {
+			return false
+		}
+		*timeout = value.Duration
+		return true
+	}
+	override(&t.DefaultGateway, overrides.DefaultGatewayProbe)
+	override(&t.DNS, overrides.DNSProbe)
+	override(&t.APIServer, overrides.APIServerProbe)
+	override(&t.NodeReadiness, overrides.NodeReadinessProbe)
+	if override(&t.Checkpoint, overrides.Checkpoint) {
+		t.explicitCheckpoint = true
+	}
+	return t.withCheckpoint()
+}
+
+// WithUserProbes returns the timeouts with the checkpoint extended to cover
+// the user probes.
+func (t Timeouts) WithUserProbes(userProbes []Probe) Timeouts {
+	t.userProbes = TotalTimeout(userProbes)
+	return t.withCheckpoint()
+}
+
+// ProbesTimeout returns how long the builtin and user probes may take to
+// pass, they run one after the other
+func (t Timeouts) ProbesTimeout() time.Duration {
+	return t.DefaultGateway + t.DNS + t.APIServer + t.NodeReadiness + t.userProbes
+}
+
+// withCheckpoint doubles the default gw ping probe, check API server
+// connectivity and user probes timeouts, to ensure the checkpoint is alive
+// before rolling it back. An explicit checkpoint is extended to the probes
+// timeouts, otherwise it would be rolled back while they are still running.
+// https://nmstate.github.io/cli_guide#manual-transaction-control
+func (t Timeouts) withCheckpoint() Timeouts {
+	if !t.explicitCheckpoint {
+		t.Checkpoint = (t.DefaultGateway + t.APIServer + t.userProbes) * 2
+	} else if probesTimeout := t.ProbesTimeout(); t.Checkpoint < probesTimeout {
+		t.Checkpoint = probesTimeout
+	}
+	return t
+}
@@ -0,0 +1,62 @@
+package probe
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+func d(duration time.Duration) *metav1.Duration {
+	return &metav1.Duration{Duration: duration}
+}
+
+var _ = Describe("Probe timeouts", func() {
+	It("should default to 120s probes and a checkpoint doubling gw and api server ones", func() {
+		timeouts := DefaultTimeouts()
+		Expect(timeouts.DefaultGateway).To(Equal(120 * time.Second))
+		Expect(timeouts.DNS).To(Equal(120 * time.Second))
+		Expect(timeouts.APIServer).To(Equal(120 * time.Second))
+		Expect(timeouts.NodeReadiness).To(Equal(120 * time.Second))
+		Expect(timeouts.Checkpoint).To(Equal(480 * time.Second))
+	})
+
+	DescribeTable("overriding timeouts",
+		func(clusterTimeouts, policyTimeouts *shared.NodeNetworkConfigurationPolicyTimeouts, expectedGw, expectedAPIServer, expectedCheckpoint time.Duration) {
+			timeouts := DefaultTimeouts().Override(clusterTimeouts).Override(policyTimeouts)
+			Expect(timeouts.DefaultGateway).To(Equal(expectedGw))
+			Expect(timeouts.APIServer).To(Equal(expectedAPIServer))
+			Expect(timeouts.Checkpoint).To(Equal(expectedCheckpoint))
+		},
+		Entry("without overrides", nil, nil, 120*time.Second, 120*time.Second, 480*time.Second),
+		Entry("with cluster probe timeouts recalculates the checkpoint",
+			&shared.NodeNetworkConfigurationPolicyTimeouts{DefaultGatewayProbe: d(10 * time.Second), APIServerProbe: d(20 * time.Second)}, nil,
+			10*time.Second, 20*time.Second, 60*time.Second),
+		Entry("with policy timeouts on top of cluster ones",
+			&shared.NodeNetworkConfigurationPolicyTimeouts{DefaultGatewayProbe: d(10 * time.Second), APIServerProbe: d(20 * time.Second)},
+			&shared.NodeNetworkConfigurationPolicyTimeouts{DefaultGatewayProbe: d(5 * time.Minute)},
+			5*time.Minute, 20*time.Second, 640*time.Second),
+		Entry("with explicit cluster checkpoint kept after policy probe timeouts",
+			&shared.NodeNetworkConfigurationPolicyTimeouts{Checkpoint: d(15 * time.Minute)},
+			&shared.NodeNetworkConfigurationPolicyTimeouts{APIServerProbe: d(time.Minute)},
+			120*time.Second, time.Minute, 15*time.Minute),
+		Entry("with explicit checkpoint shorter than the probes extended to them",
+			&shared.NodeNetworkConfigurationPolicyTimeouts{DefaultGatewayProbe: d(10 * time.Second), APIServerProbe: d(20 * time.Second)},
+			&shared.NodeNetworkConfigurationPolicyTimeouts{Checkpoint: d(time.Minute)},
+			10*time.Second, 20*time.Second, 270*time.Second),
+		Entry("with non positive timeouts ignored",
+			&shared.NodeNetworkConfigurationPolicyTimeouts{DefaultGatewayProbe: d(0), Checkpoint: d(-time.Second)}, nil,
+			120*time.Second, 120*time.Second, 480*time.Second),
+	)
+
+	It("should extend an explicit checkpoint shorter than the probes with the user probes", func() {
+		timeouts := DefaultTimeouts().Override(&shared.NodeNetworkConfigurationPolicyTimeouts{Checkpoint: d(10 * time.Minute)})
+		userProbes := User([]shared.NodeNetworkConfigurationPolicyProbe{{Name: "probe1", Timeout: d(5 * time.Minute)}})
+		Expect(timeouts.WithUserProbes(userProbes).Checkpoint).To(Equal(13 * time.Minute))
+	})
+})
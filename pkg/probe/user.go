@@ -0,0 +1,300 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+)
+
+const (
+	defaultUserProbeTimeout = 120 * time.Second
+	userProbeAttemptTimeout = 5 * time.Second
+)
+
+// sysClassNet is where the interface carrier is read from, it can be
+// changed at unit tests
+var sysClassNet = "/sys/class/net"
+
+// User returns the probes defined at the policy, unlike the builtin ones
+// they are not checked before applying the desired state since they
+// usually depend on it.
+func User(policyProbes []shared.NodeNetworkConfigurationPolicyProbe) []Probe {
+	probes := []Probe{}
+	for _, policyProbe := range policyProbes {
+		probes = append(probes, newUserProbe(policyProbe.Name, shared.ProbeSourcePolicy, policyProbe.Timeout, policyProbe.ProbeCheck))
+	}
+	return probes
+}
+
+// NodeNetwork returns the probes of the NodeNetworkProbes whose node
+// selector matches the node labels. The invalid ones are skipped so they do
+// not roll back every policy, they are returned as errors to report them.
+func NodeNetwork(nodeNetworkProbes []nmstatev1beta1.NodeNetworkProbe, nodeLabels map[string]string) ([]Probe, []error) {
+	probes := []Probe{}
+	invalid := []error{}
+	for _, nodeNetworkProbe := range nodeNetworkProbes {
+		if !labels.SelectorFromSet(nodeNetworkProbe.Spec.NodeSelector).Matches(labels.Set(nodeLabels)) {
+			continue
+		}
+		causes := ValidateCheck(field.NewPath("spec"), nodeNetworkProbe.Name, nodeNetworkProbe.Spec.ProbeCheck)
+		if len(causes) > 0 {
+			messages := []string{}
+			for _, cause := range causes {
+				messages = append(messages, fmt.Sprintf("%s: %s", cause.Field, cause.Message))
+			}
+			invalid = append(invalid, fmt.Errorf("skipping invalid NodeNetworkProbe %s: %s", nodeNetworkProbe.Name, strings.Join(messages, "; ")))
+			continue
+		}
+		probes = append(probes, newUserProbe(nodeNetworkProbe.Name, shared.ProbeSourceNodeNetworkProbe, nodeNetworkProbe.Spec.Timeout, nodeNetworkProbe.Spec.ProbeCheck))
+	}
+	return probes, invalid
+}
+
+// Results returns the results of the user probes that have been run
+func Results(probes []Probe) []shared.ProbeResult {
+	results := []shared.ProbeResult{}
+	for _, p := range probes {
+		if p.result != nil && !p.result.LastRunTime.IsZero() {
+			results = append(results, *p.result)
+		}
+	}
+	return results
+}
+
+func newUserProbe(name string, source shared.ProbeSource, timeout *metav1.Duration, check shared.ProbeCheck) Probe {
+	probeTimeout := defaultUserProbeTimeout
+	if timeout != nil && timeout.Duration > 0 {
+		probeTimeout = timeout.Duration
+	}
+	return Probe{
+		name:    name,
+		timeout: probeTimeout,
+		run:     userProbeRunner(name, check),
+		result:  &shared.ProbeResult{Name: name, Source: source},
+	}
+}
+
+func (p Probe) report(err error) {
+	if p.result == nil {
+		return
+	}
+	p.result.Passed = err == nil
+	p.result.Message = ""
+	if err != nil {
+		p.result.Message = err.Error()
+	}
+	p.result.LastRunTime = metav1.Now()
+}
+
+// TotalTimeout returns how long the probes may take to pass
+func TotalTimeout(probes []Probe) time.Duration {
+	total := time.Duration(0)
+	for _, p := range probes {
+		total += p.timeout
+	}
+	return total
+}
+
+func userProbeRunner(name string, check shared.ProbeCheck) func(client.Client, nmstatectl.Backend, time.Duration) error {
+	return func(_ client.Client, nmstate nmstatectl.Backend, timeout time.Duration) error {
+		switch {
+		case check.ICMP != nil:
+			output, err := ping(check.ICMP.Target, check.ICMP.SourceInterface, timeout)
+			if err != nil {
+				return errors.Wrapf(err, "error pinging %s -> output: %s", check.ICMP.Target, output)
+			}
+			return nil
+		case check.TCP != nil:
+			return checkTCP(*check.TCP, timeout)
+		case check.HTTP != nil:
+			return checkHTTP(*check.HTTP, timeout)
+		case check.DNS != nil:
+			return checkDNS(nmstate, *check.DNS, timeout)
+		case check.Interface != nil:
+			return checkInterface(*check.Interface, timeout)
+		}
+		return fmt.Errorf("probe %s has no check", name)
+	}
+}
+
+func dialer(sourceInterface string) *net.Dialer {
+	d := &net.Dialer{Timeout: userProbeAttemptTimeout}
+	if sourceInterface != "" {
+		d.Control = bindToDevice(sourceInterface)
+	}
+	return d
+}
+
+func checkTCP(tcpProbe shared.TCPProbe, timeout time.Duration) error {
+	var lastErr error
+	err := wait.PollImmediate(time.Second, timeout, func() (bool, error) {
+		conn, err := dialer(tcpProbe.SourceInterface).Dial("tcp", tcpProbe.Address)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		conn.Close()
+		return true, nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed connecting to %s: %v", tcpProbe.Address, lastErr)
+	}
+	return nil
+}
+
+func checkHTTP(httpProbe shared.HTTPProbe, timeout time.Duration) error {
+	expectedStatus := http.StatusOK
+	if httpProbe.ExpectedStatus != 0 {
+		expectedStatus = int(httpProbe.ExpectedStatus)
+	}
+	httpClient := http.Client{
+		Timeout: userProbeAttemptTimeout,
+		Transport: &http.Transport{
+			DialContext: dialer(httpProbe.SourceInterface).DialContext,
+		},
+		// Redirects are reported as they are so they can be expected
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	var lastErr error
+	err := wait.PollImmediate(time.Second, timeout, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), userProbeAttemptTimeout)
+		defer cancel()
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, httpProbe.URL, nil)
+		if err != nil {
+			return false, err
+		}
+		response, err := httpClient.Do(request)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		response.Body.Close()
+		if response.StatusCode != expectedStatus {
+			lastErr = fmt.Errorf("status %d, expected %d", response.StatusCode, expectedStatus)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed getting %s: %v", httpProbe.URL, lastErr)
+	}
+	return nil
+}
+
+// nameServers returns the name servers used by the DNS probe, the ones at
+// node are used unless the probe sets one
+func nameServers(nmstate nmstatectl.Backend, dnsProbe shared.DNSProbe) ([]string, error) {
+	if dnsProbe.Server != "" {
+		return []string{dnsProbe.Server}, nil
+	}
+	currentState, err := currentStateAsGJson(nmstate)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed retrieving current state to get name resolving config")
+	}
+	servers := []string{}
+	for _, server := range currentState.Get("dns-resolver.running.server").Array() {
+		servers = append(servers, server.String())
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("missing name servers at node")
+	}
+	return servers, nil
+}
+
+func resolver(nameServer string) *net.Resolver {
+	address := nameServer
+	if _, _, err := net.SplitHostPort(nameServer); err != nil {
+		address = net.JoinHostPort(nameServer, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer("").DialContext(ctx, network, address)
+		},
+	}
+}
+
+func checkDNS(nmstate nmstatectl.Backend, dnsProbe shared.DNSProbe, timeout time.Duration) error {
+	var lastErr error
+	err := wait.PollImmediate(time.Second, timeout, func() (bool, error) {
+		servers, err := nameServers(nmstate, dnsProbe)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		for _, server := range servers {
+			ctx, cancel := context.WithTimeout(context.Background(), userProbeAttemptTimeout)
+			_, err = resolver(server).LookupHost(ctx, dnsProbe.Host)
+			cancel()
+			if err == nil {
+				return true, nil
+			}
+			lastErr = err
+		}
+		return false, nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed resolving %s: %v", dnsProbe.Host, lastErr)
+	}
+	return nil
+}
+
+func checkInterface(interfaceProbe shared.InterfaceProbe, timeout time.Duration) error {
+	var lastErr error
+	err := wait.PollImmediate(time.Second, timeout, func() (bool, error) {
+		lastErr = interfaceReady(interfaceProbe)
+		return lastErr == nil, nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "interface %s is not ready: %v", interfaceProbe.Name, lastErr)
+	}
+	return nil
+}
+
+func interfaceReady(interfaceProbe shared.InterfaceProbe) error {
+	if interfaceProbe.Carrier {
+		carrier, err := ioutil.ReadFile(filepath.Join(sysClassNet, interfaceProbe.Name, "carrier"))
+		if err != nil {
+			return errors.Wrap(err, "failed reading carrier")
+		}
+		if strings.TrimSpace(string(carrier)) != "1" {
+			return fmt.Errorf("no carrier")
+		}
+	}
+	if interfaceProbe.IPAddress != "" {
+		iface, err := net.InterfaceByName(interfaceProbe.Name)
+		if err != nil {
+			return err
+		}
+		addresses, err := iface.Addrs()
+		if err != nil {
+			return errors.Wrap(err, "failed listing addresses")
+		}
+		expected := net.ParseIP(interfaceProbe.IPAddress)
+		for _, address := range addresses {
+			if ipNet, ok := address.(*net.IPNet); ok && ipNet.IP.Equal(expected) {
+				return nil
+			}
+		}
+		return fmt.Errorf("missing address %s", interfaceProbe.IPAddress)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package probe
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/nmstatectl"
+)
+
+func runUser(policyProbe shared.NodeNetworkConfigurationPolicyProbe) error {
+	policyProbe.Timeout = d(time.Second)
+	return Run(nil, &nmstatectl.Fake{}, User([]shared.NodeNetworkConfigurationPolicyProbe{policyProbe}))
+}
+
+var _ = Describe("User probes", func() {
+	It("should default the timeout and extend the checkpoint with them", func() {
+		userProbes := User([]shared.NodeNetworkConfigurationPolicyProbe{
+			{Name: "a", ProbeCheck: shared.ProbeCheck{TCP: &shared.TCPProbe{Address: "127.0.0.1:80"}}},
+			{Name: "b", ProbeCheck: shared.ProbeCheck{TCP: &shared.TCPProbe{Address: "127.0.0.1:80"}}, Timeout: d(time.Minute)},
+		})
+		Expect(TotalTimeout(userProbes)).To(Equal(3 * time.Minute))
+		Expect(DefaultTimeouts().WithUserProbes(userProbes).Checkpoint).To(Equal(14 * time.Minute))
+	})
+
+	Context("with a tcp probe", func() {
+		var listener net.Listener
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			listener.Close()
+		})
+		It("should pass when the address accepts connections", func() {
+			Expect(runUser(shared.NodeNetworkConfigurationPolicyProbe{Name: "tcp", ProbeCheck: shared.ProbeCheck{TCP: &shared.TCPProbe{Address: listener.Addr().String()}}})).To(Succeed())
+		})
+		It("should fail when the address is closed", func() {
+			address := listener.Addr().String()
+			listener.Close()
+			Expect(runUser(shared.NodeNetworkConfigurationPolicyProbe{Name: "tcp", ProbeCheck: shared.ProbeCheck{TCP: &shared.TCPProbe{Address: address}}})).ToNot(Succeed())
+		})
+		It("should report the failed probe name", func() {
+			address := listener.Addr().String()
+			listener.Close()
+			err := runUser(shared.NodeNetworkConfigurationPolicyProbe{Name: "tcp", ProbeCheck: shared.ProbeCheck{TCP: &shared.TCPProbe{Address: address}}})
+			Expect(FailedName(errors.Wrap(err, "failed runnig user probes"))).To(Equal("tcp"))
+		})
+	})
+
+	Context("with an http probe", func() {
+		var server *httptest.Server
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		It("should pass when the status is the expected one", func() {
+			Expect(runUser(shared.NodeNetworkConfigurationPolicyProbe{Name: "http", ProbeCheck: shared.ProbeCheck{HTTP: &shared.HTTPProbe{URL: server.URL, ExpectedStatus: http.StatusUnauthorized}}})).To(Succeed())
+		})
+		It("should fail when the status is not the default 200", func() {
+			err := runUser(shared.NodeNetworkConfigurationPolicyProbe{Name: "http", ProbeCheck: shared.ProbeCheck{HTTP: &shared.HTTPProbe{URL: server.URL}}})
+			Expect(err).To(MatchError(ContainSubstring("status 401, expected 200")))
+		})
+	})
+
+	Context("with an interface probe", func() {
+		var tmpDir string
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "sys-class-net")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(tmpDir, "lo"), 0755)).To(Succeed())
+			sysClassNet = tmpDir
+		})
+		AfterEach(func() {
+			sysClassNet = "/sys/class/net"
+			os.RemoveAll(tmpDir)
+		})
+		It("should pass when the interface has carrier and the address", func() {
+			Expect(ioutil.WriteFile(filepath.Join(tmpDir, "lo", "carrier"), []byte("1\n"), 0644)).To(Succeed())
+			Expect(runUser(shared.NodeNetworkConfigurationPolicyProbe{Name: "lo", ProbeCheck: shared.ProbeCheck{Interface: &shared.InterfaceProbe{Name: "lo", Carrier: true, IPAddress: "127.0.0.1"}}})).To(Succeed())
+		})
+		It("should fail when the interface has no carrier", func() {
+			Expect(ioutil.WriteFile(filepath.Join(tmpDir, "lo", "carrier"), []byte("0\n"), 0644)).To(Succeed())
+			err := runUser(shared.NodeNetworkConfigurationPolicyProbe{Name: "lo", ProbeCheck: shared.ProbeCheck{Interface: &shared.InterfaceProbe{Name: "lo", Carrier: true}}})
+			Expect(err).To(MatchError(ContainSubstring("no carrier")))
+		})
+		It("should fail when the interface misses the address", func() {
+			err := runUser(shared.NodeNetworkConfigurationPolicyProbe{Name: "lo", ProbeCheck: shared.ProbeCheck{Interface: &shared.InterfaceProbe{Name: "lo", IPAddress: "192.0.2.1"}}})
+			Expect(err).To(MatchError(ContainSubstring("missing address 192.0.2.1")))
+		})
+	})
+	Context("with a dns probe", func() {
+		It("should fail when the name server is not reachable", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			address := listener.Addr().String()
+			listener.Close()
+			err = runUser(shared.NodeNetworkConfigurationPolicyProbe{Name: "dns", ProbeCheck: shared.ProbeCheck{DNS: &shared.DNSProbe{Host: "example.com", Server: address}}})
+			Expect(err).To(MatchError(ContainSubstring("failed resolving example.com")))
+		})
+		It("should fail when the node has no name servers", func() {
+			err := runUser(shared.NodeNetworkConfigurationPolicyProbe{Name: "dns", ProbeCheck: shared.ProbeCheck{DNS: &shared.DNSProbe{Host: "example.com"}}})
+			Expect(err).To(MatchError(ContainSubstring("missing name servers at node")))
+		})
+	})
+
+	Context("with NodeNetworkProbes", func() {
+		var listener net.Listener
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			listener.Close()
+		})
+		nodeNetworkProbe := func(name string, nodeSelector map[string]string, address string) nmstatev1beta1.NodeNetworkProbe {
+			return nmstatev1beta1.NodeNetworkProbe{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: nmstatev1beta1.NodeNetworkProbeSpec{
+					NodeSelector: nodeSelector,
+					Timeout:      d(time.Second),
+					ProbeCheck:   shared.ProbeCheck{TCP: &shared.TCPProbe{Address: address}},
+				},
+			}
+		}
+		It("should select the probes matching the node labels", func() {
+			nodeProbes, invalid := NodeNetwork([]nmstatev1beta1.NodeNetworkProbe{
+				nodeNetworkProbe("all", nil, listener.Addr().String()),
+				nodeNetworkProbe("workers", map[string]string{"node-role.kubernetes.io/worker": ""}, listener.Addr().String()),
+				nodeNetworkProbe("masters", map[string]string{"node-role.kubernetes.io/master": ""}, listener.Addr().String()),
+			}, map[string]string{"node-role.kubernetes.io/worker": "", "kubernetes.io/hostname": "node01"})
+			Expect(invalid).To(BeEmpty())
+			Expect(nodeProbes).To(HaveLen(2))
+			Expect(nodeProbes[0].name).To(Equal("all"))
+			Expect(nodeProbes[1].name).To(Equal("workers"))
+		})
+		It("should skip and report the invalid probes", func() {
+			withoutCheck := nodeNetworkProbe("without-check", nil, "")
+			withoutCheck.Spec.ProbeCheck = shared.ProbeCheck{}
+			emptyTarget := nodeNetworkProbe("empty-target", nil, "")
+			emptyTarget.Spec.ProbeCheck = shared.ProbeCheck{ICMP: &shared.ICMPProbe{}}
+			nodeProbes, invalid := NodeNetwork([]nmstatev1beta1.NodeNetworkProbe{
+				withoutCheck,
+				emptyTarget,
+				nodeNetworkProbe("valid", nil, listener.Addr().String()),
+			}, nil)
+			Expect(nodeProbes).To(HaveLen(1))
+			Expect(nodeProbes[0].name).To(Equal("valid"))
+			Expect(invalid).To(HaveLen(2))
+			Expect(invalid[0].Error()).To(ContainSubstring("skipping invalid NodeNetworkProbe without-check: spec: invalid probe \"without-check\": exactly one of"))
+			Expect(invalid[1].Error()).To(ContainSubstring("skipping invalid NodeNetworkProbe empty-target: spec.icmp.target: invalid icmp probe target: must not be empty"))
+		})
+		It("should report the results of the probes that have been run", func() {
+			address := listener.Addr().String()
+			nodeProbes, _ := NodeNetwork([]nmstatev1beta1.NodeNetworkProbe{
+				nodeNetworkProbe("closed", nil, "127.0.0.1:1"),
+				nodeNetworkProbe("not-run", nil, address),
+			}, nil)
+			userProbes := append(
+				User([]shared.NodeNetworkConfigurationPolicyProbe{{Name: "policy", Timeout: d(time.Second), ProbeCheck: shared.ProbeCheck{TCP: &shared.TCPProbe{Address: address}}}}),
+				nodeProbes...,
+			)
+			Expect(Run(nil, &nmstatectl.Fake{}, userProbes)).ToNot(Succeed())
+
+			results := Results(userProbes)
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].Name).To(Equal("policy"))
+			Expect(results[0].Source).To(Equal(shared.ProbeSourcePolicy))
+			Expect(results[0].Passed).To(BeTrue())
+			Expect(results[0].Message).To(BeEmpty())
+			Expect(results[1].Name).To(Equal("closed"))
+			Expect(results[1].Source).To(Equal(shared.ProbeSourceNodeNetworkProbe))
+			Expect(results[1].Passed).To(BeFalse())
+			Expect(results[1].Message).To(ContainSubstring("failed connecting to 127.0.0.1:1"))
+		})
+	})
+})
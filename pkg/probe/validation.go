@@ -0,0 +1,71 @@
+package probe
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// ValidateCheck checks that exactly one of the probe checks is set and
+// that it is well formed
+func ValidateCheck(fieldPath *field.Path, name string, check shared.ProbeCheck) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	invalid := func(fieldPath *field.Path, format string, a ...interface{}) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf(format, a...),
+			Field:   fieldPath.String(),
+		})
+	}
+	checks := 0
+	if check.ICMP != nil {
+		checks++
+		if check.ICMP.Target == "" {
+			invalid(fieldPath.Child("icmp", "target"), "invalid icmp probe target: must not be empty")
+		}
+	}
+	if check.TCP != nil {
+		checks++
+		_, port, err := net.SplitHostPort(check.TCP.Address)
+		if err != nil || port == "" {
+			invalid(fieldPath.Child("tcp", "address"), "invalid tcp probe address: %q: must be host:port", check.TCP.Address)
+		}
+	}
+	if check.HTTP != nil {
+		checks++
+		probeURL, err := url.Parse(check.HTTP.URL)
+		if err != nil || (probeURL.Scheme != "http" && probeURL.Scheme != "https") || probeURL.Host == "" {
+			invalid(fieldPath.Child("http", "url"), "invalid http probe url: %q: must be an http or https URL", check.HTTP.URL)
+		}
+		if check.HTTP.ExpectedStatus != 0 && (check.HTTP.ExpectedStatus < 100 || check.HTTP.ExpectedStatus > 599) {
+			invalid(fieldPath.Child("http", "expectedStatus"), "invalid http probe expected status: %d: must be between 100 and 599", check.HTTP.ExpectedStatus)
+		}
+	}
+	if check.DNS != nil {
+		checks++
+		if check.DNS.Host == "" {
+			invalid(fieldPath.Child("dns", "host"), "invalid dns probe host: must not be empty")
+		}
+	}
+	if check.Interface != nil {
+		checks++
+		if check.Interface.Name == "" {
+			invalid(fieldPath.Child("interface", "name"), "invalid interface probe name: must not be empty")
+		}
+		if !check.Interface.Carrier && check.Interface.IPAddress == "" {
+			invalid(fieldPath.Child("interface"), "invalid interface probe: carrier or ipAddress has to be checked")
+		}
+		if check.Interface.IPAddress != "" && net.ParseIP(check.Interface.IPAddress) == nil {
+			invalid(fieldPath.Child("interface", "ipAddress"), "invalid interface probe ip address: %q", check.Interface.IPAddress)
+		}
+	}
+	if checks != 1 {
+		invalid(fieldPath, "invalid probe %q: exactly one of icmp, tcp, http, dns or interface has to be set", name)
+	}
+	return causes
+}
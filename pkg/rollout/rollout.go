@@ -0,0 +1,284 @@
+package rollout
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/node"
+)
+
+// Status tells if a node can start applying a policy with staged rollout,
+// if not Message explains what the node is waiting for and RequeueAfter
+// when it is worth to check again, zero means unknown. Aborted means that
+// nodes from the previous steps failed or drifted and the node must not
+// apply the policy, Message lists them.
+type Status struct {
+	Ready        bool
+	Aborted      bool
+	Message      string
+	RequeueAfter time.Duration
+}
+
+// Order returns the nodes in rollout order, nodes without the order label
+// go first, then they are sorted by the label value and by name.
+func Order(nodes []corev1.Node, nodeOrderLabel string) []corev1.Node {
+	ordered := make([]corev1.Node, len(nodes))
+	copy(ordered, nodes)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		iValue, iHasLabel := ordered[i].Labels[nodeOrderLabel]
+		jValue, jHasLabel := ordered[j].Labels[nodeOrderLabel]
+		if nodeOrderLabel != "" {
+			if iHasLabel != jHasLabel {
+				return !iHasLabel
+			}
+			if iValue != jValue {
+				return iValue < jValue
+			}
+		}
+		return ordered[i].Name < ordered[j].Name
+	})
+	return ordered
+}
+
+// StepsEnd returns the number of ordered nodes configured at the end of each
+// step, nodes not covered by the steps are added as a last step.
+func StepsEnd(steps []nmstate.NodeNetworkConfigurationPolicyRolloutStep, numberOfNodes int) ([]int, error) {
+	stepsEnd := []int{}
+	previousEnd := 0
+	for i, step := range steps {
+		end, err := node.ScaledMaxUnavailableNodeCount(numberOfNodes, step.Nodes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed calculating rollout step %d nodes", i+1)
+		}
+		if end > numberOfNodes {
+			end = numberOfNodes
+		}
+		if end < previousEnd {
+			end = previousEnd
+		}
+		stepsEnd = append(stepsEnd, end)
+		previousEnd = end
+	}
+	if previousEnd < numberOfNodes {
+		stepsEnd = append(stepsEnd, numberOfNodes)
+	}
+	return stepsEnd, nil
+}
+
+// Check calculates if nodeName can start applying the policy, the nodes
+// from the previous steps have to be configured with the current policy
+// generation and the previous step pause has to be elapsed. The rollout is
+// aborted if any of those nodes is failing or, at the end of the pause, if
+// any of them has drifted or has failing probes. The probes are re-run by
+// the previous step nodes after the pause, the node waits for them.
+func Check(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, nodes []corev1.Node, enactments []nmstatev1beta1.NodeNetworkConfigurationEnactment, nodeName string, now time.Time) (Status, error) {
+	rollout := policy.Spec.Rollout
+	if rollout == nil || len(rollout.Steps) == 0 {
+		return Status{Ready: true}, nil
+	}
+
+	ordered, stepsEnd, step, err := nodeStep(policy, nodes, nodeName)
+	if err != nil {
+		return Status{}, err
+	}
+	if step == 0 {
+		return Status{Ready: true}, nil
+	}
+
+	previousStepEnd := stepsEnd[step-1]
+	previous := progress(policy, ordered[:previousStepEnd], enactments)
+	if len(previous.failing) > 0 {
+		return Status{
+			Aborted: true,
+			Message: fmt.Sprintf("rollout step %d/%d nodes are failing: %s", step, len(stepsEnd), strings.Join(previous.failing, ", ")),
+		}, nil
+	}
+	if previous.configured < previousStepEnd {
+		return Status{
+			Message: fmt.Sprintf("waiting for rollout step %d/%d, %d/%d nodes configured", step, len(stepsEnd), previous.configured, previousStepEnd),
+		}, nil
+	}
+
+	pause := stepPause(policy, step-1, len(stepsEnd))
+	if pause > 0 {
+		pauseEnd := previous.lastConfigured.Add(pause)
+		if now.Before(pauseEnd) {
+			remaining := pauseEnd.Sub(now)
+			return Status{
+				Message:      fmt.Sprintf("waiting %s after rollout step %d/%d", remaining.Round(time.Second), step, len(stepsEnd)),
+				RequeueAfter: remaining,
+			}, nil
+		}
+	}
+	if len(previous.drifted) > 0 {
+		return Status{
+			Aborted: true,
+			Message: fmt.Sprintf("rollout step %d/%d nodes have drifted: %s", step, len(stepsEnd), strings.Join(previous.drifted, ", ")),
+		}, nil
+	}
+	if pause > 0 && !policy.Spec.DryRun {
+		// The nodes from the steps before were checked at their own pause
+		previousStepStart := 0
+		if step > 1 {
+			previousStepStart = stepsEnd[step-2]
+		}
+		previousStep := progress(policy, ordered[previousStepStart:previousStepEnd], enactments)
+		probesFailing, probesPending := previousStep.probesSince(previous.lastConfigured.Add(pause))
+		if len(probesFailing) > 0 {
+			return Status{
+				Aborted: true,
+				Message: fmt.Sprintf("rollout step %d/%d nodes have failing probes: %s", step, len(stepsEnd), strings.Join(probesFailing, ", ")),
+			}, nil
+		}
+		if len(probesPending) > 0 {
+			return Status{
+				Message: fmt.Sprintf("waiting for rollout step %d/%d nodes to re-run their probes: %s", step, len(stepsEnd), strings.Join(probesPending, ", ")),
+			}, nil
+		}
+	}
+	return Status{Ready: true}, nil
+}
+
+// PauseEnd returns when the pause after the rollout step of nodeName ends,
+// that is when the node has to re-run its probes so the next step can
+// start. hasPause is false if the step has no pause or it is the last one,
+// the returned time is zero while the step nodes are not configured yet.
+func PauseEnd(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, nodes []corev1.Node, enactments []nmstatev1beta1.NodeNetworkConfigurationEnactment, nodeName string) (time.Time, bool, error) {
+	rollout := policy.Spec.Rollout
+	if rollout == nil || len(rollout.Steps) == 0 {
+		return time.Time{}, false, nil
+	}
+	ordered, stepsEnd, step, err := nodeStep(policy, nodes, nodeName)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	pause := stepPause(policy, step, len(stepsEnd))
+	if pause == 0 {
+		return time.Time{}, false, nil
+	}
+	stepProgress := progress(policy, ordered[:stepsEnd[step]], enactments)
+	if stepProgress.configured < stepsEnd[step] {
+		return time.Time{}, true, nil
+	}
+	return stepProgress.lastConfigured.Add(pause), true, nil
+}
+
+// nodeStep returns the ordered nodes, the steps end and the index of the
+// step nodeName belongs to
+func nodeStep(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, nodes []corev1.Node, nodeName string) ([]corev1.Node, []int, int, error) {
+	ordered := Order(nodes, policy.Spec.Rollout.NodeOrderLabel)
+	nodeIndex := -1
+	for i, orderedNode := range ordered {
+		if orderedNode.Name == nodeName {
+			nodeIndex = i
+			break
+		}
+	}
+	if nodeIndex < 0 {
+		return nil, nil, 0, fmt.Errorf("node %s is not part of the policy rollout", nodeName)
+	}
+
+	stepsEnd, err := StepsEnd(policy.Spec.Rollout.Steps, len(ordered))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	step := 0
+	for nodeIndex >= stepsEnd[step] {
+		step++
+	}
+	return ordered, stepsEnd, step, nil
+}
+
+// stepPause returns the pause after the step, the implicit last step and
+// the last step have no pause since no step goes after them
+func stepPause(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, step int, numberOfSteps int) time.Duration {
+	steps := policy.Spec.Rollout.Steps
+	if step >= len(steps) || step >= numberOfSteps-1 || steps[step].Pause == nil {
+		return 0
+	}
+	return steps[step].Pause.Duration
+}
+
+// stepProgress summarizes the enactments at the current policy generation
+// of the nodes up to a rollout step
+type stepProgress struct {
+	configured     int
+	lastConfigured time.Time
+	failing        []string
+	drifted        []string
+	// configuredNodes and their enactments, in rollout order
+	configuredNodes []string
+	enactments      []nmstatev1beta1.NodeNetworkConfigurationEnactment
+}
+
+func progress(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, nodes []corev1.Node, enactments []nmstatev1beta1.NodeNetworkConfigurationEnactment) stepProgress {
+	enactmentsByName := map[string]nmstatev1beta1.NodeNetworkConfigurationEnactment{}
+	for _, enactment := range enactments {
+		enactmentsByName[enactment.Name] = enactment
+	}
+
+	p := stepProgress{}
+	for _, stepNode := range nodes {
+		enactment, found := enactmentsByName[nmstate.EnactmentKey(stepNode.Name, policy.Name).Name]
+		if !found || enactment.Status.PolicyGeneration != policy.Generation {
+			continue
+		}
+		if isConditionTrue(enactment.Status.Conditions, nmstate.NodeNetworkConfigurationEnactmentConditionFailing) {
+			p.failing = append(p.failing, stepNode.Name)
+		}
+		if isConditionTrue(enactment.Status.Conditions, nmstate.NodeNetworkConfigurationEnactmentConditionDrifted) {
+			p.drifted = append(p.drifted, stepNode.Name)
+		}
+		condition := enactment.Status.Conditions.Find(configuredConditionType(policy))
+		if condition == nil || condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		p.configured++
+		p.configuredNodes = append(p.configuredNodes, stepNode.Name)
+		p.enactments = append(p.enactments, enactment)
+		if condition.LastTransitionTime.Time.After(p.lastConfigured) {
+			p.lastConfigured = condition.LastTransitionTime.Time
+		}
+	}
+	return p
+}
+
+// probesSince returns the probes that have failed and the nodes whose
+// probes have not been run since the given time
+func (p stepProgress) probesSince(since time.Time) (failing []string, pending []string) {
+	for i, enactment := range p.enactments {
+		nodeName := p.configuredNodes[i]
+		for _, result := range enactment.Status.ProbeResults {
+			if result.LastRunTime.Time.Before(since) {
+				pending = append(pending, nodeName)
+				break
+			}
+			if !result.Passed {
+				failing = append(failing, fmt.Sprintf("%s (%s)", nodeName, result.Name))
+			}
+		}
+	}
+	return failing, pending
+}
+
+func isConditionTrue(conditions nmstate.ConditionList, conditionType nmstate.ConditionType) bool {
+	condition := conditions.Find(conditionType)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// configuredConditionType is the enactment condition that marks a node as
+// configured, dry run policies do not commit the configuration.
+func configuredConditionType(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) nmstate.ConditionType {
+	if policy.Spec.DryRun {
+		return nmstate.NodeNetworkConfigurationEnactmentConditionDryRunSucceeded
+	}
+	return nmstate.NodeNetworkConfigurationEnactmentConditionAvailable
+}
@@ -0,0 +1,16 @@
+package rollout
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/ginkgo/reporters"
+)
+
+func TestUnit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	junitReporter := reporters.NewJUnitReporter("junit.rollout-rollout_suite_test.xml")
+	RunSpecsWithDefaultAndCustomReporters(t, "Rollout Test Suite", []Reporter{junitReporter})
+}
@@ -0,0 +1,287 @@
+package rollout
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
+)
+
+const (
+	policyName       = "policy1"
+	policyGeneration = 2
+	masterLabel      = "node-role.kubernetes.io/master"
+)
+
+func n(name string, labels map[string]string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+func e(nodeName string, generation int64, conditionsSetter func(*nmstate.ConditionList, string)) nmstatev1beta1.NodeNetworkConfigurationEnactment {
+	conditions := nmstate.ConditionList{}
+	conditionsSetter(&conditions, "")
+	return nmstatev1beta1.NodeNetworkConfigurationEnactment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nmstate.EnactmentKey(nodeName, policyName).Name,
+		},
+		Status: nmstate.NodeNetworkConfigurationEnactmentStatus{
+			PolicyGeneration: generation,
+			Conditions:       conditions,
+		},
+	}
+}
+
+func probed(enactment nmstatev1beta1.NodeNetworkConfigurationEnactment, passed bool, lastRunTime time.Time) nmstatev1beta1.NodeNetworkConfigurationEnactment {
+	enactment.Status.ProbeResults = []nmstate.ProbeResult{
+		{Name: "ping-gateway", Source: nmstate.ProbeSourcePolicy, Passed: passed, LastRunTime: metav1.NewTime(lastRunTime)},
+	}
+	return enactment
+}
+
+func successAndDrifted(conditions *nmstate.ConditionList, message string) {
+	enactmentconditions.SetSuccess(conditions, message)
+	enactmentconditions.SetDrifted(conditions, message)
+}
+
+func step(nodes intstr.IntOrString, pause time.Duration) nmstate.NodeNetworkConfigurationPolicyRolloutStep {
+	rolloutStep := nmstate.NodeNetworkConfigurationPolicyRolloutStep{Nodes: nodes}
+	if pause > 0 {
+		rolloutStep.Pause = &metav1.Duration{Duration: pause}
+	}
+	return rolloutStep
+}
+
+func p(dryRun bool, steps ...nmstate.NodeNetworkConfigurationPolicyRolloutStep) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	return nmstatev1beta1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       policyName,
+			Generation: policyGeneration,
+		},
+		Spec: nmstate.NodeNetworkConfigurationPolicySpec{
+			DryRun: dryRun,
+			Rollout: &nmstate.NodeNetworkConfigurationPolicyRollout{
+				Steps:          steps,
+				NodeOrderLabel: masterLabel,
+			},
+		},
+	}
+}
+
+func names(nodes []corev1.Node) []string {
+	nodeNames := []string{}
+	for _, node := range nodes {
+		nodeNames = append(nodeNames, node.Name)
+	}
+	return nodeNames
+}
+
+var _ = Describe("Rollout", func() {
+	var nodes = []corev1.Node{
+		n("node04", map[string]string{masterLabel: ""}),
+		n("node03", nil),
+		n("node02", map[string]string{masterLabel: ""}),
+		n("node01", nil),
+	}
+
+	Context("when ordering nodes", func() {
+		It("should put nodes without the order label first and sort them by name", func() {
+			Expect(names(Order(nodes, masterLabel))).To(Equal([]string{"node01", "node03", "node02", "node04"}))
+		})
+		It("should sort them by name without order label", func() {
+			Expect(names(Order(nodes, ""))).To(Equal([]string{"node01", "node02", "node03", "node04"}))
+		})
+	})
+
+	DescribeTable("calculating steps end",
+		func(steps []nmstate.NodeNetworkConfigurationPolicyRolloutStep, numberOfNodes int, expectedStepsEnd []int) {
+			stepsEnd, err := StepsEnd(steps, numberOfNodes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stepsEnd).To(Equal(expectedStepsEnd))
+		},
+		Entry("with canary and percentage steps",
+			[]nmstate.NodeNetworkConfigurationPolicyRolloutStep{step(intstr.FromInt(1), 0), step(intstr.FromString("10%"), 0)}, 20,
+			[]int{1, 2, 20}),
+		Entry("with percentage rounded up",
+			[]nmstate.NodeNetworkConfigurationPolicyRolloutStep{step(intstr.FromString("10%"), 0)}, 4,
+			[]int{1, 4}),
+		Entry("with steps beyond the number of nodes",
+			[]nmstate.NodeNetworkConfigurationPolicyRolloutStep{step(intstr.FromInt(1), 0), step(intstr.FromInt(10), 0)}, 3,
+			[]int{1, 3}),
+		Entry("with decreasing steps",
+			[]nmstate.NodeNetworkConfigurationPolicyRolloutStep{step(intstr.FromInt(2), 0), step(intstr.FromInt(1), 0), step(intstr.FromString("100%"), 0)}, 3,
+			[]int{2, 2, 3}),
+	)
+
+	type CheckCase struct {
+		policy     nmstatev1beta1.NodeNetworkConfigurationPolicy
+		enactments []nmstatev1beta1.NodeNetworkConfigurationEnactment
+		nodeName   string
+		now        time.Time
+		ready      bool
+		aborted    bool
+		message    string
+	}
+	DescribeTable("checking if node rollout step has started",
+		func(c CheckCase) {
+			status, err := Check(c.policy, nodes, c.enactments, c.nodeName, c.now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status.Ready).To(Equal(c.ready))
+			Expect(status.Aborted).To(Equal(c.aborted))
+			Expect(status.Message).To(Equal(c.message))
+		},
+		Entry("first step node is ready", CheckCase{
+			policy:   p(false, step(intstr.FromInt(1), time.Hour)),
+			nodeName: "node01",
+			now:      time.Now(),
+			ready:    true,
+		}),
+		Entry("second step node waits for first step nodes", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), 0)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{e("node01", policyGeneration, enactmentconditions.SetProgressing)},
+			nodeName:   "node03",
+			now:        time.Now(),
+			message:    "waiting for rollout step 1/2, 0/1 nodes configured",
+		}),
+		Entry("second step node waits for first step nodes at current policy generation", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), 0)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{e("node01", policyGeneration-1, enactmentconditions.SetSuccess)},
+			nodeName:   "node03",
+			now:        time.Now(),
+			message:    "waiting for rollout step 1/2, 0/1 nodes configured",
+		}),
+		Entry("second step node is ready after first step nodes are configured", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), 0)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{e("node01", policyGeneration, enactmentconditions.SetSuccess)},
+			nodeName:   "node03",
+			now:        time.Now(),
+			ready:      true,
+		}),
+		Entry("second step node waits for first step pause", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), time.Hour)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{e("node01", policyGeneration, enactmentconditions.SetSuccess)},
+			nodeName:   "node03",
+			now:        time.Now(),
+			message:    "waiting 1h0m0s after rollout step 1/2",
+		}),
+		Entry("second step node is ready after first step pause", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), time.Hour)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{e("node01", policyGeneration, enactmentconditions.SetSuccess)},
+			nodeName:   "node03",
+			now:        time.Now().Add(2 * time.Hour),
+			ready:      true,
+		}),
+		Entry("second step node aborts if first step nodes are failing", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), time.Hour)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{e("node01", policyGeneration, enactmentconditions.SetFailedToConfigure)},
+			nodeName:   "node03",
+			now:        time.Now(),
+			aborted:    true,
+			message:    "rollout step 1/2 nodes are failing: node01",
+		}),
+		Entry("second step node waits for first step pause if first step nodes have drifted", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), time.Hour)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{e("node01", policyGeneration, successAndDrifted)},
+			nodeName:   "node03",
+			now:        time.Now().Add(30 * time.Minute),
+			message:    "waiting 30m0s after rollout step 1/2",
+		}),
+		Entry("second step node aborts after first step pause if first step nodes have drifted", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), time.Hour)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{e("node01", policyGeneration, successAndDrifted)},
+			nodeName:   "node03",
+			now:        time.Now().Add(2 * time.Hour),
+			aborted:    true,
+			message:    "rollout step 1/2 nodes have drifted: node01",
+		}),
+		Entry("second step node waits for first step nodes to re-run their probes after the pause", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), time.Hour)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{probed(e("node01", policyGeneration, enactmentconditions.SetSuccess), true, time.Now())},
+			nodeName:   "node03",
+			now:        time.Now().Add(2 * time.Hour),
+			message:    "waiting for rollout step 1/2 nodes to re-run their probes: node01",
+		}),
+		Entry("second step node aborts if first step nodes probes fail during the pause", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), time.Hour)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{probed(e("node01", policyGeneration, enactmentconditions.SetSuccess), false, time.Now().Add(time.Hour+time.Minute))},
+			nodeName:   "node03",
+			now:        time.Now().Add(2 * time.Hour),
+			aborted:    true,
+			message:    "rollout step 1/2 nodes have failing probes: node01 (ping-gateway)",
+		}),
+		Entry("second step node is ready after first step nodes probes pass after the pause", CheckCase{
+			policy:     p(false, step(intstr.FromInt(1), time.Hour)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{probed(e("node01", policyGeneration, enactmentconditions.SetSuccess), true, time.Now().Add(time.Hour+time.Minute))},
+			nodeName:   "node03",
+			now:        time.Now().Add(2 * time.Hour),
+			ready:      true,
+		}),
+		Entry("third step node only waits for second step nodes to re-run their probes", CheckCase{
+			policy: p(false, step(intstr.FromInt(1), time.Hour), step(intstr.FromString("50%"), time.Hour)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				probed(e("node01", policyGeneration, enactmentconditions.SetSuccess), true, time.Now()),
+				probed(e("node03", policyGeneration, enactmentconditions.SetSuccess), true, time.Now().Add(time.Hour+time.Minute)),
+			},
+			nodeName: "node02",
+			now:      time.Now().Add(2 * time.Hour),
+			ready:    true,
+		}),
+		Entry("control plane node goes at last step", CheckCase{
+			policy: p(false, step(intstr.FromInt(1), 0), step(intstr.FromString("50%"), 0)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{
+				e("node01", policyGeneration, enactmentconditions.SetSuccess),
+				e("node03", policyGeneration, enactmentconditions.SetProgressing),
+			},
+			nodeName: "node02",
+			now:      time.Now(),
+			message:  "waiting for rollout step 2/3, 1/2 nodes configured",
+		}),
+		Entry("dry run node is ready after first step nodes succeeded dry run", CheckCase{
+			policy:     p(true, step(intstr.FromInt(1), 0)),
+			enactments: []nmstatev1beta1.NodeNetworkConfigurationEnactment{e("node01", policyGeneration, enactmentconditions.SetDryRunSucceeded)},
+			nodeName:   "node03",
+			now:        time.Now(),
+			ready:      true,
+		}),
+	)
+
+	Context("when calculating the end of a node step pause", func() {
+		It("should be unknown until the step nodes are configured", func() {
+			pauseEnd, hasPause, err := PauseEnd(p(false, step(intstr.FromInt(1), time.Hour)), nodes, nil, "node01")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasPause).To(BeTrue())
+			Expect(pauseEnd.IsZero()).To(BeTrue())
+		})
+		It("should be the pause after the step nodes are configured", func() {
+			enactment := e("node01", policyGeneration, enactmentconditions.SetSuccess)
+			configured := enactment.Status.Conditions.Find(nmstate.NodeNetworkConfigurationEnactmentConditionAvailable).LastTransitionTime.Time
+			pauseEnd, hasPause, err := PauseEnd(p(false, step(intstr.FromInt(1), time.Hour)), nodes, []nmstatev1beta1.NodeNetworkConfigurationEnactment{enactment}, "node01")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasPause).To(BeTrue())
+			Expect(pauseEnd).To(Equal(configured.Add(time.Hour)))
+		})
+		It("should have no pause at the last step", func() {
+			_, hasPause, err := PauseEnd(p(false, step(intstr.FromInt(1), time.Hour)), nodes, nil, "node03")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hasPause).To(BeFalse())
+		})
+	})
+
+	It("should fail checking a node that does not match the policy", func() {
+		_, err := Check(p(false, step(intstr.FromInt(1), 0)), nodes, nil, "node05", time.Now())
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -28,3 +29,19 @@ func (s *Selectors) UnmatchedNodeLabels(nodeName string) (map[string]string, err
 
 	return unmatchingLabels(s.policy.Spec.NodeSelector, node.ObjectMeta.Labels), nil
 }
+
+func (s *Selectors) MatchesNode(nodeName string) (bool, error) {
+	logger := s.logger.WithValues("node", nodeName)
+	node := corev1.Node{}
+	err := s.client.Get(context.TODO(), types.NamespacedName{Name: nodeName}, &node)
+	if err != nil {
+		logger.Info("Cannot find corev1.Node")
+		return false, err
+	}
+
+	nodeSelector, err := NodeSelector(s.policy)
+	if err != nil {
+		return false, err
+	}
+	return nodeSelector.Matches(labels.Set(node.ObjectMeta.Labels)), nil
+}
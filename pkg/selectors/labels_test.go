@@ -142,3 +142,111 @@ var _ = Describe("NodeNetworkConfigurationPolicy controller selectors", func() {
 			}),
 	)
 })
+
+var _ = Describe("NodeNetworkConfigurationPolicy controller node label selector", func() {
+	var nodeName = "node01"
+	type nodeLabelSelectorCase struct {
+		NodeSelector      map[string]string
+		NodeLabelSelector *metav1.LabelSelector
+		NodeLabels        map[string]string
+		ShouldMatch       bool
+	}
+	DescribeTable("testing node label selectors",
+		func(c nodeLabelSelectorCase) {
+			node := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   nodeName,
+					Labels: c.NodeLabels,
+				},
+			}
+
+			policy := nmstatev1beta1.NodeNetworkConfigurationPolicy{
+				Spec: nmstate.NodeNetworkConfigurationPolicySpec{
+					NodeSelector:      c.NodeSelector,
+					NodeLabelSelector: c.NodeLabelSelector,
+				},
+			}
+
+			selectorsRequest := NewFromPolicy(fake.NewFakeClient(&node), policy)
+
+			matchesNode, err := selectorsRequest.MatchesNode(nodeName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matchesNode).To(Equal(c.ShouldMatch))
+		},
+		Entry("with no selectors",
+			nodeLabelSelectorCase{
+				NodeLabels:  map[string]string{"rack": "r1"},
+				ShouldMatch: true,
+			}),
+		Entry("with In requirement matching",
+			nodeLabelSelectorCase{
+				NodeLabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "rack", Operator: metav1.LabelSelectorOpIn, Values: []string{"r1", "r2"}},
+					},
+				},
+				NodeLabels:  map[string]string{"rack": "r2"},
+				ShouldMatch: true,
+			}),
+		Entry("with In requirement not matching",
+			nodeLabelSelectorCase{
+				NodeLabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "rack", Operator: metav1.LabelSelectorOpIn, Values: []string{"r1", "r2"}},
+					},
+				},
+				NodeLabels:  map[string]string{"rack": "r3"},
+				ShouldMatch: false,
+			}),
+		Entry("with Exists and NotIn requirements matching",
+			nodeLabelSelectorCase{
+				NodeLabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "node-role.kubernetes.io/worker", Operator: metav1.LabelSelectorOpExists},
+						{Key: "pool", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"storage"}},
+					},
+				},
+				NodeLabels:  map[string]string{"node-role.kubernetes.io/worker": ""},
+				ShouldMatch: true,
+			}),
+		Entry("with NotIn requirement not matching",
+			nodeLabelSelectorCase{
+				NodeLabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "node-role.kubernetes.io/worker", Operator: metav1.LabelSelectorOpExists},
+						{Key: "pool", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"storage"}},
+					},
+				},
+				NodeLabels:  map[string]string{"node-role.kubernetes.io/worker": "", "pool": "storage"},
+				ShouldMatch: false,
+			}),
+		Entry("with DoesNotExist requirement not matching",
+			nodeLabelSelectorCase{
+				NodeLabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "maintenance", Operator: metav1.LabelSelectorOpDoesNotExist},
+					},
+				},
+				NodeLabels:  map[string]string{"maintenance": "true"},
+				ShouldMatch: false,
+			}),
+		Entry("with node selector and node label selector matching",
+			nodeLabelSelectorCase{
+				NodeSelector: map[string]string{"kubernetes.io/arch": "amd64"},
+				NodeLabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"rack": "r1"},
+				},
+				NodeLabels:  map[string]string{"kubernetes.io/arch": "amd64", "rack": "r1"},
+				ShouldMatch: true,
+			}),
+		Entry("with node selector not matching and node label selector matching",
+			nodeLabelSelectorCase{
+				NodeSelector: map[string]string{"kubernetes.io/arch": "arm64"},
+				NodeLabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"rack": "r1"},
+				},
+				NodeLabels:  map[string]string{"kubernetes.io/arch": "amd64", "rack": "r1"},
+				ShouldMatch: false,
+			}),
+	)
+})
@@ -2,6 +2,10 @@ package selectors
 
 import (
 	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -23,3 +27,19 @@ func NewFromPolicy(client client.Client, policy nmstatev1beta1.NodeNetworkConfig
 	selectors.logger = logf.Log.WithName("policy/selectors").WithValues("policy", policy.Name)
 	return selectors
 }
+
+// NodeSelector returns the selector that nodes have to match for the
+// policy to be applied at them, it contains the requirements from both
+// nodeSelector and nodeLabelSelector.
+func NodeSelector(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) (labels.Selector, error) {
+	selector := labels.SelectorFromSet(policy.Spec.NodeSelector)
+	if policy.Spec.NodeLabelSelector == nil {
+		return selector, nil
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(policy.Spec.NodeLabelSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed converting nodeLabelSelector")
+	}
+	requirements, _ := labelSelector.Requirements()
+	return selector.Add(requirements...), nil
+}
@@ -0,0 +1,105 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	fieldPathSeparator = "."
+	fieldPathWildcard  = "*"
+)
+
+// fieldPathRoots are the keys of the reported state the fields filter can
+// remove fields from
+var fieldPathRoots = []string{"interfaces", "routes"}
+
+// validateFieldPath checks that the path starts with one of the reported
+// state keys, otherwise it would not remove anything
+func validateFieldPath(path string) error {
+	root := strings.Split(path, fieldPathSeparator)[0]
+	for _, fieldPathRoot := range fieldPathRoots {
+		if root == fieldPathRoot {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid field path %q: has to start with one of %s", path, strings.Join(fieldPathRoots, ", "))
+}
+
+// fieldsFilter drops the fields at the paths, the path keys are separated by
+// dots and * matches every list item or map value, e.g.
+// interfaces.*.ethtool.feature
+type fieldsFilter struct {
+	paths [][]string
+}
+
+func newFieldsFilter(paths []string) fieldsFilter {
+	f := fieldsFilter{}
+	for _, path := range paths {
+		f.paths = append(f.paths, strings.Split(path, fieldPathSeparator))
+	}
+	return f
+}
+
+func (f fieldsFilter) filterOut(state *rootState) {
+	document := map[string]interface{}{
+		"interfaces": interfacesData(state.Interfaces),
+	}
+	if state.Routes != nil {
+		document["routes"] = map[string]interface{}{
+			"config":  state.Routes.Config,
+			"running": state.Routes.Running,
+		}
+	}
+	for _, path := range f.paths {
+		deleteField(document, path)
+	}
+}
+
+// interfacesData returns the interfaces maps, removing a field from them
+// removes it from the interfaces
+func interfacesData(interfaces []interfaceState) []interface{} {
+	data := []interface{}{}
+	for _, iface := range interfaces {
+		data = append(data, iface.Data)
+	}
+	return data
+}
+
+// deleteField removes the field at path from node, the last key has to be
+// a map key or * to remove all of them since list items are not removed
+func deleteField(node interface{}, path []string) {
+	if len(path) == 0 {
+		return
+	}
+	key, rest := path[0], path[1:]
+	switch typedNode := node.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			if key == fieldPathWildcard {
+				for k := range typedNode {
+					delete(typedNode, k)
+				}
+				return
+			}
+			delete(typedNode, key)
+			return
+		}
+		if key == fieldPathWildcard {
+			for _, value := range typedNode {
+				deleteField(value, rest)
+			}
+			return
+		}
+		if value, ok := typedNode[key]; ok {
+			deleteField(value, rest)
+		}
+	case []interface{}:
+		if key != fieldPathWildcard || len(rest) == 0 {
+			return
+		}
+		for _, item := range typedNode {
+			deleteField(item, rest)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package state
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 
 	"github.com/gobwas/glob"
@@ -11,38 +13,224 @@ import (
 	yaml "sigs.k8s.io/yaml"
 )
 
+// DefaultInterfacesFilter omits the pods veth and calico interfaces from the
+// reported state if INTERFACES_FILTER is not set
+const DefaultInterfacesFilter = "{veth*,cali*}"
+
 var (
-	interfacesFilterGlobFromEnv glob.Glob
+	filtersFromEnv filterChain
+	// filtersFromEnvErr is returned by FilterOut so an invalid filter
+	// fails the state refresh instead of crashing the handler
+	filtersFromEnvErr error
 )
 
 func init() {
 	if !environment.IsHandler() {
 		return
 	}
+	filtersFromEnv, filtersFromEnvErr = filtersFromEnvironment()
+}
+
+// filtersFromEnvironment builds the filter chain from INTERFACES_FILTER and
+// STATE_FILTER
+func filtersFromEnvironment() (filterChain, error) {
 	interfacesFilter, isSet := os.LookupEnv("INTERFACES_FILTER")
 	if !isSet {
-		panic("INTERFACES_FILTER is mandatory")
+		interfacesFilter = DefaultInterfacesFilter
+	}
+	interfacesFilterGlob, err := CompileInterfacesFilter(interfacesFilter)
+	if err != nil {
+		return nil, fmt.Errorf("INTERFACES_FILTER is not valid: %v", err)
+	}
+	stateFilter := shared.NodeNetworkStateFilter{}
+	if stateFilterRaw := os.Getenv("STATE_FILTER"); stateFilterRaw != "" {
+		if err := json.Unmarshal([]byte(stateFilterRaw), &stateFilter); err != nil {
+			return nil, fmt.Errorf("STATE_FILTER is not valid: %v", err)
+		}
+		if err := ValidateStateFilter(stateFilter); err != nil {
+			return nil, fmt.Errorf("STATE_FILTER is not valid: %v", err)
+		}
+	}
+	return newFilterChain(interfacesFilterGlob, stateFilter), nil
+}
+
+// CompileInterfacesFilter compiles the glob of the interfaces that are not
+// reported, the format of INTERFACES_FILTER
+func CompileInterfacesFilter(interfacesFilter string) (glob.Glob, error) {
+	return glob.Compile(interfacesFilter)
+}
+
+// ValidateStateFilter checks the state filter configured at the NMState CR
+// before it reaches the handlers
+func ValidateStateFilter(stateFilter shared.NodeNetworkStateFilter) error {
+	for _, path := range stateFilter.Fields {
+		if err := validateFieldPath(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filter drops from the state the interfaces, routes or fields that should
+// not be reported
+type filter interface {
+	filterOut(state *rootState)
+}
+
+// filterChain applies the filters in order, so the routes of the interfaces
+// dropped by a filter can be dropped by the following ones
+type filterChain []filter
+
+func (c filterChain) filterOut(state *rootState) {
+	for _, f := range c {
+		f.filterOut(state)
+	}
+}
+
+// newFilterChain returns the interfaces name filter, the builtin dynamic
+// attributes filter and the filters configured at stateFilter
+func newFilterChain(interfacesFilterGlob glob.Glob, stateFilter shared.NodeNetworkStateFilter) filterChain {
+	chain := filterChain{interfacesNameFilter{interfacesFilterGlob}}
+	if len(stateFilter.InterfaceTypes) > 0 {
+		chain = append(chain, newInterfaceTypesFilter(stateFilter.InterfaceTypes))
 	}
-	interfacesFilterGlobFromEnv = glob.MustCompile(interfacesFilter)
+	if len(stateFilter.RouteTables) > 0 {
+		chain = append(chain, newRouteTablesFilter(stateFilter.RouteTables))
+	}
+	if len(stateFilter.RouteProtocols) > 0 {
+		chain = append(chain, newRouteProtocolsFilter(stateFilter.RouteProtocols))
+	}
+	if stateFilter.DynamicAddresses {
+		chain = append(chain, dynamicAddressesFilter{})
+	}
+	chain = append(chain, dynamicAttributesFilter{})
+	if len(stateFilter.Fields) > 0 {
+		chain = append(chain, newFieldsFilter(stateFilter.Fields))
+	}
+	return chain
 }
 
 func FilterOut(currentState shared.State) (shared.State, error) {
-	return filterOut(currentState, interfacesFilterGlobFromEnv)
+	if filtersFromEnvErr != nil {
+		return shared.State{}, filtersFromEnvErr
+	}
+	return filterOut(currentState, filtersFromEnv)
 }
 
-func filterOutRoutes(routes []interface{}, interfacesFilterGlob glob.Glob) []interface{} {
-	filteredRoutes := []interface{}{}
-	for _, route := range routes {
-		name := route.(map[string]interface{})["next-hop-interface"]
-		if !interfacesFilterGlob.Match(name.(string)) {
-			filteredRoutes = append(filteredRoutes, route)
+// interfacesNameFilter drops the interfaces matching the glob and the
+// routes using them as next hop
+type interfacesNameFilter struct {
+	glob glob.Glob
+}
+
+func (f interfacesNameFilter) filterOut(state *rootState) {
+	filterOutInterfaces(state, func(iface interfaceState) bool {
+		return f.glob.Match(iface.Name)
+	})
+	filterOutRoutes(state, func(route map[string]interface{}) bool {
+		name, ok := route["next-hop-interface"].(string)
+		return ok && f.glob.Match(name)
+	})
+}
+
+// interfaceTypesFilter drops the interfaces of the types and the routes
+// using them as next hop
+type interfaceTypesFilter struct {
+	types map[string]struct{}
+}
+
+func newInterfaceTypesFilter(types []string) interfaceTypesFilter {
+	f := interfaceTypesFilter{types: map[string]struct{}{}}
+	for _, t := range types {
+		f.types[t] = struct{}{}
+	}
+	return f
+}
+
+func (f interfaceTypesFilter) filterOut(state *rootState) {
+	dropped := map[string]struct{}{}
+	filterOutInterfaces(state, func(iface interfaceState) bool {
+		ifaceType, _ := iface.Data["type"].(string)
+		if _, drop := f.types[ifaceType]; drop {
+			dropped[iface.Name] = struct{}{}
+			return true
 		}
+		return false
+	})
+	filterOutRoutes(state, func(route map[string]interface{}) bool {
+		name, _ := route["next-hop-interface"].(string)
+		_, drop := dropped[name]
+		return drop
+	})
+}
+
+// routeTablesFilter drops the routes of the tables
+type routeTablesFilter struct {
+	tables map[int]struct{}
+}
+
+func newRouteTablesFilter(tables []int) routeTablesFilter {
+	f := routeTablesFilter{tables: map[int]struct{}{}}
+	for _, t := range tables {
+		f.tables[t] = struct{}{}
 	}
+	return f
+}
 
-	return filteredRoutes
+func (f routeTablesFilter) filterOut(state *rootState) {
+	filterOutRoutes(state, func(route map[string]interface{}) bool {
+		// Numbers are unmarshaled as float64
+		tableID, ok := route["table-id"].(float64)
+		if !ok {
+			return false
+		}
+		_, drop := f.tables[int(tableID)]
+		return drop
+	})
+}
+
+// routeProtocolsFilter drops the routes of the protocols
+type routeProtocolsFilter struct {
+	protocols map[string]struct{}
+}
+
+func newRouteProtocolsFilter(protocols []string) routeProtocolsFilter {
+	f := routeProtocolsFilter{protocols: map[string]struct{}{}}
+	for _, p := range protocols {
+		f.protocols[p] = struct{}{}
+	}
+	return f
+}
+
+func (f routeProtocolsFilter) filterOut(state *rootState) {
+	filterOutRoutes(state, func(route map[string]interface{}) bool {
+		protocol, ok := route["protocol"].(string)
+		if !ok {
+			return false
+		}
+		_, drop := f.protocols[protocol]
+		return drop
+	})
+}
+
+// dynamicAttributesFilter drops the attributes that change without any
+// configuration change
+type dynamicAttributesFilter struct{}
+
+func (dynamicAttributesFilter) filterOut(state *rootState) {
+	for _, iface := range state.Interfaces {
+		filterOutDynamicAttributes(iface.Data)
+	}
 }
 
 func filterOutDynamicAttributes(iface map[string]interface{}) {
+	// The DHCP leases and autoconf addresses lifetimes are decreasing
+	// continuously
+	for _, address := range interfaceAddresses(iface) {
+		delete(address, "valid-life-time")
+		delete(address, "preferred-life-time")
+	}
+
 	// The gc-timer and hello-time are deep into linux-bridge like this
 	//    - bridge:
 	//        options:
@@ -74,32 +262,97 @@ func filterOutDynamicAttributes(iface map[string]interface{}) {
 	delete(options, "hello-timer")
 }
 
-func filterOutInterfaces(ifacesState []interfaceState, interfacesFilterGlob glob.Glob) []interfaceState {
+// dynamicAddressesFilter drops the addresses obtained with DHCP or autoconf,
+// nmstate reports a finite lifetime only for them
+type dynamicAddressesFilter struct{}
+
+func (dynamicAddressesFilter) filterOut(state *rootState) {
+	for _, iface := range state.Interfaces {
+		for _, family := range []string{"ipv4", "ipv6"} {
+			ip, ok := iface.Data[family].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			addresses, ok := ip["address"].([]interface{})
+			if !ok {
+				continue
+			}
+			staticAddresses := []interface{}{}
+			for _, addressRaw := range addresses {
+				address, ok := addressRaw.(map[string]interface{})
+				if ok && isDynamicAddress(address) {
+					continue
+				}
+				staticAddresses = append(staticAddresses, addressRaw)
+			}
+			ip["address"] = staticAddresses
+		}
+	}
+}
+
+func isDynamicAddress(address map[string]interface{}) bool {
+	validLifeTime, ok := address["valid-life-time"].(string)
+	return ok && validLifeTime != "forever"
+}
+
+func interfaceAddresses(iface map[string]interface{}) []map[string]interface{} {
+	addresses := []map[string]interface{}{}
+	for _, family := range []string{"ipv4", "ipv6"} {
+		ip, ok := iface[family].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		familyAddresses, ok := ip["address"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, addressRaw := range familyAddresses {
+			if address, ok := addressRaw.(map[string]interface{}); ok {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	return addresses
+}
+
+func filterOutInterfaces(state *rootState, drop func(interfaceState) bool) {
 	filteredInterfaces := []interfaceState{}
-	for _, iface := range ifacesState {
-		if !interfacesFilterGlob.Match(iface.Name) {
-			filterOutDynamicAttributes(iface.Data)
+	for _, iface := range state.Interfaces {
+		if !drop(iface) {
 			filteredInterfaces = append(filteredInterfaces, iface)
 		}
 	}
-	return filteredInterfaces
+	state.Interfaces = filteredInterfaces
 }
 
-func filterOut(currentState shared.State, interfacesFilterGlob glob.Glob) (shared.State, error) {
-	var state rootState
-	if err := yaml.Unmarshal(currentState.Raw, &state); err != nil {
-		return currentState, err
+func filterOutRoutes(state *rootState, drop func(map[string]interface{}) bool) {
+	if state.Routes == nil {
+		return
 	}
+	state.Routes.Running = filterOutRoutesList(state.Routes.Running, drop)
+	state.Routes.Config = filterOutRoutesList(state.Routes.Config, drop)
+}
 
-	if err := normalizeInterfacesNames(currentState.Raw, &state); err != nil {
-		return currentState, err
+func filterOutRoutesList(routes []interface{}, drop func(map[string]interface{}) bool) []interface{} {
+	filteredRoutes := []interface{}{}
+	for _, routeRaw := range routes {
+		route, ok := routeRaw.(map[string]interface{})
+		if ok && drop(route) {
+			continue
+		}
+		filteredRoutes = append(filteredRoutes, routeRaw)
 	}
+	return filteredRoutes
+}
 
-	state.Interfaces = filterOutInterfaces(state.Interfaces, interfacesFilterGlob)
-	if state.Routes != nil {
-		state.Routes.Running = filterOutRoutes(state.Routes.Running, interfacesFilterGlob)
-		state.Routes.Config = filterOutRoutes(state.Routes.Config, interfacesFilterGlob)
+func filterOut(currentState shared.State, filters filterChain) (shared.State, error) {
+	state, err := unmarshalState(currentState)
+	if err != nil {
+		return currentState, err
 	}
+
+	filters.filterOut(&state)
+
 	filteredState, err := yaml.Marshal(state)
 	if err != nil {
 		return currentState, err
@@ -108,6 +361,18 @@ func filterOut(currentState shared.State, interfacesFilterGlob glob.Glob) (share
 	return shared.NewState(string(filteredState)), nil
 }
 
+func unmarshalState(currentState shared.State) (rootState, error) {
+	var state rootState
+	if err := yaml.Unmarshal(currentState.Raw, &state); err != nil {
+		return state, err
+	}
+
+	if err := normalizeInterfacesNames(currentState.Raw, &state); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
 // normalizeInterfacesNames fixes the unmarshal of numeric values in the interfaces names
 // Numeric values, including the ones with a base prefix (e.g. 0x123) should be stringify.
 func normalizeInterfacesNames(rawState []byte, state *rootState) error {
@@ -1,6 +1,8 @@
 package state
 
 import (
+	"os"
+
 	"github.com/gobwas/glob"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -114,7 +116,7 @@ routes:
 			interfacesFilterGlob = glob.MustCompile("")
 		})
 		It("should remove them from linux-bridge", func() {
-			returnedState, err := filterOut(state, interfacesFilterGlob)
+			returnedState, err := filterOut(state, newFilterChain(interfacesFilterGlob, nmstate.NodeNetworkStateFilter{}))
 			Expect(err).ToNot(HaveOccurred())
 			Expect(returnedState).To(MatchYAML(filteredState))
 		})
@@ -147,7 +149,7 @@ routes:
 		})
 
 		It("should keep all interfaces intact", func() {
-			returnedState, err := filterOut(state, interfacesFilterGlob)
+			returnedState, err := filterOut(state, newFilterChain(interfacesFilterGlob, nmstate.NodeNetworkStateFilter{}))
 			Expect(err).ToNot(HaveOccurred())
 			Expect(returnedState).To(MatchYAML(state))
 		})
@@ -194,7 +196,7 @@ routes:
 		})
 
 		It("should filter out matching interface and keep the others", func() {
-			returnedState, err := filterOut(state, interfacesFilterGlob)
+			returnedState, err := filterOut(state, newFilterChain(interfacesFilterGlob, nmstate.NodeNetworkStateFilter{}))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(returnedState).To(MatchYAML(filteredState))
 		})
@@ -248,7 +250,7 @@ routes:
 		})
 
 		It("should filter out all matching interfaces and keep the others", func() {
-			returnedState, err := filterOut(state, interfacesFilterGlob)
+			returnedState, err := filterOut(state, newFilterChain(interfacesFilterGlob, nmstate.NodeNetworkStateFilter{}))
 			Expect(err).ToNot(HaveOccurred())
 			Expect(returnedState).To(MatchYAML(filteredState))
 		})
@@ -302,7 +304,7 @@ routes:
 		})
 
 		It("it should filter out all interfaces matching any of these prefixes and keep the others", func() {
-			returnedState, err := filterOut(state, interfacesFilterGlob)
+			returnedState, err := filterOut(state, newFilterChain(interfacesFilterGlob, nmstate.NodeNetworkStateFilter{}))
 			Expect(err).ToNot(HaveOccurred())
 			Expect(returnedState).To(MatchYAML(filteredState))
 		})
@@ -325,7 +327,7 @@ interfaces:
 			interfacesFilterGlob = glob.MustCompile("")
 		})
 		It("should keep the bridge as it is", func() {
-			returnedState, err := filterOut(state, interfacesFilterGlob)
+			returnedState, err := filterOut(state, newFilterChain(interfacesFilterGlob, nmstate.NodeNetworkStateFilter{}))
 			Expect(err).ToNot(HaveOccurred())
 			Expect(returnedState).To(MatchYAML(filteredState))
 		})
@@ -354,7 +356,7 @@ interfaces:
 		})
 
 		It("should filter out interfaces correctly", func() {
-			returnedState, err := filterOut(state, interfacesFilterGlob)
+			returnedState, err := filterOut(state, newFilterChain(interfacesFilterGlob, nmstate.NodeNetworkStateFilter{}))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(returnedState).To(MatchYAML(filteredState))
 		})
@@ -378,9 +380,303 @@ interfaces:
 		})
 
 		It("does not filter out interfaces correctly and does not represent them correctly", func() {
-			returnedState, err := filterOut(state, interfacesFilterGlob)
+			returnedState, err := filterOut(state, newFilterChain(interfacesFilterGlob, nmstate.NodeNetworkStateFilter{}))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(returnedState).To(MatchYAML(filteredState))
 		})
 	})
+
+	Context("when the state has dynamic addresses", func() {
+		BeforeEach(func() {
+			state = nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  ipv4:
+    dhcp: true
+    enabled: true
+    address:
+    - ip: 192.168.66.101
+      prefix-length: 24
+      valid-life-time: 3421sec
+      preferred-life-time: 3421sec
+  ipv6:
+    autoconf: true
+    dhcp: true
+    enabled: true
+    address:
+    - ip: 2001:db8::1234
+      prefix-length: 64
+      valid-life-time: 86251sec
+      preferred-life-time: 14251sec
+    - ip: fe80::1
+      prefix-length: 64
+      valid-life-time: forever
+      preferred-life-time: forever
+`)
+		})
+		It("should remove the addresses lifetimes", func() {
+			returnedState, err := filterOut(state, newFilterChain(glob.MustCompile(""), nmstate.NodeNetworkStateFilter{}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(returnedState).To(MatchYAML(nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  ipv4:
+    dhcp: true
+    enabled: true
+    address:
+    - ip: 192.168.66.101
+      prefix-length: 24
+  ipv6:
+    autoconf: true
+    dhcp: true
+    enabled: true
+    address:
+    - ip: 2001:db8::1234
+      prefix-length: 64
+    - ip: fe80::1
+      prefix-length: 64
+`)))
+		})
+		It("should remove the dynamic addresses if configured", func() {
+			returnedState, err := filterOut(state, newFilterChain(glob.MustCompile(""), nmstate.NodeNetworkStateFilter{DynamicAddresses: true}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(returnedState).To(MatchYAML(nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  ipv4:
+    dhcp: true
+    enabled: true
+    address: []
+  ipv6:
+    autoconf: true
+    dhcp: true
+    enabled: true
+    address:
+    - ip: fe80::1
+      prefix-length: 64
+`)))
+		})
+	})
+
+	Context("when the state filter is configured", func() {
+		BeforeEach(func() {
+			state = nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  ethtool:
+    feature:
+      rx-checksum: true
+- name: ovs0
+  type: ovs-interface
+  ethtool:
+    feature:
+      rx-checksum: true
+- name: br-ex
+  type: ovs-bridge
+  ovs-db:
+    external_ids:
+      statistics: 1234
+routes:
+  config: []
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.66.2
+    next-hop-interface: eth1
+    table-id: 254
+  - destination: 10.0.0.0/24
+    next-hop-address: 10.0.0.1
+    next-hop-interface: ovs0
+    table-id: 254
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.66.2
+    next-hop-interface: eth1
+    table-id: 100
+`)
+		})
+		It("should remove the interfaces of the types and their routes", func() {
+			returnedState, err := filterOut(state, newFilterChain(glob.MustCompile(""), nmstate.NodeNetworkStateFilter{
+				InterfaceTypes: []string{"ovs-interface"},
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(returnedState).To(MatchYAML(nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  ethtool:
+    feature:
+      rx-checksum: true
+- name: br-ex
+  type: ovs-bridge
+  ovs-db:
+    external_ids:
+      statistics: 1234
+routes:
+  config: []
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.66.2
+    next-hop-interface: eth1
+    table-id: 254
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.66.2
+    next-hop-interface: eth1
+    table-id: 100
+`)))
+		})
+		It("should remove the routes of the tables", func() {
+			returnedState, err := filterOut(state, newFilterChain(glob.MustCompile(""), nmstate.NodeNetworkStateFilter{
+				RouteTables: []int{254},
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(returnedState).To(MatchYAML(nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  ethtool:
+    feature:
+      rx-checksum: true
+- name: ovs0
+  type: ovs-interface
+  ethtool:
+    feature:
+      rx-checksum: true
+- name: br-ex
+  type: ovs-bridge
+  ovs-db:
+    external_ids:
+      statistics: 1234
+routes:
+  config: []
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.66.2
+    next-hop-interface: eth1
+    table-id: 100
+`)))
+		})
+		It("should remove the routes of the protocols", func() {
+			state = nmstate.NewState(`
+interfaces: []
+routes:
+  config: []
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.66.2
+    next-hop-interface: eth1
+    protocol: dhcp
+  - destination: 10.0.0.0/24
+    next-hop-address: 10.0.0.1
+    next-hop-interface: eth1
+    protocol: bgp
+  - destination: 10.1.0.0/24
+    next-hop-address: 10.0.0.1
+    next-hop-interface: eth1
+`)
+			returnedState, err := filterOut(state, newFilterChain(glob.MustCompile(""), nmstate.NodeNetworkStateFilter{
+				RouteProtocols: []string{"bgp"},
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(returnedState).To(MatchYAML(nmstate.NewState(`
+interfaces: []
+routes:
+  config: []
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.66.2
+    next-hop-interface: eth1
+    protocol: dhcp
+  - destination: 10.1.0.0/24
+    next-hop-address: 10.0.0.1
+    next-hop-interface: eth1
+`)))
+		})
+		It("should remove the fields at the paths", func() {
+			returnedState, err := filterOut(state, newFilterChain(glob.MustCompile(""), nmstate.NodeNetworkStateFilter{
+				Fields: []string{"interfaces.*.ethtool", "interfaces.*.ovs-db.external_ids.statistics", "routes.running.*.next-hop-address"},
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(returnedState).To(MatchYAML(nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+- name: ovs0
+  type: ovs-interface
+- name: br-ex
+  type: ovs-bridge
+  ovs-db:
+    external_ids: {}
+routes:
+  config: []
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth1
+    table-id: 254
+  - destination: 10.0.0.0/24
+    next-hop-interface: ovs0
+    table-id: 254
+  - destination: 0.0.0.0/0
+    next-hop-interface: eth1
+    table-id: 100
+`)))
+		})
+	})
+})
+
+var _ = Describe("filtersFromEnvironment", func() {
+	var (
+		interfacesFilter, stateFilter string
+	)
+	BeforeEach(func() {
+		interfacesFilter = DefaultInterfacesFilter
+		stateFilter = ""
+	})
+	JustBeforeEach(func() {
+		os.Setenv("INTERFACES_FILTER", interfacesFilter)
+		os.Setenv("STATE_FILTER", stateFilter)
+	})
+	AfterEach(func() {
+		os.Unsetenv("INTERFACES_FILTER")
+		os.Unsetenv("STATE_FILTER")
+	})
+	Context("when the filters are valid", func() {
+		BeforeEach(func() {
+			stateFilter = `{"interfaceTypes":["ovs-interface"]}`
+		})
+		It("should build the filter chain", func() {
+			chain, err := filtersFromEnvironment()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chain).ToNot(BeEmpty())
+		})
+	})
+	Context("when the interfaces filter is not a valid glob", func() {
+		BeforeEach(func() {
+			interfacesFilter = "veth["
+		})
+		It("should return an error instead of panicking", func() {
+			_, err := filtersFromEnvironment()
+			Expect(err).To(MatchError(ContainSubstring("INTERFACES_FILTER is not valid")))
+		})
+	})
+	Context("when the state filter has fields out of the reported state", func() {
+		BeforeEach(func() {
+			stateFilter = `{"fields":["interfaces.*.ethtool","dns-resolver.running"]}`
+		})
+		It("should return an error", func() {
+			_, err := filtersFromEnvironment()
+			Expect(err).To(MatchError(`STATE_FILTER is not valid: invalid field path "dns-resolver.running": has to start with one of interfaces, routes`))
+		})
+	})
+	Context("when the state filter is not valid JSON", func() {
+		BeforeEach(func() {
+			stateFilter = `{"interfaceTypes":`
+		})
+		It("should return an error instead of panicking", func() {
+			_, err := filtersFromEnvironment()
+			Expect(err).To(MatchError(ContainSubstring("STATE_FILTER is not valid")))
+		})
+	})
 })
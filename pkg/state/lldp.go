@@ -0,0 +1,108 @@
+package state
+
+import (
+	"strconv"
+
+	"github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+// LLDPNeighbors returns the neighbors nmstate reports at the interfaces with
+// LLDP enabled, currentState has to be the unfiltered one. nmstate reports
+// every neighbor as a list of TLVs like this
+//
+//	lldp:
+//	  enabled: true
+//	  neighbors:
+//	  - - type: 1
+//	      chassis-id: 00:01:30:F9:AD:A0
+//	    - type: 2
+//	      port-id: 1/1
+//	    - type: 127
+//	      ieee-802-1-vlans:
+//	      - name: v2-0488-03-0505
+//	        vid: 488
+func LLDPNeighbors(currentState shared.State) ([]shared.LLDPNeighbor, error) {
+	state, err := unmarshalState(currentState)
+	if err != nil {
+		return nil, err
+	}
+
+	neighbors := []shared.LLDPNeighbor{}
+	for _, iface := range state.Interfaces {
+		lldp, ok := iface.Data["lldp"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ifaceNeighbors, ok := lldp["neighbors"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, tlvsRaw := range ifaceNeighbors {
+			tlvs, ok := tlvsRaw.([]interface{})
+			if !ok {
+				continue
+			}
+			neighbors = append(neighbors, lldpNeighbor(iface.Name, tlvs))
+		}
+	}
+	if len(neighbors) == 0 {
+		return nil, nil
+	}
+	return neighbors, nil
+}
+
+func lldpNeighbor(ifaceName string, tlvs []interface{}) shared.LLDPNeighbor {
+	neighbor := shared.LLDPNeighbor{Interface: ifaceName}
+	for _, tlvRaw := range tlvs {
+		tlv, ok := tlvRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if chassisID, ok := tlvString(tlv, "chassis-id"); ok {
+			neighbor.ChassisID = chassisID
+		}
+		if portID, ok := tlvString(tlv, "port-id"); ok {
+			neighbor.PortID = portID
+		}
+		if portDescription, ok := tlvString(tlv, "port-description"); ok {
+			neighbor.PortDescription = portDescription
+		}
+		if systemName, ok := tlvString(tlv, "system-name"); ok {
+			neighbor.SystemName = systemName
+		}
+		if vlans, ok := tlv["ieee-802-1-vlans"].([]interface{}); ok {
+			neighbor.VLANs = append(neighbor.VLANs, lldpNeighborVLANs(vlans)...)
+		}
+	}
+	return neighbor
+}
+
+// tlvString returns the TLV value at key, numeric ones like some port ids
+// are unmarshaled as float64
+func tlvString(tlv map[string]interface{}, key string) (string, bool) {
+	switch value := tlv[key].(type) {
+	case string:
+		return value, true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+func lldpNeighborVLANs(vlans []interface{}) []shared.LLDPNeighborVLAN {
+	neighborVLANs := []shared.LLDPNeighborVLAN{}
+	for _, vlanRaw := range vlans {
+		vlan, ok := vlanRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// Numbers are unmarshaled as float64
+		vid, ok := vlan["vid"].(float64)
+		if !ok {
+			continue
+		}
+		name, _ := vlan["name"].(string)
+		neighborVLANs = append(neighborVLANs, shared.LLDPNeighborVLAN{ID: int(vid), Name: name})
+	}
+	return neighborVLANs
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	nmstate "github.com/nmstate/kubernetes-nmstate/api/shared"
+)
+
+var _ = Describe("LLDPNeighbors", func() {
+	var (
+		state nmstate.State
+	)
+
+	Context("when there are no interfaces with LLDP neighbors", func() {
+		BeforeEach(func() {
+			state = nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  lldp:
+    enabled: false
+- name: eth2
+  type: ethernet
+`)
+		})
+		It("should return no neighbors", func() {
+			neighbors, err := LLDPNeighbors(state)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(neighbors).To(BeNil())
+		})
+	})
+
+	Context("when there are interfaces with LLDP neighbors", func() {
+		BeforeEach(func() {
+			state = nmstate.NewState(`
+interfaces:
+- name: eth1
+  type: ethernet
+  lldp:
+    enabled: true
+    neighbors:
+    - - chassis-id: 00:01:30:F9:AD:A0
+        chassis-id-type: 4
+        type: 1
+      - port-id: 1/1
+        port-id-type: 5
+        type: 2
+      - system-name: summit300-48
+        type: 5
+      - port-description: Summit300-48-Port 1001
+        type: 4
+      - ieee-802-1-vlans:
+        - name: v2-0488-03-0505
+          vid: 488
+        - vid: 489
+        oui: 00:80:c2
+        subtype: 3
+        type: 127
+      - ieee-802-3-max-frame-size: 1522
+        oui: 00:12:0f
+        subtype: 4
+        type: 127
+- name: eth2
+  type: ethernet
+  lldp:
+    enabled: true
+    neighbors:
+    - - chassis-id: 00:01:30:F9:AD:A1
+        type: 1
+      - port-id: 12
+        type: 2
+`)
+		})
+		It("should return the neighbors of every interface", func() {
+			neighbors, err := LLDPNeighbors(state)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(neighbors).To(Equal([]nmstate.LLDPNeighbor{
+				{
+					Interface:       "eth1",
+					ChassisID:       "00:01:30:F9:AD:A0",
+					PortID:          "1/1",
+					PortDescription: "Summit300-48-Port 1001",
+					SystemName:      "summit300-48",
+					VLANs: []nmstate.LLDPNeighborVLAN{
+						{ID: 488, Name: "v2-0488-03-0505"},
+						{ID: 489},
+					},
+				},
+				{
+					Interface: "eth2",
+					ChassisID: "00:01:30:F9:AD:A1",
+					PortID:    "12",
+				},
+			}))
+		})
+	})
+})
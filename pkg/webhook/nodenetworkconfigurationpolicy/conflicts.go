@@ -0,0 +1,387 @@
+package nodenetworkconfigurationpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	yaml "sigs.k8s.io/yaml"
+
+	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/cleanup"
+	"github.com/nmstate/kubernetes-nmstate/pkg/policytemplate"
+	"github.com/nmstate/kubernetes-nmstate/pkg/selectors"
+)
+
+const templateMarker = "{{"
+
+// settings contains the leaf values of a desiredState entry indexed by
+// their path, lists are leaves so they are compared as a whole.
+type settings map[string]string
+
+// policyState is the part of a desiredState that can conflict with other
+// policies: interfaces by name, routes by destination, next hop and table
+// and the DNS configuration.
+type policyState struct {
+	interfaces map[string]indexedSettings
+	routes     map[string]indexedSettings
+	dns        settings
+}
+
+type indexedSettings struct {
+	index    int
+	settings settings
+}
+
+type conflict struct {
+	field   string
+	message string
+}
+
+// validatePolicyConflicts denies policies with interface, route or DNS
+// settings that contradict the ones from other policies matching some of
+// the same nodes. Templated desired states are compared after rendering them
+// at each node. Updates are only warned about the conflicts that the policy
+// already had, so it can still be changed while they are solved. Failing to
+// list the nodes or the policies is only warned about, so an API server
+// hiccup does not block policy updates.
+func validatePolicyConflicts(cli client.Reader) validator {
+	return func(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		causes := []metav1.StatusCause{}
+
+		// Pausing or resuming does not change the policy configuration
+		if onlyPausedChanged(policy, currentPolicy) {
+			return causes
+		}
+
+		// Malformed desired states are not a conflict, nmstate will report them
+		state, err := newPolicyState(policy.Spec.DesiredState)
+		if err != nil || (state.isEmpty() && !policytemplate.IsTemplate(policy.Spec.DesiredState)) {
+			return causes
+		}
+
+		nodes := corev1.NodeList{}
+		err = cli.List(context.TODO(), &nodes)
+		if err != nil {
+			return append(causes, metav1.StatusCause{
+				Type:    causeTypeWarning,
+				Message: fmt.Sprintf("failed listing nodes to check policy conflicts: %v", err),
+			})
+		}
+		policyNodes, err := matchingNodes(policy, nodes.Items)
+		if err != nil || len(policyNodes) == 0 {
+			return causes
+		}
+
+		policies := nmstatev1beta1.NodeNetworkConfigurationPolicyList{}
+		err = cli.List(context.TODO(), &policies)
+		if err != nil {
+			return append(causes, metav1.StatusCause{
+				Type:    causeTypeWarning,
+				Message: fmt.Sprintf("failed listing policies to check policy conflicts: %v", err),
+			})
+		}
+
+		states := newNodeStates(cli)
+		for _, otherPolicy := range policies.Items {
+			// Policies being deleted or dry run do not keep configuration at nodes
+			if otherPolicy.Name == policy.Name || !otherPolicy.DeletionTimestamp.IsZero() || otherPolicy.Spec.DryRun {
+				continue
+			}
+			otherPolicyNodes, err := matchingNodes(otherPolicy, policyNodes)
+			if err != nil || len(otherPolicyNodes) == 0 {
+				continue
+			}
+
+			// Desired states without templates conflict the same way at
+			// every node, templated ones are compared node by node
+			nodeGroups := [][]corev1.Node{otherPolicyNodes}
+			if policytemplate.IsTemplate(policy.Spec.DesiredState) ||
+				policytemplate.IsTemplate(currentPolicy.Spec.DesiredState) ||
+				policytemplate.IsTemplate(otherPolicy.Spec.DesiredState) {
+				nodeGroups = [][]corev1.Node{}
+				for _, node := range otherPolicyNodes {
+					nodeGroups = append(nodeGroups, []corev1.Node{node})
+				}
+			}
+
+			conflictNodes := map[metav1.StatusCause][]corev1.Node{}
+			conflictOrder := []metav1.StatusCause{}
+			for _, group := range nodeGroups {
+				node := group[0]
+				otherState, err := states.at(otherPolicy, node)
+				if err != nil {
+					continue
+				}
+				state, err := states.at(policy, node)
+				if err != nil {
+					continue
+				}
+				conflicts := state.conflictsWith(otherState)
+				if len(conflicts) == 0 {
+					continue
+				}
+				// The policy before the update, it is empty at creation and
+				// a malformed one has no conflicts to keep
+				existingConflicts := map[string]bool{}
+				if currentState, err := states.at(currentPolicy, node); err == nil {
+					for _, c := range currentState.conflictsWith(otherState) {
+						existingConflicts[c.message] = true
+					}
+				}
+				for _, c := range conflicts {
+					causeType := metav1.CauseTypeFieldValueInvalid
+					if existingConflicts[c.message] {
+						causeType = causeTypeWarning
+					}
+					cause := metav1.StatusCause{Type: causeType, Message: c.message, Field: "spec.desiredState." + c.field}
+					if _, found := conflictNodes[cause]; !found {
+						conflictOrder = append(conflictOrder, cause)
+					}
+					conflictNodes[cause] = append(conflictNodes[cause], group...)
+				}
+			}
+			for _, cause := range conflictOrder {
+				cause.Message = fmt.Sprintf("%s, conflicting with policy %s at nodes %s", cause.Message, otherPolicy.Name, nodeNames(conflictNodes[cause]))
+				causes = append(causes, cause)
+			}
+		}
+		return causes
+	}
+}
+
+// nodeStates parses the policies desired states rendered at the nodes,
+// caching them and the nodes current state used to render them
+type nodeStates struct {
+	cli           client.Reader
+	currentStates map[string]shared.State
+	policyStates  map[string]policyState
+}
+
+func newNodeStates(cli client.Reader) nodeStates {
+	return nodeStates{
+		cli:           cli,
+		currentStates: map[string]shared.State{},
+		policyStates:  map[string]policyState{},
+	}
+}
+
+// at returns the policy state at the node. Desired states that cannot be
+// rendered, for example because the node has no NodeNetworkState yet, are
+// parsed as they are so their templated entries and values are skipped.
+func (s nodeStates) at(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, node corev1.Node) (policyState, error) {
+	desiredState := policy.Spec.DesiredState
+	if !policytemplate.IsTemplate(desiredState) {
+		return newPolicyState(desiredState)
+	}
+	// Keyed by desired state, the policy before and after an update share
+	// the name
+	key := node.Name + "/" + desiredState.String()
+	if state, found := s.policyStates[key]; found {
+		return state, nil
+	}
+	currentState, found := s.currentStates[node.Name]
+	if !found {
+		nns := nmstatev1beta1.NodeNetworkState{}
+		err := s.cli.Get(context.TODO(), types.NamespacedName{Name: node.Name}, &nns)
+		if err == nil {
+			currentState = nns.Status.CurrentState
+		}
+		s.currentStates[node.Name] = currentState
+	}
+	rendered, err := policytemplate.Render(desiredState, node, currentState)
+	if err != nil {
+		rendered = desiredState
+	}
+	state, err := newPolicyState(rendered)
+	if err != nil {
+		return state, err
+	}
+	s.policyStates[key] = state
+	return state, nil
+}
+
+// matchingNodes returns the nodes matching the policy node selectors.
+func matchingNodes(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, nodes []corev1.Node) ([]corev1.Node, error) {
+	nodeSelector, err := selectors.NodeSelector(policy)
+	if err != nil {
+		return nil, err
+	}
+	matching := []corev1.Node{}
+	for _, node := range nodes {
+		if nodeSelector.Matches(labels.Set(node.Labels)) {
+			matching = append(matching, node)
+		}
+	}
+	return matching, nil
+}
+
+func nodeNames(nodes []corev1.Node) string {
+	names := []string{}
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func newPolicyState(desiredState shared.State) (policyState, error) {
+	state := policyState{
+		interfaces: map[string]indexedSettings{},
+		routes:     map[string]indexedSettings{},
+		dns:        settings{},
+	}
+	if len(desiredState.Raw) == 0 {
+		return state, nil
+	}
+	desired := map[string]interface{}{}
+	err := yaml.Unmarshal(desiredState.Raw, &desired)
+	if err != nil {
+		return state, err
+	}
+
+	if interfaces, ok := desired["interfaces"].([]interface{}); ok {
+		for i, iface := range interfaces {
+			ifaceMap, ok := iface.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, ok := interfaceName(ifaceMap["name"])
+			if !ok || strings.Contains(name, templateMarker) {
+				continue
+			}
+			state.interfaces[name] = indexedSettings{index: i, settings: flatten(ifaceMap)}
+		}
+	}
+
+	if routes, ok := desired["routes"].(map[string]interface{}); ok {
+		if config, ok := routes["config"].([]interface{}); ok {
+			for i, route := range config {
+				routeMap, ok := route.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				key := cleanup.RouteKey(routeMap)
+				if strings.Contains(key, templateMarker) {
+					continue
+				}
+				routeSettings := flatten(routeMap)
+				// Routes are added unless state is absent and they go to the
+				// main table by default, both have to be compared
+				if _, found := routeSettings["state"]; !found {
+					routeSettings["state"] = "present"
+				}
+				delete(routeSettings, "table-id")
+				state.routes[key] = indexedSettings{index: i, settings: routeSettings}
+			}
+		}
+	}
+
+	if dns, ok := desired["dns-resolver"].(map[string]interface{}); ok {
+		if config, ok := dns["config"].(map[string]interface{}); ok {
+			state.dns = flatten(config)
+		}
+	}
+	return state, nil
+}
+
+func (s policyState) isEmpty() bool {
+	return len(s.interfaces) == 0 && len(s.routes) == 0 && len(s.dns) == 0
+}
+
+func (s policyState) conflictsWith(other policyState) []conflict {
+	conflicts := []conflict{}
+	for _, name := range sortedKeys(s.interfaces) {
+		otherIface, found := other.interfaces[name]
+		if !found {
+			continue
+		}
+		iface := s.interfaces[name]
+		for _, path := range iface.settings.differentFrom(otherIface.settings) {
+			conflicts = append(conflicts, conflict{
+				field: fmt.Sprintf("interfaces[%d].%s", iface.index, path),
+				message: fmt.Sprintf("interface %s %s is %s but it is %s at the other policy",
+					name, path, iface.settings[path], otherIface.settings[path]),
+			})
+		}
+	}
+	for _, key := range sortedKeys(s.routes) {
+		otherRoute, found := other.routes[key]
+		if !found {
+			continue
+		}
+		route := s.routes[key]
+		for _, path := range route.settings.differentFrom(otherRoute.settings) {
+			conflicts = append(conflicts, conflict{
+				field: fmt.Sprintf("routes.config[%d].%s", route.index, path),
+				message: fmt.Sprintf("route to %s via %s %s is %s but it is %s at the other policy",
+					route.settings["destination"], route.settings["next-hop-interface"], path, route.settings[path], otherRoute.settings[path]),
+			})
+		}
+	}
+	for _, path := range s.dns.differentFrom(other.dns) {
+		conflicts = append(conflicts, conflict{
+			field:   fmt.Sprintf("dns-resolver.config.%s", path),
+			message: fmt.Sprintf("dns-resolver %s is %s but it is %s at the other policy", path, s.dns[path], other.dns[path]),
+		})
+	}
+	return conflicts
+}
+
+// differentFrom returns the sorted paths present at both settings with
+// different values, values rendered per node are not compared.
+func (s settings) differentFrom(other settings) []string {
+	paths := []string{}
+	for path, value := range s {
+		otherValue, found := other[path]
+		if !found || otherValue == value {
+			continue
+		}
+		if strings.Contains(value, templateMarker) || strings.Contains(otherValue, templateMarker) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func flatten(value map[string]interface{}) settings {
+	flattened := settings{}
+	flattenInto(flattened, "", value)
+	return flattened
+}
+
+func flattenInto(flattened settings, path string, value interface{}) {
+	if valueMap, ok := value.(map[string]interface{}); ok {
+		for key, child := range valueMap {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenInto(flattened, childPath, child)
+		}
+		return
+	}
+	leaf, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	flattened[path] = strings.Trim(string(leaf), "\"")
+}
+
+func sortedKeys(m map[string]indexedSettings) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,346 @@
+package nodenetworkconfigurationpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+func conflictNode(name string, labels map[string]string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+func conflictPolicy(name string, nodeSelector map[string]string, desiredState string) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	return nmstatev1beta1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: shared.NodeNetworkConfigurationPolicySpec{
+			NodeSelector: nodeSelector,
+			DesiredState: shared.NewState(desiredState),
+		},
+	}
+}
+
+func dryRun(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	policy.Spec.DryRun = true
+	return policy
+}
+
+var _ = Describe("NNCP conflicts validation", func() {
+	var (
+		nodes = []corev1.Node{
+			conflictNode("node01", map[string]string{"role": "worker", "zone": "a"}),
+			conflictNode("node02", map[string]string{"role": "worker", "zone": "b"}),
+		}
+		zoneA  = map[string]string{"zone": "a"}
+		zoneB  = map[string]string{"zone": "b"}
+		worker = map[string]string{"role": "worker"}
+	)
+
+	const (
+		eth1Address = `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - ip: 10.244.0.1
+      prefix-length: 24
+`
+		eth1OtherAddress = `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - ip: 10.244.0.2
+      prefix-length: 24
+`
+		eth1TemplateAddress = `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - ip: "{{ .Node.Labels.ip }}"
+      prefix-length: 24
+`
+		eth2Address = `
+interfaces:
+- name: eth2
+  type: ethernet
+  state: up
+`
+		eth2AndEth1Address = `
+interfaces:
+- name: eth2
+  type: ethernet
+  state: up
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - ip: 10.244.0.1
+      prefix-length: 24
+`
+		eth1AddressAndMTU = `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 1500
+  ipv4:
+    enabled: true
+    address:
+    - ip: 10.244.0.1
+      prefix-length: 24
+`
+		eth1OtherAddressAndMTU = `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  mtu: 9000
+  ipv4:
+    enabled: true
+    address:
+    - ip: 10.244.0.2
+      prefix-length: 24
+`
+		route = `
+routes:
+  config:
+  - destination: 198.51.100.0/24
+    next-hop-address: 192.0.2.1
+    next-hop-interface: eth1
+`
+		absentRoute = `
+routes:
+  config:
+  - destination: 198.51.100.0/24
+    next-hop-address: 192.0.2.1
+    next-hop-interface: eth1
+    table-id: 254
+    state: absent
+`
+		templatedRoute = `
+routes:
+  config:
+  - destination: 198.51.100.0/24
+    next-hop-address: 192.0.2.1
+    next-hop-interface: "eth-{{ .Node.Labels.zone }}"
+`
+		dns = `
+dns-resolver:
+  config:
+    server:
+    - 8.8.8.8
+`
+		otherDNS = `
+dns-resolver:
+  config:
+    server:
+    - 1.1.1.1
+`
+	)
+
+	type ConflictsCase struct {
+		policy        nmstatev1beta1.NodeNetworkConfigurationPolicy
+		currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy
+		otherPolicies []nmstatev1beta1.NodeNetworkConfigurationPolicy
+		causes        []metav1.StatusCause
+	}
+	DescribeTable("the NNCP desired state", func(c ConflictsCase) {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationPolicy{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyList{},
+		)
+		objs := []runtime.Object{}
+		for i := range nodes {
+			objs = append(objs, &nodes[i])
+		}
+		for i := range c.otherPolicies {
+			objs = append(objs, &c.otherPolicies[i])
+		}
+		cli := fake.NewFakeClientWithScheme(s, objs...)
+		Expect(validatePolicyConflicts(cli)(c.policy, c.currentPolicy)).To(Equal(c.causes))
+	},
+		Entry("without other policies", ConflictsCase{
+			policy: conflictPolicy("policy", worker, eth1Address),
+			causes: []metav1.StatusCause{},
+		}),
+		Entry("with different interfaces", ConflictsCase{
+			policy:        conflictPolicy("policy", worker, eth1Address),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{conflictPolicy("other", worker, eth2Address)},
+			causes:        []metav1.StatusCause{},
+		}),
+		Entry("with same interface settings", ConflictsCase{
+			policy:        conflictPolicy("policy", worker, eth1Address),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{conflictPolicy("other", worker, eth1Address)},
+			causes:        []metav1.StatusCause{},
+		}),
+		Entry("with different interface address at different nodes", ConflictsCase{
+			policy:        conflictPolicy("policy", zoneA, eth1Address),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{conflictPolicy("other", zoneB, eth1OtherAddress)},
+			causes:        []metav1.StatusCause{},
+		}),
+		Entry("with different interface address at same nodes", ConflictsCase{
+			policy:        conflictPolicy("policy", zoneA, eth1Address),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{conflictPolicy("other", worker, eth1OtherAddress)},
+			causes: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `interface eth1 ipv4.address is [{"ip":"10.244.0.1","prefix-length":24}] but it is [{"ip":"10.244.0.2","prefix-length":24}] at the other policy, conflicting with policy other at nodes node01`,
+					Field:   "spec.desiredState.interfaces[0].ipv4.address",
+				},
+			},
+		}),
+		Entry("with different interface address at a dry run policy", ConflictsCase{
+			policy:        conflictPolicy("policy", worker, eth1Address),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{dryRun(conflictPolicy("other", worker, eth1OtherAddress))},
+			causes:        []metav1.StatusCause{},
+		}),
+		Entry("with interface address rendered per node", ConflictsCase{
+			policy:        conflictPolicy("policy", worker, eth1TemplateAddress),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{conflictPolicy("other", worker, eth1OtherAddress)},
+			causes:        []metav1.StatusCause{},
+		}),
+		Entry("with different interface address when policy is only paused", ConflictsCase{
+			policy:        pause(conflictPolicy("policy", worker, eth1Address)),
+			currentPolicy: conflictPolicy("policy", worker, eth1Address),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{conflictPolicy("other", worker, eth1OtherAddress)},
+			causes:        []metav1.StatusCause{},
+		}),
+		Entry("with different interface address already conflicting before the update", ConflictsCase{
+			policy:        conflictPolicy("policy", worker, eth2AndEth1Address),
+			currentPolicy: conflictPolicy("policy", worker, eth1Address),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{conflictPolicy("other", zoneA, eth1OtherAddress)},
+			causes: []metav1.StatusCause{
+				{
+					Type:    causeTypeWarning,
+					Message: `interface eth1 ipv4.address is [{"ip":"10.244.0.1","prefix-length":24}] but it is [{"ip":"10.244.0.2","prefix-length":24}] at the other policy, conflicting with policy other at nodes node01`,
+					Field:   "spec.desiredState.interfaces[1].ipv4.address",
+				},
+			},
+		}),
+		Entry("with different interface mtu conflicting after the update", ConflictsCase{
+			policy:        conflictPolicy("policy", worker, eth1AddressAndMTU),
+			currentPolicy: conflictPolicy("policy", worker, eth1Address),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{conflictPolicy("other", zoneA, eth1OtherAddressAndMTU)},
+			causes: []metav1.StatusCause{
+				{
+					Type:    causeTypeWarning,
+					Message: `interface eth1 ipv4.address is [{"ip":"10.244.0.1","prefix-length":24}] but it is [{"ip":"10.244.0.2","prefix-length":24}] at the other policy, conflicting with policy other at nodes node01`,
+					Field:   "spec.desiredState.interfaces[0].ipv4.address",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "interface eth1 mtu is 1500 but it is 9000 at the other policy, conflicting with policy other at nodes node01",
+					Field:   "spec.desiredState.interfaces[0].mtu",
+				},
+			},
+		}),
+		Entry("with route removed by the other policy", ConflictsCase{
+			policy:        conflictPolicy("policy", worker, route),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{conflictPolicy("other", worker, absentRoute)},
+			causes: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "route to 198.51.100.0/24 via eth1 state is present but it is absent at the other policy, conflicting with policy other at nodes node01, node02",
+					Field:   "spec.desiredState.routes.config[0].state",
+				},
+			},
+		}),
+		Entry("with templated route removed by the other policy at some nodes", ConflictsCase{
+			policy: conflictPolicy("policy", worker, templatedRoute),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{
+				conflictPolicy("other", worker, strings.Replace(absentRoute, "eth1", "eth-a", 1)),
+			},
+			causes: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "route to 198.51.100.0/24 via eth-a state is present but it is absent at the other policy, conflicting with policy other at nodes node01",
+					Field:   "spec.desiredState.routes.config[0].state",
+				},
+			},
+		}),
+		Entry("with templated route rendered to other interfaces than the absent one", ConflictsCase{
+			policy: conflictPolicy("policy", worker, templatedRoute),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{
+				conflictPolicy("other", worker, strings.Replace(absentRoute, "eth1", "eth-c", 1)),
+			},
+			causes: []metav1.StatusCause{},
+		}),
+		Entry("with different DNS servers", ConflictsCase{
+			policy:        conflictPolicy("policy", worker, dns),
+			otherPolicies: []nmstatev1beta1.NodeNetworkConfigurationPolicy{conflictPolicy("other", zoneB, otherDNS)},
+			causes: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `dns-resolver server is ["8.8.8.8"] but it is ["1.1.1.1"] at the other policy, conflicting with policy other at nodes node02`,
+					Field:   "spec.desiredState.dns-resolver.config.server",
+				},
+			},
+		}),
+	)
+	It("should only warn when the policies cannot be listed", func() {
+		s := runtime.NewScheme()
+		Expect(corev1.AddToScheme(s)).To(Succeed())
+		cli := fake.NewFakeClientWithScheme(s, &nodes[0], &nodes[1])
+		causes := validatePolicyConflicts(cli)(conflictPolicy("policy", worker, eth1Address), nmstatev1beta1.NodeNetworkConfigurationPolicy{})
+		Expect(causes).To(HaveLen(1))
+		Expect(causes[0].Type).To(Equal(causeTypeWarning))
+		Expect(causes[0].Message).To(ContainSubstring("failed listing policies to check policy conflicts"))
+	})
+	It("should allow the update with the conflicts it already had as warnings", func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationPolicy{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyList{},
+		)
+		currentPolicy := conflictPolicy("policy", worker, eth1Address)
+		otherPolicy := conflictPolicy("other", zoneA, eth1OtherAddress)
+		cli := fake.NewFakeClientWithScheme(s, &nodes[0], &nodes[1], &currentPolicy, &otherPolicy)
+
+		policyJSON, err := json.Marshal(conflictPolicy("policy", worker, eth2AndEth1Address))
+		Expect(err).ToNot(HaveOccurred())
+
+		request := webhook.AdmissionRequest{}
+		request.Operation = admissionv1.Update
+		request.Object = runtime.RawExtension{Raw: policyJSON}
+		response := validatePolicyHandler(cli, onPolicySpecChange, validatePolicyConflicts(cli))(context.TODO(), request)
+
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Warnings).To(ConsistOf(`spec.desiredState.interfaces[1].ipv4.address: interface eth1 ipv4.address is [{"ip":"10.244.0.1","prefix-length":24}] but it is [{"ip":"10.244.0.2","prefix-length":24}] at the other policy, conflicting with policy other at nodes node01`))
+	})
+})
@@ -0,0 +1,295 @@
+package nodenetworkconfigurationpolicy
+
+import (
+	_ "embed"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	yaml "sigs.k8s.io/yaml"
+
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+//go:embed nmstate_schema.yaml
+var nmstateSchemaYAML []byte
+
+var nmstateSchema = loadSchema(nmstateSchemaYAML)
+
+// schema is the subset of OpenAPI v3 used by the desiredState whitelist
+type schema struct {
+	Type        string            `json:"type,omitempty"`
+	IntOrString bool              `json:"x-kubernetes-int-or-string,omitempty"`
+	Enum        []string          `json:"enum,omitempty"`
+	Minimum     *int64            `json:"minimum,omitempty"`
+	Maximum     *int64            `json:"maximum,omitempty"`
+	MinLength   *int              `json:"minLength,omitempty"`
+	Required    []string          `json:"required,omitempty"`
+	Properties  map[string]schema `json:"properties,omitempty"`
+	Items       *schema           `json:"items,omitempty"`
+}
+
+// loadSchema decodes the embedded whitelist, it panics if the schema
+// is broken since it is part of the binary.
+func loadSchema(schemaYAML []byte) schema {
+	s := schema{}
+	err := yaml.Unmarshal(schemaYAML, &s)
+	if err != nil {
+		panic(errors.Wrap(err, "failed decoding nmstate schema"))
+	}
+	return s
+}
+
+// validate checks the value and its children against the schema, the
+// properties not present at the schema are not checked.
+func (s schema) validate(fieldPath *field.Path, value interface{}) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if stringValue, ok := value.(string); ok && isTemplate(stringValue) {
+		return allErrs
+	}
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return append(allErrs, field.Invalid(fieldPath, value, "must be an object"))
+		}
+		for _, required := range s.Required {
+			if _, found := object[required]; !found {
+				allErrs = append(allErrs, field.Required(fieldPath.Child(required), ""))
+			}
+		}
+		for _, name := range s.propertyNames() {
+			if propertyValue, found := object[name]; found {
+				allErrs = append(allErrs, s.Properties[name].validate(fieldPath.Child(name), propertyValue)...)
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return append(allErrs, field.Invalid(fieldPath, value, "must be a list"))
+		}
+		if s.Items != nil {
+			for i, item := range array {
+				allErrs = append(allErrs, s.Items.validate(fieldPath.Index(i), item)...)
+			}
+		}
+	case "string":
+		if s.IntOrString {
+			if _, ok := value.(float64); ok {
+				if _, isInteger := interfaceName(value); !isInteger {
+					return append(allErrs, field.Invalid(fieldPath, value, "must be a string or an integer"))
+				}
+				return allErrs
+			}
+		}
+		stringValue, ok := value.(string)
+		if !ok {
+			return append(allErrs, field.Invalid(fieldPath, value, "must be a string"))
+		}
+		if s.MinLength != nil && len(stringValue) < *s.MinLength {
+			allErrs = append(allErrs, field.Invalid(fieldPath, value, fmt.Sprintf("must have at least %d characters", *s.MinLength)))
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, stringValue) {
+			allErrs = append(allErrs, field.NotSupported(fieldPath, value, s.Enum))
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != float64(int64(number)) {
+			return append(allErrs, field.Invalid(fieldPath, value, "must be an integer"))
+		}
+		if s.Minimum != nil && int64(number) < *s.Minimum {
+			allErrs = append(allErrs, field.Invalid(fieldPath, value, fmt.Sprintf("must be greater than or equal to %d", *s.Minimum)))
+		}
+		if s.Maximum != nil && int64(number) > *s.Maximum {
+			allErrs = append(allErrs, field.Invalid(fieldPath, value, fmt.Sprintf("must be less than or equal to %d", *s.Maximum)))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return append(allErrs, field.Invalid(fieldPath, value, "must be a boolean"))
+		}
+	}
+	return allErrs
+}
+
+func (s schema) propertyNames() []string {
+	keys := make([]string, 0, len(s.Properties))
+	for key := range s.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePolicyDesiredState checks the desiredState against the whitelisted
+// settings and the constraints the schema cannot express, values with
+// templates are not checked since they are only known once rendered for
+// each node.
+func validatePolicyDesiredState(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	fieldPath := field.NewPath("spec", "desiredState")
+	if len(policy.Spec.DesiredState.Raw) == 0 {
+		return causes
+	}
+
+	var desiredState interface{}
+	err := yaml.Unmarshal(policy.Spec.DesiredState.Raw, &desiredState)
+	if err != nil {
+		return append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid desiredState: %v", err),
+			Field:   fieldPath.String(),
+		})
+	}
+
+	validationErrors := nmstateSchema.validate(fieldPath, desiredState)
+	if len(validationErrors) == 0 {
+		validationErrors = validateDesiredStateSemantics(fieldPath, desiredState)
+	}
+	for _, validationError := range validationErrors {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseType(validationError.Type),
+			Message: validationError.Error(),
+			Field:   validationError.Field,
+		})
+	}
+	return causes
+}
+
+// validateDesiredStateSemantics checks a desiredState that matches the
+// schema for duplicated interfaces and ports and for malformed addresses.
+func validateDesiredStateSemantics(fieldPath *field.Path, desiredState interface{}) field.ErrorList {
+	allErrs := field.ErrorList{}
+	state, ok := desiredState.(map[string]interface{})
+	if !ok {
+		return allErrs
+	}
+
+	interfaces, _ := state["interfaces"].([]interface{})
+	interfacesPath := fieldPath.Child("interfaces")
+	interfaceTypes := map[string][]string{}
+	portOwners := map[string]string{}
+	for i, iface := range interfaces {
+		ifacePath := interfacesPath.Index(i)
+		ifaceMap, _ := iface.(map[string]interface{})
+		name, _ := interfaceName(ifaceMap["name"])
+		ifaceType, _ := ifaceMap["type"].(string)
+
+		if seenTypes := interfaceTypes[name]; len(seenTypes) > 0 && !canShareName(seenTypes, ifaceType) {
+			allErrs = append(allErrs, field.Duplicate(ifacePath.Child("name"), name))
+		}
+		if !isTemplate(name) {
+			interfaceTypes[name] = append(interfaceTypes[name], ifaceType)
+		}
+
+		allErrs = append(allErrs, validateAddresses(ifacePath.Child("ipv4", "address"), ifaceMap["ipv4"], net.IPv4len)...)
+		allErrs = append(allErrs, validateAddresses(ifacePath.Child("ipv6", "address"), ifaceMap["ipv6"], net.IPv6len)...)
+
+		linkAggregation, _ := ifaceMap["link-aggregation"].(map[string]interface{})
+		for _, portsField := range []string{"port", "slaves"} {
+			ports, _ := linkAggregation[portsField].([]interface{})
+			for j, port := range ports {
+				portName, _ := interfaceName(port)
+				if isTemplate(portName) {
+					continue
+				}
+				portPath := ifacePath.Child("link-aggregation", portsField).Index(j)
+				if owner, found := portOwners[portName]; found {
+					allErrs = append(allErrs, field.Invalid(portPath, portName, fmt.Sprintf("port is already listed at bond %s", owner)))
+					continue
+				}
+				portOwners[portName] = name
+			}
+		}
+	}
+
+	routes, _ := state["routes"].(map[string]interface{})
+	routesConfig, _ := routes["config"].([]interface{})
+	for i, route := range routesConfig {
+		routePath := fieldPath.Child("routes", "config").Index(i)
+		routeMap, _ := route.(map[string]interface{})
+		if destination, ok := routeMap["destination"].(string); ok && !isTemplate(destination) {
+			if _, _, err := net.ParseCIDR(destination); err != nil {
+				allErrs = append(allErrs, field.Invalid(routePath.Child("destination"), destination, "must be a valid CIDR"))
+			}
+		}
+		if nextHop, ok := routeMap["next-hop-address"].(string); ok && nextHop != "" && !isTemplate(nextHop) && net.ParseIP(nextHop) == nil {
+			allErrs = append(allErrs, field.Invalid(routePath.Child("next-hop-address"), nextHop, "must be a valid IP address"))
+		}
+	}
+	return allErrs
+}
+
+// sameNameInterfaceTypes are the interface types nmstate allows to share the
+// name with another type, an ovs-bridge and its internal ovs-interface
+var sameNameInterfaceTypes = map[string]string{
+	"ovs-bridge":    "ovs-interface",
+	"ovs-interface": "ovs-bridge",
+}
+
+// canShareName tells if an interface of ifaceType can be listed with the
+// interfaces of seenTypes that have the same name
+func canShareName(seenTypes []string, ifaceType string) bool {
+	for _, seenType := range seenTypes {
+		if sameNameInterfaceTypes[seenType] != ifaceType {
+			return false
+		}
+	}
+	return true
+}
+
+// validateAddresses checks that the static addresses of an ipv4 or ipv6
+// section are IPs of that family
+func validateAddresses(addressPath *field.Path, ipConfig interface{}, ipLen int) field.ErrorList {
+	allErrs := field.ErrorList{}
+	ipConfigMap, _ := ipConfig.(map[string]interface{})
+	addresses, _ := ipConfigMap["address"].([]interface{})
+	for i, address := range addresses {
+		addressMap, _ := address.(map[string]interface{})
+		ip, _ := addressMap["ip"].(string)
+		if isTemplate(ip) {
+			continue
+		}
+		parsedIP := net.ParseIP(ip)
+		if parsedIP == nil || (ipLen == net.IPv4len) != (parsedIP.To4() != nil) {
+			family := "IPv6"
+			if ipLen == net.IPv4len {
+				family = "IPv4"
+			}
+			allErrs = append(allErrs, field.Invalid(addressPath.Index(i).Child("ip"), ip, fmt.Sprintf("must be a valid %s address", family)))
+		}
+	}
+	return allErrs
+}
+
+// interfaceName returns the interface name of a desiredState value, names
+// like 10 are decoded as numbers so integers are formatted back.
+func interfaceName(value interface{}) (string, bool) {
+	switch name := value.(type) {
+	case string:
+		return name, true
+	case float64:
+		if name != float64(int64(name)) {
+			return "", false
+		}
+		return strconv.FormatInt(int64(name), 10), true
+	}
+	return "", false
+}
+
+func isTemplate(value string) bool {
+	return strings.Contains(value, templateMarker)
+}
@@ -0,0 +1,295 @@
+package nodenetworkconfigurationpolicy
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+func desiredStatePolicy(desiredState string) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	return nmstatev1beta1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testPolicy",
+		},
+		Spec: shared.NodeNetworkConfigurationPolicySpec{
+			DesiredState: shared.NewState(desiredState),
+		},
+	}
+}
+
+var _ = Describe("NNCP desiredState validation", func() {
+	DescribeTable("when validating the desiredState",
+		func(desiredState string, expectedCauses []metav1.StatusCause) {
+			policy := desiredStatePolicy(desiredState)
+			Expect(validatePolicyDesiredState(policy, nmstatev1beta1.NodeNetworkConfigurationPolicy{})).To(ConsistOf(expectedCauses))
+		},
+		Entry("is empty", "", []metav1.StatusCause{}),
+		Entry("is valid", `
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - ip: 192.168.1.1
+      prefix-length: 24
+  ipv6:
+    enabled: true
+    address:
+    - ip: 2001:db8::1
+      prefix-length: 64
+  bridge:
+    options:
+      stp:
+        enabled: false
+    port:
+    - name: eth1
+- name: bond0
+  type: bond
+  state: up
+  link-aggregation:
+    mode: active-backup
+    port:
+    - eth2
+    - eth3
+- name: br-ex
+  type: ovs-bridge
+  state: up
+- name: br-ex
+  type: ovs-interface
+  state: up
+  unknown-setting: from-a-newer-nmstate
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.254
+    next-hop-interface: br1
+dns-resolver:
+  config:
+    server:
+    - 192.168.1.53
+`, []metav1.StatusCause{}),
+		Entry("has numeric interface names", `
+interfaces:
+- name: 10
+  type: vlan
+  state: up
+  vlan:
+    base-iface: 1
+    id: 10
+- name: bond0
+  type: bond
+  state: up
+  link-aggregation:
+    mode: active-backup
+    port:
+    - 2
+    - 3
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.254
+    next-hop-interface: 10
+`, []metav1.StatusCause{}),
+		Entry("has templated values", `
+interfaces:
+- name: '{{ index .Node.Labels "example.com/nic" }}'
+  type: ethernet
+  state: up
+  mtu: '{{ index .Node.Annotations "example.com/mtu" | toInt }}'
+  ipv4:
+    enabled: true
+    address:
+    - ip: '{{ index .Node.Annotations "example.com/address" }}'
+      prefix-length: 24
+`, []metav1.StatusCause{}),
+		Entry("has a fractional interface name", `
+interfaces:
+- name: 1.5
+  type: dummy
+  state: up
+`, []metav1.StatusCause{
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "spec.desiredState.interfaces[0].name: Invalid value: 1.5: must be a string or an integer",
+				Field:   "spec.desiredState.interfaces[0].name",
+			},
+		}),
+		Entry("is not YAML", "interfaces: [", []metav1.StatusCause{
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "invalid desiredState: error converting YAML to JSON: yaml: line 1: did not find expected node content",
+				Field:   "spec.desiredState",
+			},
+		}),
+		Entry("does not match the schema", `
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: upp
+- type: ethernet
+- name: eth1.100
+  type: vlan
+  vlan:
+    base-iface: eth1
+    id: 5000
+- name: eth2
+  ipv4:
+    address:
+    - ip: 192.168.1.1
+      prefix-length: 33
+`, []metav1.StatusCause{
+			{
+				Type:    metav1.CauseTypeFieldValueNotSupported,
+				Message: `spec.desiredState.interfaces[0].state: Unsupported value: "upp": supported values: "up", "down", "absent", "ignore"`,
+				Field:   "spec.desiredState.interfaces[0].state",
+			},
+			{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: "spec.desiredState.interfaces[1].name: Required value",
+				Field:   "spec.desiredState.interfaces[1].name",
+			},
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "spec.desiredState.interfaces[2].vlan.id: Invalid value: 5000: must be less than or equal to 4094",
+				Field:   "spec.desiredState.interfaces[2].vlan.id",
+			},
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "spec.desiredState.interfaces[3].ipv4.address[0].prefix-length: Invalid value: 33: must be less than or equal to 32",
+				Field:   "spec.desiredState.interfaces[3].ipv4.address[0].prefix-length",
+			},
+		}),
+		Entry("has interfaces sharing the name with other types", `
+interfaces:
+- name: eth1
+  type: ethernet
+- name: eth1
+  type: dummy
+- name: br-ex
+  type: ovs-bridge
+- name: br-ex
+  type: ovs-interface
+- name: br-ex
+  type: ovs-interface
+`, []metav1.StatusCause{
+			{
+				Type:    metav1.CauseTypeFieldValueDuplicate,
+				Message: `spec.desiredState.interfaces[1].name: Duplicate value: "eth1"`,
+				Field:   "spec.desiredState.interfaces[1].name",
+			},
+			{
+				Type:    metav1.CauseTypeFieldValueDuplicate,
+				Message: `spec.desiredState.interfaces[4].name: Duplicate value: "br-ex"`,
+				Field:   "spec.desiredState.interfaces[4].name",
+			},
+		}),
+		Entry("has duplicated interfaces and ports and malformed addresses", `
+interfaces:
+- name: bond0
+  type: bond
+  link-aggregation:
+    mode: balance-xor
+    port:
+    - eth1
+    - eth1
+- name: bond1
+  type: bond
+  link-aggregation:
+    mode: balance-xor
+    port:
+    - eth2
+    - eth1
+- name: bond0
+  type: bond
+- name: eth3
+  type: ethernet
+  ipv4:
+    address:
+    - ip: 2001:db8::1
+      prefix-length: 24
+  ipv6:
+    address:
+    - ip: 2001:db8::zz
+      prefix-length: 64
+routes:
+  config:
+  - destination: 192.168.1.0
+    next-hop-address: 192.168.1.300
+`, []metav1.StatusCause{
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: `spec.desiredState.interfaces[0].link-aggregation.port[1]: Invalid value: "eth1": port is already listed at bond bond0`,
+				Field:   "spec.desiredState.interfaces[0].link-aggregation.port[1]",
+			},
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: `spec.desiredState.interfaces[1].link-aggregation.port[1]: Invalid value: "eth1": port is already listed at bond bond0`,
+				Field:   "spec.desiredState.interfaces[1].link-aggregation.port[1]",
+			},
+			{
+				Type:    metav1.CauseTypeFieldValueDuplicate,
+				Message: `spec.desiredState.interfaces[2].name: Duplicate value: "bond0"`,
+				Field:   "spec.desiredState.interfaces[2].name",
+			},
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: `spec.desiredState.interfaces[3].ipv4.address[0].ip: Invalid value: "2001:db8::1": must be a valid IPv4 address`,
+				Field:   "spec.desiredState.interfaces[3].ipv4.address[0].ip",
+			},
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: `spec.desiredState.interfaces[3].ipv6.address[0].ip: Invalid value: "2001:db8::zz": must be a valid IPv6 address`,
+				Field:   "spec.desiredState.interfaces[3].ipv6.address[0].ip",
+			},
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: `spec.desiredState.routes.config[0].destination: Invalid value: "192.168.1.0": must be a valid CIDR`,
+				Field:   "spec.desiredState.routes.config[0].destination",
+			},
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: `spec.desiredState.routes.config[0].next-hop-address: Invalid value: "192.168.1.300": must be a valid IP address`,
+				Field:   "spec.desiredState.routes.config[0].next-hop-address",
+			},
+		}),
+	)
+	It("should report the causes with their field at the denial details", func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion, &nmstatev1beta1.NodeNetworkConfigurationPolicy{})
+		cli := fake.NewFakeClientWithScheme(s)
+
+		policy := desiredStatePolicy(`
+interfaces:
+- name: br1
+  type: linux-bridge
+  state: upp
+`)
+		policyJSON, err := json.Marshal(policy)
+		Expect(err).ToNot(HaveOccurred())
+
+		request := webhook.AdmissionRequest{}
+		request.Operation = admissionv1.Create
+		request.Object = runtime.RawExtension{Raw: policyJSON}
+		response := validatePolicyHandler(cli, onPolicySpecChange, validatePolicyDesiredState)(context.TODO(), request)
+
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Details).ToNot(BeNil())
+		Expect(response.Result.Details.Causes).To(HaveLen(1))
+		Expect(response.Result.Details.Causes[0].Field).To(Equal("spec.desiredState.interfaces[0].state"))
+	})
+})
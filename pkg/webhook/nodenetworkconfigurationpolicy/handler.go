@@ -22,6 +22,10 @@ import (
 type mutator func(nmstatev1beta1.NodeNetworkConfigurationPolicy) nmstatev1beta1.NodeNetworkConfigurationPolicy
 type validator func(nmstatev1beta1.NodeNetworkConfigurationPolicy, nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause
 
+// causeTypeWarning marks the causes returned by validators that do not deny
+// the policy, they are returned as admission warnings
+const causeTypeWarning metav1.CauseType = "Warning"
+
 func mutatePolicyHandler(neededMutationFor func(nmstatev1beta1.NodeNetworkConfigurationPolicy) bool, mutate mutator) admission.HandlerFunc {
 	log := logf.Log.WithName("webhook/nodenetworkconfigurationpolicy/mutator")
 	return func(ctx context.Context, req webhook.AdmissionRequest) webhook.AdmissionResponse {
@@ -70,13 +74,27 @@ func validatePolicyHandler(cli client.Client, neededValidationFor func(admission
 		}
 
 		errCauses := []metav1.StatusCause{}
+		warnings := []string{}
 		for _, validate := range validators {
-			errCauses = append(errCauses, validate(policy, currentPolicy)...)
+			for _, cause := range validate(policy, currentPolicy) {
+				if cause.Type == causeTypeWarning {
+					warnings = append(warnings, fmt.Sprintf("%s: %s", cause.Field, cause.Message))
+					continue
+				}
+				errCauses = append(errCauses, cause)
+			}
 		}
 		if len(errCauses) > 0 {
-			return admission.Denied(handlePolicyCauses(errCauses, policy.Name))
+			response := admission.Denied(handlePolicyCauses(errCauses, policy.Name))
+			response.Result.Details = &metav1.StatusDetails{
+				Name:   policy.Name,
+				Group:  nmstatev1beta1.GroupVersion.Group,
+				Kind:   "NodeNetworkConfigurationPolicy",
+				Causes: errCauses,
+			}
+			return response.WithWarnings(warnings...)
 		}
-		return admission.Allowed("")
+		return admission.Allowed("").WithWarnings(warnings...)
 	}
 }
 
@@ -0,0 +1,382 @@
+package nodenetworkconfigurationpolicy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	yaml "sigs.k8s.io/yaml"
+
+	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/policytemplate"
+)
+
+var defaultRouteDestinations = map[string]bool{"0.0.0.0/0": true, "::/0": true}
+
+// managementInterface is an interface the node management connectivity
+// depends on
+type managementInterface struct {
+	// reason is why the interface carries the management connectivity
+	reason string
+	// addresses are the node InternalIP addresses held by the interface
+	addresses []net.IP
+	// ports are the ports of the interface that are management interfaces
+	ports []string
+	// controller is the interface the port is attached to
+	controller string
+}
+
+// managementChange is a desiredState change that disrupts the management
+// connectivity of a node
+type managementChange struct {
+	field   string
+	message string
+}
+
+// validatePolicyManagementConnectivity denies policies that remove the
+// address from, detach or set down or absent the interfaces carrying the
+// matching nodes InternalIP or default route, unless the policy has the
+// allow management changes annotation. It fails closed, policies changing
+// interfaces or routes are denied at nodes whose management interface or
+// default route are not found at the NodeNetworkState, since the handler
+// state filter can hide them, and when the nodes cannot be listed.
+func validatePolicyManagementConnectivity(cli client.Reader) validator {
+	return func(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		causes := []metav1.StatusCause{}
+
+		if policy.Annotations[shared.NodeNetworkConfigurationPolicyAllowManagementChangesAnnotation] == "true" {
+			return causes
+		}
+		// Pausing or resuming does not change the policy configuration
+		if onlyPausedChanged(policy, currentPolicy) || len(policy.Spec.DesiredState.Raw) == 0 {
+			return causes
+		}
+
+		nodes := corev1.NodeList{}
+		err := cli.List(context.TODO(), &nodes)
+		if err != nil {
+			return append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeUnexpectedServerResponse,
+				Message: fmt.Sprintf("failed listing nodes to check management connectivity: %v", err),
+			})
+		}
+		policyNodes, err := matchingNodes(policy, nodes.Items)
+		if err != nil {
+			return append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("failed matching nodes to check management connectivity: %v", err),
+				Field:   "spec.nodeSelector",
+			})
+		}
+
+		affectedNodes := map[managementChange][]string{}
+		unknownNodes := []string{}
+		for _, node := range policyNodes {
+			nns := nmstatev1beta1.NodeNetworkState{}
+			err = cli.Get(context.TODO(), types.NamespacedName{Name: node.Name}, &nns)
+			if err != nil {
+				// Nodes without state yet cannot be checked
+				continue
+			}
+			// Templates that cannot be rendered fail the node enactment
+			// before anything is applied
+			desiredState, err := policytemplate.Render(policy.Spec.DesiredState, node, nns.Status.CurrentState)
+			if err != nil {
+				continue
+			}
+			changes, known := managementChanges(node, nns.Status.CurrentState, desiredState)
+			if !known {
+				unknownNodes = append(unknownNodes, node.Name)
+				continue
+			}
+			for _, change := range changes {
+				affectedNodes[change] = append(affectedNodes[change], node.Name)
+			}
+		}
+		if len(unknownNodes) > 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type: metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("the management interface or default route of nodes %s is not found at their NodeNetworkState, it may be hidden by the handler state filter, set the %s annotation to \"true\" to allow the policy",
+					strings.Join(unknownNodes, ", "), shared.NodeNetworkConfigurationPolicyAllowManagementChangesAnnotation),
+				Field: "spec.desiredState",
+			})
+		}
+
+		changes := []managementChange{}
+		for change := range affectedNodes {
+			changes = append(changes, change)
+		}
+		sort.Slice(changes, func(i, j int) bool {
+			if changes[i].field != changes[j].field {
+				return changes[i].field < changes[j].field
+			}
+			return changes[i].message < changes[j].message
+		})
+		for _, change := range changes {
+			causes = append(causes, metav1.StatusCause{
+				Type: metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s, disrupting the management connectivity of nodes %s, set the %s annotation to \"true\" to allow it",
+					change.message, strings.Join(affectedNodes[change], ", "), shared.NodeNetworkConfigurationPolicyAllowManagementChangesAnnotation),
+				Field: "spec.desiredState." + change.field,
+			})
+		}
+		return causes
+	}
+}
+
+// managementChanges returns the changes of the desired state that disrupt
+// the node management connectivity, known is false if the desired state
+// changes interfaces or routes but the management interfaces cannot be
+// found at the current state
+func managementChanges(node corev1.Node, currentState shared.State, desiredState shared.State) (changes []managementChange, known bool) {
+	current := map[string]interface{}{}
+	desired := map[string]interface{}{}
+	if yaml.Unmarshal(desiredState.Raw, &desired) != nil {
+		// Malformed desired states are reported by nmstate
+		return nil, true
+	}
+	if !changesInterfacesOrRoutes(desired) {
+		return nil, true
+	}
+	if yaml.Unmarshal(currentState.Raw, &current) != nil {
+		return nil, false
+	}
+	management, found := managementInterfaces(node, current)
+	if !found {
+		return nil, false
+	}
+
+	changes = []managementChange{}
+	interfaces, _ := desired["interfaces"].([]interface{})
+	for i, iface := range interfaces {
+		ifaceMap, _ := iface.(map[string]interface{})
+		name, _ := interfaceName(ifaceMap["name"])
+		ifacePath := fmt.Sprintf("interfaces[%d]", i)
+
+		if mgmtIface, found := management[name]; found {
+			state, _ := ifaceMap["state"].(string)
+			if state == "absent" || state == "down" {
+				changes = append(changes, managementChange{
+					field:   ifacePath + ".state",
+					message: fmt.Sprintf("interface %s %s is set %s", name, mgmtIface.reason, state),
+				})
+			}
+			for _, address := range mgmtIface.addresses {
+				if field, removed := addressRemoved(ifaceMap, address); removed {
+					changes = append(changes, managementChange{
+						field:   ifacePath + "." + field,
+						message: fmt.Sprintf("interface %s %s has the address removed", name, mgmtIface.reason),
+					})
+				}
+			}
+		}
+
+		portsField, ports, found := interfacePorts(ifaceMap)
+		if !found {
+			continue
+		}
+		desiredPorts := map[string]bool{}
+		for _, port := range ports {
+			desiredPorts[port] = true
+			mgmtPort, isManagement := management[port]
+			if isManagement && mgmtPort.controller != name {
+				changes = append(changes, managementChange{
+					field:   ifacePath + "." + portsField,
+					message: fmt.Sprintf("interface %s %s is attached to %s", port, mgmtPort.reason, name),
+				})
+			}
+		}
+		if mgmtIface, found := management[name]; found {
+			for _, port := range mgmtIface.ports {
+				if !desiredPorts[port] {
+					changes = append(changes, managementChange{
+						field:   ifacePath + "." + portsField,
+						message: fmt.Sprintf("port %s is detached from interface %s %s", port, name, mgmtIface.reason),
+					})
+				}
+			}
+		}
+	}
+
+	routes, _ := desired["routes"].(map[string]interface{})
+	routesConfig, _ := routes["config"].([]interface{})
+	for i, route := range routesConfig {
+		routeMap, _ := route.(map[string]interface{})
+		state, _ := routeMap["state"].(string)
+		destination, _ := routeMap["destination"].(string)
+		nextHopInterface, _ := interfaceName(routeMap["next-hop-interface"])
+		if state != "absent" || (destination != "" && !defaultRouteDestinations[destination]) {
+			continue
+		}
+		if _, found := management[nextHopInterface]; nextHopInterface == "" || found {
+			changes = append(changes, managementChange{
+				field:   fmt.Sprintf("routes.config[%d]", i),
+				message: "the default route is removed",
+			})
+		}
+	}
+	return changes, true
+}
+
+func changesInterfacesOrRoutes(desired map[string]interface{}) bool {
+	interfaces, _ := desired["interfaces"].([]interface{})
+	routes, _ := desired["routes"].(map[string]interface{})
+	routesConfig, _ := routes["config"].([]interface{})
+	return len(interfaces) > 0 || len(routesConfig) > 0
+}
+
+// managementInterfaces returns the interfaces of the current state holding
+// the node InternalIP addresses or the default routes, and the ports they
+// depend on indexed by name. found is false if no interface holds the node
+// InternalIP addresses or there is no default route.
+func managementInterfaces(node corev1.Node, current map[string]interface{}) (management map[string]managementInterface, found bool) {
+	internalIPs := []net.IP{}
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			if ip := net.ParseIP(address.Address); ip != nil {
+				internalIPs = append(internalIPs, ip)
+			}
+		}
+	}
+
+	management = map[string]managementInterface{}
+	interfaces, _ := current["interfaces"].([]interface{})
+	for _, iface := range interfaces {
+		ifaceMap, _ := iface.(map[string]interface{})
+		name, _ := interfaceName(ifaceMap["name"])
+		for _, ip := range internalIPs {
+			if hasAddress(ifaceMap, ip) {
+				mgmtIface := management[name]
+				mgmtIface.reason = "holding the node InternalIP"
+				mgmtIface.addresses = append(mgmtIface.addresses, ip)
+				management[name] = mgmtIface
+			}
+		}
+	}
+	foundInternalIP := len(internalIPs) == 0 || len(management) > 0
+
+	foundDefaultRoute := false
+	routes, _ := current["routes"].(map[string]interface{})
+	runningRoutes, _ := routes["running"].([]interface{})
+	for _, route := range runningRoutes {
+		routeMap, _ := route.(map[string]interface{})
+		destination, _ := routeMap["destination"].(string)
+		nextHopInterface, _ := interfaceName(routeMap["next-hop-interface"])
+		if !defaultRouteDestinations[destination] || nextHopInterface == "" {
+			continue
+		}
+		foundDefaultRoute = true
+		if _, found := management[nextHopInterface]; !found {
+			management[nextHopInterface] = managementInterface{reason: "holding the default route"}
+		}
+	}
+
+	// The ports of the management interfaces carry their traffic, an
+	// ovs-bridge and its ovs-interface share the name so every interface
+	// with the same name is checked
+	for added := true; added; {
+		added = false
+		for _, iface := range interfaces {
+			ifaceMap, _ := iface.(map[string]interface{})
+			name, _ := interfaceName(ifaceMap["name"])
+			controller, found := management[name]
+			if !found {
+				continue
+			}
+			_, ports, _ := interfacePorts(ifaceMap)
+			for _, port := range ports {
+				if port == name || contains(controller.ports, port) {
+					continue
+				}
+				controller.ports = append(controller.ports, port)
+				if _, found := management[port]; !found {
+					management[port] = managementInterface{reason: fmt.Sprintf("being a port of %s", name), controller: name}
+					added = true
+				}
+			}
+			management[name] = controller
+		}
+	}
+	return management, foundInternalIP && foundDefaultRoute
+}
+
+// interfacePorts returns the field and the port names of bridges and bonds
+func interfacePorts(iface map[string]interface{}) (string, []string, bool) {
+	if bridge, ok := iface["bridge"].(map[string]interface{}); ok {
+		if ports, ok := bridge["port"].([]interface{}); ok {
+			names := []string{}
+			for _, port := range ports {
+				portMap, _ := port.(map[string]interface{})
+				if name, ok := interfaceName(portMap["name"]); ok {
+					names = append(names, name)
+				}
+			}
+			return "bridge.port", names, true
+		}
+	}
+	if linkAggregation, ok := iface["link-aggregation"].(map[string]interface{}); ok {
+		for _, portsField := range []string{"port", "slaves"} {
+			if ports, ok := linkAggregation[portsField].([]interface{}); ok {
+				names := []string{}
+				for _, port := range ports {
+					if name, ok := interfaceName(port); ok {
+						names = append(names, name)
+					}
+				}
+				return "link-aggregation." + portsField, names, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+func ipFamily(ip net.IP) string {
+	if ip.To4() != nil {
+		return "ipv4"
+	}
+	return "ipv6"
+}
+
+func hasAddress(iface map[string]interface{}, ip net.IP) bool {
+	ipConfig, _ := iface[ipFamily(ip)].(map[string]interface{})
+	addresses, _ := ipConfig["address"].([]interface{})
+	for _, address := range addresses {
+		addressMap, _ := address.(map[string]interface{})
+		addressIP, _ := addressMap["ip"].(string)
+		if ip.Equal(net.ParseIP(addressIP)) {
+			return true
+		}
+	}
+	return false
+}
+
+// addressRemoved returns the field of the desired interface that removes
+// the address, addresses are only removed if the ip family is disabled or
+// the static addresses are set without it and without dhcp
+func addressRemoved(iface map[string]interface{}, ip net.IP) (string, bool) {
+	family := ipFamily(ip)
+	ipConfig, ok := iface[family].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	if enabled, ok := ipConfig["enabled"].(bool); ok && !enabled {
+		return family + ".enabled", true
+	}
+	if dhcp, _ := ipConfig["dhcp"].(bool); dhcp {
+		return "", false
+	}
+	if autoconf, _ := ipConfig["autoconf"].(bool); autoconf {
+		return "", false
+	}
+	if _, ok := ipConfig["address"]; ok && !hasAddress(iface, ip) {
+		return family + ".address", true
+	}
+	return "", false
+}
@@ -0,0 +1,273 @@
+package nodenetworkconfigurationpolicy
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+func managementNode(name string, internalIP string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"role": "worker"},
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: name},
+				{Type: corev1.NodeInternalIP, Address: internalIP},
+			},
+		},
+	}
+}
+
+func managementNNS(name string, currentState string) nmstatev1beta1.NodeNetworkState {
+	return nmstatev1beta1.NodeNetworkState{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: shared.NodeNetworkStateStatus{
+			CurrentState: shared.NewState(currentState),
+		},
+	}
+}
+
+func allowManagementChanges(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	policy.Annotations = map[string]string{shared.NodeNetworkConfigurationPolicyAllowManagementChangesAnnotation: "true"}
+	return policy
+}
+
+func managementCause(field, message string) metav1.StatusCause {
+	return metav1.StatusCause{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: message + ", disrupting the management connectivity of nodes node01, node02, set the nmstate.io/allow-management-changes annotation to \"true\" to allow it",
+		Field:   "spec.desiredState." + field,
+	}
+}
+
+var _ = Describe("NNCP management connectivity validation", func() {
+	const (
+		// br-ex holds the node address over eth0 and the default route,
+		// eth1 is a secondary NIC
+		currentStateTemplate = `
+interfaces:
+- name: br-ex
+  type: linux-bridge
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - ip: %s
+      prefix-length: 24
+  bridge:
+    port:
+    - name: eth0
+- name: eth0
+  type: ethernet
+  state: up
+- name: eth1
+  type: ethernet
+  state: up
+routes:
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.254
+    next-hop-interface: br-ex
+`
+	)
+	var (
+		nodes = []corev1.Node{
+			managementNode("node01", "192.168.1.1"),
+			managementNode("node02", "192.168.1.2"),
+			managementNode("node03", "192.168.1.3"),
+		}
+		worker = map[string]string{"role": "worker"}
+	)
+
+	type ManagementCase struct {
+		policy nmstatev1beta1.NodeNetworkConfigurationPolicy
+		causes []metav1.StatusCause
+	}
+	DescribeTable("the NNCP desired state", func(c ManagementCase) {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkState{},
+			&nmstatev1beta1.NodeNetworkStateList{},
+		)
+		objs := []runtime.Object{}
+		for i := range nodes {
+			objs = append(objs, &nodes[i])
+		}
+		// node03 has no state yet
+		for _, nodeName := range []string{"node01", "node02"} {
+			address := "192.168.1.1"
+			if nodeName == "node02" {
+				address = "192.168.1.2"
+			}
+			nns := managementNNS(nodeName, fmt.Sprintf(currentStateTemplate, address))
+			objs = append(objs, &nns)
+		}
+		cli := fake.NewFakeClientWithScheme(s, objs...)
+		Expect(validatePolicyManagementConnectivity(cli)(c.policy, nmstatev1beta1.NodeNetworkConfigurationPolicy{})).To(Equal(c.causes))
+	},
+		Entry("changes secondary interfaces", ManagementCase{
+			policy: conflictPolicy("policy", worker, `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: absent
+`),
+			causes: []metav1.StatusCause{},
+		}),
+		Entry("keeps the management address", ManagementCase{
+			policy: conflictPolicy("policy", worker, `
+interfaces:
+- name: br-ex
+  type: linux-bridge
+  state: up
+  mtu: 9000
+  ipv4:
+    enabled: true
+    address:
+    - ip: '{{ currentState "interfaces.#(name==\"br-ex\").ipv4.address.0.ip" }}'
+      prefix-length: 24
+  bridge:
+    port:
+    - name: eth0
+`),
+			causes: []metav1.StatusCause{},
+		}),
+		Entry("sets the management interface absent", ManagementCase{
+			policy: conflictPolicy("policy", worker, `
+interfaces:
+- name: br-ex
+  type: linux-bridge
+  state: absent
+`),
+			causes: []metav1.StatusCause{
+				managementCause("interfaces[0].state", "interface br-ex holding the node InternalIP is set absent"),
+			},
+		}),
+		Entry("removes the management address, detaches and attaches its port and removes the default route", ManagementCase{
+			policy: conflictPolicy("policy", worker, `
+interfaces:
+- name: br-ex
+  type: linux-bridge
+  state: up
+  ipv4:
+    enabled: false
+  bridge:
+    port: []
+- name: bond0
+  type: bond
+  state: up
+  link-aggregation:
+    mode: active-backup
+    port:
+    - eth0
+    - eth1
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    state: absent
+`),
+			causes: []metav1.StatusCause{
+				managementCause("interfaces[0].bridge.port", "port eth0 is detached from interface br-ex holding the node InternalIP"),
+				managementCause("interfaces[0].ipv4.enabled", "interface br-ex holding the node InternalIP has the address removed"),
+				managementCause("interfaces[1].link-aggregation.port", "interface eth0 being a port of br-ex is attached to bond0"),
+				managementCause("routes.config[0]", "the default route is removed"),
+			},
+		}),
+		Entry("changes the management interface with the annotation", ManagementCase{
+			policy: allowManagementChanges(conflictPolicy("policy", worker, `
+interfaces:
+- name: br-ex
+  type: linux-bridge
+  state: absent
+`)),
+			causes: []metav1.StatusCause{},
+		}),
+	)
+
+	Context("when the state filter hides the management address", func() {
+		var cli client.Client
+		BeforeEach(func() {
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NodeNetworkState{},
+				&nmstatev1beta1.NodeNetworkStateList{},
+			)
+			node := managementNode("node01", "192.168.1.1")
+			// The DHCP address is dropped by the dynamic addresses filter
+			nns := managementNNS("node01", `
+interfaces:
+- name: eth0
+  type: ethernet
+  state: up
+routes:
+  running:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.1.254
+    next-hop-interface: eth0
+`)
+			cli = fake.NewFakeClientWithScheme(s, &node, &nns)
+		})
+		It("should deny policies changing interfaces", func() {
+			policy := conflictPolicy("policy", worker, `
+interfaces:
+- name: eth0
+  type: ethernet
+  state: down
+`)
+			Expect(validatePolicyManagementConnectivity(cli)(policy, nmstatev1beta1.NodeNetworkConfigurationPolicy{})).To(ConsistOf(metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "the management interface or default route of nodes node01 is not found at their NodeNetworkState, it may be hidden by the handler state filter, set the nmstate.io/allow-management-changes annotation to \"true\" to allow the policy",
+				Field:   "spec.desiredState",
+			}))
+		})
+		It("should allow policies not changing interfaces or routes", func() {
+			policy := conflictPolicy("policy", worker, `
+dns-resolver:
+  config:
+    server:
+    - 192.168.1.53
+`)
+			Expect(validatePolicyManagementConnectivity(cli)(policy, nmstatev1beta1.NodeNetworkConfigurationPolicy{})).To(BeEmpty())
+		})
+	})
+
+	It("should deny policies whose nodes cannot be matched", func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkState{},
+			&nmstatev1beta1.NodeNetworkStateList{},
+		)
+		node := managementNode("node01", "192.168.1.1")
+		cli := fake.NewFakeClientWithScheme(s, &node)
+		policy := conflictPolicy("policy", nil, `
+interfaces:
+- name: eth0
+  type: ethernet
+  state: down
+`)
+		policy.Spec.NodeLabelSelector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "role", Operator: "Unknown"}},
+		}
+		causes := validatePolicyManagementConnectivity(cli)(policy, nmstatev1beta1.NodeNetworkConfigurationPolicy{})
+		Expect(causes).To(HaveLen(1))
+		Expect(causes[0].Type).To(Equal(metav1.CauseTypeFieldValueInvalid))
+		Expect(causes[0].Message).To(ContainSubstring("failed matching nodes to check management connectivity"))
+	})
+})
@@ -0,0 +1,61 @@
+package nodenetworkconfigurationpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/probe"
+)
+
+// validateNodeNetworkProbe checks the NodeNetworkProbe check like the
+// policy probes ones, an invalid one would roll back every policy at the
+// nodes it matches
+func validateNodeNetworkProbe(nodeNetworkProbe nmstatev1beta1.NodeNetworkProbe) []metav1.StatusCause {
+	specPath := field.NewPath("spec")
+	causes := probe.ValidateCheck(specPath, nodeNetworkProbe.Name, nodeNetworkProbe.Spec.ProbeCheck)
+	if nodeNetworkProbe.Spec.Timeout != nil && nodeNetworkProbe.Spec.Timeout.Duration <= 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid probe timeout: %q: must be positive", nodeNetworkProbe.Spec.Timeout.Duration),
+			Field:   specPath.Child("timeout").String(),
+		})
+	}
+	return causes
+}
+
+func validateNodeNetworkProbeHook() *webhook.Admission {
+	return &webhook.Admission{
+		Handler: admission.HandlerFunc(func(ctx context.Context, req webhook.AdmissionRequest) webhook.AdmissionResponse {
+			nodeNetworkProbe := nmstatev1beta1.NodeNetworkProbe{}
+			err := json.Unmarshal(req.Object.Raw, &nodeNetworkProbe)
+			if err != nil {
+				return admission.Errored(http.StatusInternalServerError, errors.Wrapf(err, "failed decoding NodeNetworkProbe: %s", string(req.Object.Raw)))
+			}
+			causes := validateNodeNetworkProbe(nodeNetworkProbe)
+			if len(causes) > 0 {
+				errMsg := fmt.Sprintf("failed to admit NodeNetworkProbe %s: ", nodeNetworkProbe.Name)
+				for _, cause := range causes {
+					errMsg += fmt.Sprintf("message: %s. ", cause.Message)
+				}
+				response := admission.Denied(errMsg)
+				response.Result.Details = &metav1.StatusDetails{
+					Name:   nodeNetworkProbe.Name,
+					Group:  nmstatev1beta1.GroupVersion.Group,
+					Kind:   "NodeNetworkProbe",
+					Causes: causes,
+				}
+				return response
+			}
+			return admission.Allowed("")
+		}),
+	}
+}
@@ -0,0 +1,50 @@
+package nodenetworkconfigurationpolicy
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+var _ = Describe("NodeNetworkProbe validation", func() {
+	nodeNetworkProbe := func(timeout *metav1.Duration, check shared.ProbeCheck) nmstatev1beta1.NodeNetworkProbe {
+		return nmstatev1beta1.NodeNetworkProbe{
+			ObjectMeta: metav1.ObjectMeta{Name: "gateway"},
+			Spec: nmstatev1beta1.NodeNetworkProbeSpec{
+				Timeout:    timeout,
+				ProbeCheck: check,
+			},
+		}
+	}
+	DescribeTable("checking the probe spec",
+		func(nodeNetworkProbe nmstatev1beta1.NodeNetworkProbe, expectedFields []string) {
+			fields := []string{}
+			for _, cause := range validateNodeNetworkProbe(nodeNetworkProbe) {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ConsistOf(expectedFields))
+		},
+		Entry("valid probe",
+			nodeNetworkProbe(nil, shared.ProbeCheck{ICMP: &shared.ICMPProbe{Target: "192.168.66.2"}}),
+			[]string{}),
+		Entry("without check",
+			nodeNetworkProbe(nil, shared.ProbeCheck{}),
+			[]string{"spec"}),
+		Entry("with several checks",
+			nodeNetworkProbe(nil, shared.ProbeCheck{ICMP: &shared.ICMPProbe{Target: "192.168.66.2"}, DNS: &shared.DNSProbe{Host: "example.com"}}),
+			[]string{"spec"}),
+		Entry("with empty target",
+			nodeNetworkProbe(nil, shared.ProbeCheck{ICMP: &shared.ICMPProbe{}}),
+			[]string{"spec.icmp.target"}),
+		Entry("with negative timeout",
+			nodeNetworkProbe(&metav1.Duration{Duration: -time.Second}, shared.ProbeCheck{ICMP: &shared.ICMPProbe{Target: "192.168.66.2"}}),
+			[]string{"spec.timeout"}),
+	)
+})
@@ -0,0 +1,85 @@
+package nodenetworkconfigurationpolicy
+
+import (
+	"fmt"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/policyrevision"
+)
+
+func rollbackRequested(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) bool {
+	return policy.Spec.RollbackTo != nil
+}
+
+// rollbackPolicy replaces the policy spec with the one from the rollbackTo
+// revision, if the revision cannot be retrieved rollbackTo is kept so the
+// update is denied by validatePolicyRollbackTo.
+func rollbackPolicy(cli client.Reader) mutator {
+	return func(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+		policyRevision, err := policyrevision.Get(cli, policy, *policy.Spec.RollbackTo)
+		if err != nil {
+			log.Error(err, "failed rolling back policy", "policy", policy.Name)
+			return policy
+		}
+		log.Info("rolling back policy", "policy", policy.Name, "revision", policyRevision.Revision)
+		return policyrevision.Rollback(policy, *policyRevision)
+	}
+}
+
+// onlyRolledBack is true if the policy update restores the spec of one of
+// its previous revisions, as the rollbackTo mutation does, keeping the
+// current paused field
+func onlyRolledBack(cli client.Reader, policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) bool {
+	if policy.Spec.Paused != currentPolicy.Spec.Paused {
+		return false
+	}
+	revisions, err := policyrevision.List(cli, policy.Name)
+	if err != nil {
+		return false
+	}
+	for _, policyRevision := range revisions {
+		if policyRevision.Revision >= currentPolicy.Generation {
+			continue
+		}
+		if reflect.DeepEqual(policyrevision.Rollback(policy, policyRevision).Spec, policy.Spec) {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePolicyRollbackTo(cli client.Reader) validator {
+	return func(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		if !rollbackRequested(policy) {
+			return []metav1.StatusCause{}
+		}
+		message := fmt.Sprintf("failed rolling back to revision %d", *policy.Spec.RollbackTo)
+		_, err := policyrevision.Get(cli, currentPolicy, *policy.Spec.RollbackTo)
+		if err != nil {
+			message = fmt.Sprintf("%s: %v", message, err)
+		}
+		return []metav1.StatusCause{
+			{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: message,
+				Field:   "spec.rollbackTo",
+			},
+		}
+	}
+}
+
+func rollbackPolicyHook(cli client.Reader) *webhook.Admission {
+	return &webhook.Admission{
+		Handler: admission.HandlerFunc(
+			mutatePolicyHandler(
+				rollbackRequested,
+				rollbackPolicy(cli),
+			)),
+	}
+}
@@ -0,0 +1,101 @@
+package nodenetworkconfigurationpolicy
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
+	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+)
+
+func revisionPolicy(generation int64, desiredState string) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	return nmstatev1beta1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "policy1",
+			Generation: generation,
+		},
+		Spec: shared.NodeNetworkConfigurationPolicySpec{
+			DesiredState: shared.NewState(desiredState),
+		},
+	}
+}
+
+func rollbackTo(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, revision int64) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	policy.Spec.RollbackTo = &revision
+	return policy
+}
+
+var _ = Describe("NNCP rollback", func() {
+	var (
+		cli           client.Client
+		currentPolicy = revisionPolicy(3, "bad: configuration")
+	)
+	BeforeEach(func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyRevision{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyRevisionList{},
+		)
+		firstRevision := nmstatev1beta1.NewPolicyRevision(revisionPolicy(1, "first: configuration"))
+		secondRevision := nmstatev1beta1.NewPolicyRevision(pause(revisionPolicy(2, "good: configuration")))
+		currentRevision := nmstatev1beta1.NewPolicyRevision(currentPolicy)
+		cli = fake.NewFakeClientWithScheme(s, &firstRevision, &secondRevision, &currentRevision)
+	})
+	Context("when rolling back to a named revision", func() {
+		It("should restore the revision spec and clear rollbackTo", func() {
+			rolledBack := rollbackPolicy(cli)(rollbackTo(currentPolicy, 1))
+			Expect(rolledBack.Spec.RollbackTo).To(BeNil())
+			Expect(rolledBack.Spec.DesiredState.String()).To(MatchYAML("first: configuration"))
+		})
+	})
+	Context("when rolling back to revision 0", func() {
+		It("should restore the previous revision keeping the current paused field", func() {
+			rolledBack := rollbackPolicy(cli)(rollbackTo(currentPolicy, 0))
+			Expect(rolledBack.Spec.RollbackTo).To(BeNil())
+			Expect(rolledBack.Spec.DesiredState.String()).To(MatchYAML("good: configuration"))
+			Expect(rolledBack.Spec.Paused).To(BeFalse())
+		})
+	})
+	Context("when rolling back to a missing revision", func() {
+		var policy nmstatev1beta1.NodeNetworkConfigurationPolicy
+		BeforeEach(func() {
+			policy = rollbackPolicy(cli)(rollbackTo(currentPolicy, 7))
+		})
+		It("should keep the policy untouched", func() {
+			Expect(policy).To(Equal(rollbackTo(currentPolicy, 7)))
+		})
+		It("should deny the update", func() {
+			causes := validatePolicyRollbackTo(cli)(policy, currentPolicy)
+			Expect(causes).To(ConsistOf(metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "failed rolling back to revision 7: policy policy1 has no revision 7",
+				Field:   "spec.rollbackTo",
+			}))
+		})
+	})
+	Context("when the policy is still in progress", func() {
+		It("should allow rolling it back", func() {
+			rolledBack := rollbackPolicy(cli)(rollbackTo(currentPolicy, 1))
+			Expect(validatePolicyNotInProgress(cli)(rolledBack, currentPolicy)).To(BeEmpty())
+		})
+		It("should deny other spec changes", func() {
+			causes := validatePolicyNotInProgress(cli)(revisionPolicy(3, "other: configuration"), currentPolicy)
+			Expect(causes).To(ConsistOf(metav1.StatusCause{
+				Message: "policy policy1 is still in progress",
+			}))
+		})
+		It("should deny restoring the revision with other changes", func() {
+			rolledBack := rollbackPolicy(cli)(rollbackTo(currentPolicy, 1))
+			rolledBack.Spec.NodeSelector = map[string]string{"kubernetes.io/hostname": "node01"}
+			Expect(validatePolicyNotInProgress(cli)(rolledBack, currentPolicy)).ToNot(BeEmpty())
+		})
+	})
+	It("should not deny updates without rollbackTo", func() {
+		Expect(validatePolicyRollbackTo(cli)(currentPolicy, currentPolicy)).To(BeEmpty())
+	})
+})
@@ -23,6 +23,8 @@ func Add(mgr manager.Manager) error {
 	server.Register("/nodenetworkconfigurationpolicies-mutate", deleteConditionsHook())
 	server.Register("/nodenetworkconfigurationpolicies-status-mutate", setConditionsUnknownHook())
 	server.Register("/nodenetworkconfigurationpolicies-timestamp-mutate", setTimestampAnnotationHook())
+	server.Register("/nodenetworkconfigurationpolicies-rollback-mutate", rollbackPolicyHook(mgr.GetClient()))
 	server.Register("/nodenetworkconfigurationpolicies-progress-validate", validatePolicyUpdateHook(mgr.GetClient()))
+	server.Register("/nodenetworkprobes-validate", validateNodeNetworkProbeHook())
 	return mgr.Add(server)
 }
@@ -1,19 +1,25 @@
 package nodenetworkconfigurationpolicy
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	"github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus"
+	"github.com/nmstate/kubernetes-nmstate/pkg/probe"
 )
 
 func onPolicySpecChange(operation admissionv1.Operation, policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) bool {
@@ -24,8 +30,20 @@ func onCreate(operation admissionv1.Operation, policy nmstatev1beta1.NodeNetwork
 	return operation == admissionv1.Create
 }
 
+// onlyPausedChanged is true if the policy update pauses or resumes it
+// without changing anything else at the spec
+func onlyPausedChanged(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) bool {
+	spec := policy.Spec.DeepCopy()
+	spec.Paused = currentPolicy.Spec.Paused
+	return reflect.DeepEqual(*spec, currentPolicy.Spec)
+}
+
 func validatePolicyNotInProgressHook(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
 	causes := []metav1.StatusCause{}
+	// Pausing is the way to stop a policy in progress
+	if onlyPausedChanged(policy, currentPolicy) {
+		return causes
+	}
 	currentPolicyAvailableCondition := currentPolicy.Status.Conditions.Find(shared.NodeNetworkConfigurationPolicyConditionAvailable)
 
 	if currentPolicyAvailableCondition == nil ||
@@ -38,6 +56,38 @@ func validatePolicyNotInProgressHook(policy nmstatev1beta1.NodeNetworkConfigurat
 	return causes
 }
 
+// pausedWithoutEnactmentsInFlight is true if the current policy is paused and
+// none of its enactments is still applying or reverting the desired state,
+// so the policy can be fixed before resuming it
+func pausedWithoutEnactmentsInFlight(cli client.Reader, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) bool {
+	if !currentPolicy.Spec.Paused {
+		return false
+	}
+	enactments := nmstatev1beta1.NodeNetworkConfigurationEnactmentList{}
+	err := cli.List(context.TODO(), &enactments, client.MatchingLabels{shared.EnactmentPolicyLabel: currentPolicy.Name})
+	if err != nil {
+		return false
+	}
+	for _, enactment := range enactments.Items {
+		if enactmentstatus.IsProgressing(&enactment.Status.Conditions) {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePolicyNotInProgress denies updating a policy in progress unless it
+// is paused without enactments in flight or it is rolled back, pausing and
+// rolling back are the way to stop a bad rollout
+func validatePolicyNotInProgress(cli client.Reader) validator {
+	return func(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+		if pausedWithoutEnactmentsInFlight(cli, currentPolicy) || onlyRolledBack(cli, policy, currentPolicy) {
+			return []metav1.StatusCause{}
+		}
+		return validatePolicyNotInProgressHook(policy, currentPolicy)
+	}
+}
+
 func validatePolicyNodeSelector(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
 	causes := []metav1.StatusCause{}
 	nodeSelector := policy.Spec.NodeSelector
@@ -65,6 +115,144 @@ func validatePolicyNodeSelector(policy nmstatev1beta1.NodeNetworkConfigurationPo
 	return causes
 }
 
+func validatePolicyNodeLabelSelector(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	nodeLabelSelector := policy.Spec.NodeLabelSelector
+	if nodeLabelSelector == nil {
+		return causes
+	}
+	fieldPath := field.NewPath("spec", "nodeLabelSelector")
+	for _, validationError := range metav1validation.ValidateLabelSelector(nodeLabelSelector, fieldPath) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseType(validationError.Type),
+			Message: validationError.ErrorBody(),
+			Field:   validationError.Field,
+		})
+	}
+	if len(causes) > 0 {
+		return causes
+	}
+	// Values are validated when converting to a selector
+	_, err := metav1.LabelSelectorAsSelector(nodeLabelSelector)
+	if err != nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: err.Error(),
+			Field:   fieldPath.String(),
+		})
+	}
+	return causes
+}
+
+func validatePolicyRollout(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	rollout := policy.Spec.Rollout
+	if rollout == nil {
+		return causes
+	}
+	fieldPath := field.NewPath("spec", "rollout")
+	if rollout.NodeOrderLabel != "" {
+		validationErrors := validation.IsQualifiedName(rollout.NodeOrderLabel)
+		if len(validationErrors) > 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid label key: %q: %s", rollout.NodeOrderLabel, strings.Join(validationErrors, "; ")),
+				Field:   fieldPath.Child("nodeOrderLabel").String(),
+			})
+		}
+	}
+	for i, step := range rollout.Steps {
+		stepPath := fieldPath.Child("steps").Index(i)
+		// Scaling a hundred nodes catches both negative numbers and
+		// malformed or out of range percentages
+		nodes, err := intstr.GetScaledValueFromIntOrPercent(&step.Nodes, 100, true)
+		if err != nil || nodes <= 0 || (step.Nodes.Type == intstr.String && nodes > 100) {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid rollout step nodes: %q: must be a positive number or a percentage between 1%% and 100%%", step.Nodes.String()),
+				Field:   stepPath.Child("nodes").String(),
+			})
+		}
+		if step.Pause != nil && step.Pause.Duration < 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid rollout step pause: %q: must not be negative", step.Pause.Duration),
+				Field:   stepPath.Child("pause").String(),
+			})
+		}
+	}
+	return causes
+}
+
+func validatePolicyTimeouts(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	timeouts := policy.Spec.Timeouts
+	if timeouts == nil {
+		return causes
+	}
+	fieldPath := field.NewPath("spec", "timeouts")
+	for _, timeout := range []struct {
+		name  string
+		value *metav1.Duration
+	}{
+		{"checkpoint", timeouts.Checkpoint},
+		{"defaultGatewayProbe", timeouts.DefaultGatewayProbe},
+		{"dnsProbe", timeouts.DNSProbe},
+		{"apiServerProbe", timeouts.APIServerProbe},
+		{"nodeReadinessProbe", timeouts.NodeReadinessProbe},
+	} {
+		if timeout.value != nil && timeout.value.Duration <= 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid %s timeout: %q: must be positive", timeout.name, timeout.value.Duration),
+				Field:   fieldPath.Child(timeout.name).String(),
+			})
+		}
+	}
+	if len(causes) > 0 || timeouts.Checkpoint == nil {
+		return causes
+	}
+	// The cluster wide timeouts are not known here, the builtin defaults are
+	// used for the probes the policy does not set
+	probesTimeout := probe.DefaultTimeouts().Override(timeouts).WithUserProbes(probe.User(policy.Spec.Probes)).ProbesTimeout()
+	if timeouts.Checkpoint.Duration < probesTimeout {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("invalid checkpoint timeout: %q: must not be shorter than the probes timeouts sum %q", timeouts.Checkpoint.Duration, probesTimeout),
+			Field:   fieldPath.Child("checkpoint").String(),
+		})
+	}
+	return causes
+}
+
+func validatePolicyProbes(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+	causes := []metav1.StatusCause{}
+	invalid := func(fieldPath *field.Path, format string, a ...interface{}) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf(format, a...),
+			Field:   fieldPath.String(),
+		})
+	}
+	names := map[string]bool{}
+	for i, policyProbe := range policy.Spec.Probes {
+		probePath := field.NewPath("spec", "probes").Index(i)
+		if policyProbe.Name == "" {
+			invalid(probePath.Child("name"), "invalid probe name: must not be empty")
+		} else if names[policyProbe.Name] {
+			invalid(probePath.Child("name"), "invalid probe name: %q: is duplicated", policyProbe.Name)
+		}
+		names[policyProbe.Name] = true
+
+		if policyProbe.Timeout != nil && policyProbe.Timeout.Duration <= 0 {
+			invalid(probePath.Child("timeout"), "invalid probe timeout: %q: must be positive", policyProbe.Timeout.Duration)
+		}
+
+		causes = append(causes, probe.ValidateCheck(probePath, policyProbe.Name, policyProbe.ProbeCheck)...)
+	}
+	return causes
+}
+
 func validatePolicyName(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
 	causes := []metav1.StatusCause{}
 	validationErrors := validation.IsValidLabelValue(policy.Name)
@@ -78,14 +266,44 @@ func validatePolicyName(policy nmstatev1beta1.NodeNetworkConfigurationPolicy, cu
 	return causes
 }
 
+// specValidators validate the policy spec, they are run by the webhook on
+// spec changes and by ValidatePolicy
+func specValidators(cli client.Reader) []validator {
+	return []validator{
+		validatePolicyNodeSelector,
+		validatePolicyNodeLabelSelector,
+		validatePolicyRollout,
+		validatePolicyTimeouts,
+		validatePolicyProbes,
+		validatePolicyDesiredState,
+		validatePolicyConflicts(cli),
+		validatePolicyManagementConnectivity(cli),
+	}
+}
+
+// ValidatePolicy runs the validations of the policy spec done at the
+// webhook, cli reads the nodes, their states and the other policies so they
+// can be captured objects when there is no cluster. The validations that
+// depend on the policy at the cluster, progress and rollbackTo, are not run.
+func ValidatePolicy(cli client.Reader, policy nmstatev1beta1.NodeNetworkConfigurationPolicy) []metav1.StatusCause {
+	validators := append([]validator{validatePolicyName}, specValidators(cli)...)
+	causes := []metav1.StatusCause{}
+	for _, validate := range validators {
+		causes = append(causes, validate(policy, nmstatev1beta1.NodeNetworkConfigurationPolicy{})...)
+	}
+	return causes
+}
+
 func validatePolicyUpdateHook(cli client.Client) *webhook.Admission {
+	validators := []validator{validatePolicyNotInProgress(cli)}
+	validators = append(validators, specValidators(cli)...)
+	validators = append(validators, validatePolicyRollbackTo(cli))
 	return &webhook.Admission{
 		Handler: admission.MultiValidatingHandler(
 			admission.HandlerFunc(validatePolicyHandler(
 				cli,
 				onPolicySpecChange,
-				validatePolicyNotInProgressHook,
-				validatePolicyNodeSelector,
+				validators...,
 			)),
 			admission.HandlerFunc(validatePolicyHandler(
 				cli,
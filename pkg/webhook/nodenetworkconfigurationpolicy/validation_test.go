@@ -1,14 +1,21 @@
 package nodenetworkconfigurationpolicy
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
 	"github.com/nmstate/kubernetes-nmstate/pkg/policyconditions"
 )
 
@@ -28,6 +35,55 @@ func p(nodeSelector map[string]string, conditionsSetter func(*shared.ConditionLi
 	}
 }
 
+func pause(policy nmstatev1beta1.NodeNetworkConfigurationPolicy) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	policy.Spec.Paused = true
+	return policy
+}
+
+func r(rollout *shared.NodeNetworkConfigurationPolicyRollout) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	return nmstatev1beta1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testPolicy",
+		},
+		Spec: shared.NodeNetworkConfigurationPolicySpec{
+			Rollout: rollout,
+		},
+	}
+}
+
+func t(timeouts *shared.NodeNetworkConfigurationPolicyTimeouts) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	return nmstatev1beta1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testPolicy",
+		},
+		Spec: shared.NodeNetworkConfigurationPolicySpec{
+			Timeouts: timeouts,
+		},
+	}
+}
+
+func probes(policyProbes ...shared.NodeNetworkConfigurationPolicyProbe) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	return nmstatev1beta1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testPolicy",
+		},
+		Spec: shared.NodeNetworkConfigurationPolicySpec{
+			Probes: policyProbes,
+		},
+	}
+}
+
+func ls(nodeLabelSelector *metav1.LabelSelector) nmstatev1beta1.NodeNetworkConfigurationPolicy {
+	return nmstatev1beta1.NodeNetworkConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testPolicy",
+		},
+		Spec: shared.NodeNetworkConfigurationPolicySpec{
+			NodeLabelSelector: nodeLabelSelector,
+		},
+	}
+}
+
 var _ = Describe("NNCP Conditions Validation Admission Webhook", func() {
 	var allNodes = map[string]string{}
 	var testPolicy = nmstatev1beta1.NodeNetworkConfigurationPolicy{
@@ -57,6 +113,28 @@ var _ = Describe("NNCP Conditions Validation Admission Webhook", func() {
 				},
 			},
 		}),
+		Entry("current policy in progress and policy is paused", ValidationWebhookCase{
+			policy:           pause(p(allNodes, policyconditions.SetPolicyProgressing, "")),
+			currentPolicy:    p(allNodes, policyconditions.SetPolicyProgressing, ""),
+			validationFn:     validatePolicyNotInProgressHook,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("current policy paused and policy is resumed", ValidationWebhookCase{
+			policy:           p(allNodes, policyconditions.SetPolicyPaused, ""),
+			currentPolicy:    pause(p(allNodes, policyconditions.SetPolicyPaused, "")),
+			validationFn:     validatePolicyNotInProgressHook,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("current policy in progress and policy is paused with other changes", ValidationWebhookCase{
+			policy:        pause(p(map[string]string{"kubernetes.io/hostname": "node01"}, policyconditions.SetPolicyProgressing, "")),
+			currentPolicy: p(allNodes, policyconditions.SetPolicyProgressing, ""),
+			validationFn:  validatePolicyNotInProgressHook,
+			validationResult: []metav1.StatusCause{
+				{
+					Message: "policy testPolicy is still in progress",
+				},
+			},
+		}),
 		Entry("current policy successfully configured", ValidationWebhookCase{
 			policy:           testPolicy,
 			currentPolicy:    p(allNodes, policyconditions.SetPolicySuccess, ""),
@@ -107,6 +185,214 @@ var _ = Describe("NNCP Conditions Validation Admission Webhook", func() {
 			validationFn:     validatePolicyNodeSelector,
 			validationResult: []metav1.StatusCause{},
 		}),
+		Entry("policy has valid node label selector", ValidationWebhookCase{
+			policy: ls(&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "node-role.kubernetes.io/worker", Operator: metav1.LabelSelectorOpExists},
+					{Key: "rack", Operator: metav1.LabelSelectorOpIn, Values: []string{"r1", "r2"}},
+					{Key: "pool", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"storage"}},
+					{Key: "maintenance", Operator: metav1.LabelSelectorOpDoesNotExist},
+				},
+			}),
+			validationFn:     validatePolicyNodeLabelSelector,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has node label selector with In operator and no values", ValidationWebhookCase{
+			policy: ls(&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "rack", Operator: metav1.LabelSelectorOpIn},
+				},
+			}),
+			validationFn: validatePolicyNodeLabelSelector,
+			validationResult: []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: "Required value: must be specified when `operator` is 'In' or 'NotIn'",
+				Field:   "spec.nodeLabelSelector.matchExpressions[0].values",
+			}},
+		}),
+		Entry("policy has node label selector with unknown operator", ValidationWebhookCase{
+			policy: ls(&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "rack", Operator: "Foo", Values: []string{"r1"}},
+				},
+			}),
+			validationFn: validatePolicyNodeLabelSelector,
+			validationResult: []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "Invalid value: \"Foo\": not a valid selector operator",
+				Field:   "spec.nodeLabelSelector.matchExpressions[0].operator",
+			}},
+		}),
+		Entry("policy has node label selector with invalid value", ValidationWebhookCase{
+			policy: ls(&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "rack", Operator: metav1.LabelSelectorOpIn, Values: []string{"foo+bar"}},
+				},
+			}),
+			validationFn: validatePolicyNodeLabelSelector,
+			validationResult: []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "values[0][rack]: Invalid value: \"foo+bar\": a valid label must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyValue',  or 'my_value',  or '12345', regex used for validation is '(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?')",
+				Field:   "spec.nodeLabelSelector",
+			}},
+		}),
+		Entry("policy has valid rollout", ValidationWebhookCase{
+			policy: r(&shared.NodeNetworkConfigurationPolicyRollout{
+				NodeOrderLabel: "node-role.kubernetes.io/master",
+				Steps: []shared.NodeNetworkConfigurationPolicyRolloutStep{
+					{Nodes: intstr.FromInt(1), Pause: &metav1.Duration{Duration: 10 * time.Minute}},
+					{Nodes: intstr.FromString("10%")},
+					{Nodes: intstr.FromString("100%")},
+				},
+			}),
+			validationFn:     validatePolicyRollout,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has rollout with invalid steps", ValidationWebhookCase{
+			policy: r(&shared.NodeNetworkConfigurationPolicyRollout{
+				Steps: []shared.NodeNetworkConfigurationPolicyRolloutStep{
+					{Nodes: intstr.FromInt(0)},
+					{Nodes: intstr.FromString("120%")},
+					{Nodes: intstr.FromString("foo"), Pause: &metav1.Duration{Duration: -time.Minute}},
+				},
+			}),
+			validationFn: validatePolicyRollout,
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid rollout step nodes: \"0\": must be a positive number or a percentage between 1% and 100%",
+					Field:   "spec.rollout.steps[0].nodes",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid rollout step nodes: \"120%\": must be a positive number or a percentage between 1% and 100%",
+					Field:   "spec.rollout.steps[1].nodes",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid rollout step nodes: \"foo\": must be a positive number or a percentage between 1% and 100%",
+					Field:   "spec.rollout.steps[2].nodes",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid rollout step pause: \"-1m0s\": must not be negative",
+					Field:   "spec.rollout.steps[2].pause",
+				},
+			},
+		}),
+		Entry("policy has rollout with invalid node order label", ValidationWebhookCase{
+			policy: r(&shared.NodeNetworkConfigurationPolicyRollout{
+				NodeOrderLabel: "bad key",
+			}),
+			validationFn: validatePolicyRollout,
+			validationResult: []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "invalid label key: \"bad key\": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')",
+				Field:   "spec.rollout.nodeOrderLabel",
+			}},
+		}),
+		Entry("policy has valid timeouts", ValidationWebhookCase{
+			policy: t(&shared.NodeNetworkConfigurationPolicyTimeouts{
+				Checkpoint:          &metav1.Duration{Duration: 10 * time.Minute},
+				DefaultGatewayProbe: &metav1.Duration{Duration: 30 * time.Second},
+			}),
+			validationFn:     validatePolicyTimeouts,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has a checkpoint shorter than the probes", ValidationWebhookCase{
+			policy: t(&shared.NodeNetworkConfigurationPolicyTimeouts{
+				Checkpoint:          &metav1.Duration{Duration: 5 * time.Minute},
+				DefaultGatewayProbe: &metav1.Duration{Duration: 30 * time.Second},
+			}),
+			validationFn: validatePolicyTimeouts,
+			validationResult: []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: "invalid checkpoint timeout: \"5m0s\": must not be shorter than the probes timeouts sum \"6m30s\"",
+				Field:   "spec.timeouts.checkpoint",
+			}},
+		}),
+		Entry("policy has non positive timeouts", ValidationWebhookCase{
+			policy: t(&shared.NodeNetworkConfigurationPolicyTimeouts{
+				DNSProbe:           &metav1.Duration{Duration: 0},
+				NodeReadinessProbe: &metav1.Duration{Duration: -time.Second},
+			}),
+			validationFn: validatePolicyTimeouts,
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid dnsProbe timeout: \"0s\": must be positive",
+					Field:   "spec.timeouts.dnsProbe",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid nodeReadinessProbe timeout: \"-1s\": must be positive",
+					Field:   "spec.timeouts.nodeReadinessProbe",
+				},
+			},
+		}),
+		Entry("policy has valid probes", ValidationWebhookCase{
+			policy: probes(
+				shared.NodeNetworkConfigurationPolicyProbe{Name: "storage-ping", ProbeCheck: shared.ProbeCheck{ICMP: &shared.ICMPProbe{Target: "192.168.100.10", SourceInterface: "eth1"}}},
+				shared.NodeNetworkConfigurationPolicyProbe{Name: "storage-iscsi", ProbeCheck: shared.ProbeCheck{TCP: &shared.TCPProbe{Address: "192.168.100.10:3260"}}, Timeout: &metav1.Duration{Duration: time.Minute}},
+				shared.NodeNetworkConfigurationPolicyProbe{Name: "registry", ProbeCheck: shared.ProbeCheck{HTTP: &shared.HTTPProbe{URL: "https://registry.example.com/v2/", ExpectedStatus: 401}}},
+				shared.NodeNetworkConfigurationPolicyProbe{Name: "eth1-address", ProbeCheck: shared.ProbeCheck{Interface: &shared.InterfaceProbe{Name: "eth1", Carrier: true, IPAddress: "192.168.100.2"}}},
+				shared.NodeNetworkConfigurationPolicyProbe{Name: "registry-dns", ProbeCheck: shared.ProbeCheck{DNS: &shared.DNSProbe{Host: "registry.example.com", Server: "192.168.100.53"}}},
+			),
+			validationFn:     validatePolicyProbes,
+			validationResult: []metav1.StatusCause{},
+		}),
+		Entry("policy has invalid probes", ValidationWebhookCase{
+			policy: probes(
+				shared.NodeNetworkConfigurationPolicyProbe{Name: "storage", ProbeCheck: shared.ProbeCheck{TCP: &shared.TCPProbe{Address: "192.168.100.10"}}},
+				shared.NodeNetworkConfigurationPolicyProbe{Name: "storage", ProbeCheck: shared.ProbeCheck{HTTP: &shared.HTTPProbe{URL: "ftp://storage", ExpectedStatus: 42}}},
+				shared.NodeNetworkConfigurationPolicyProbe{Name: "eth1", ProbeCheck: shared.ProbeCheck{Interface: &shared.InterfaceProbe{Name: "eth1"}}, Timeout: &metav1.Duration{}},
+				shared.NodeNetworkConfigurationPolicyProbe{Name: "both", ProbeCheck: shared.ProbeCheck{ICMP: &shared.ICMPProbe{Target: "10.0.0.1"}, TCP: &shared.TCPProbe{Address: "10.0.0.1:80"}}},
+				shared.NodeNetworkConfigurationPolicyProbe{Name: "dns", ProbeCheck: shared.ProbeCheck{DNS: &shared.DNSProbe{}}},
+			),
+			validationFn: validatePolicyProbes,
+			validationResult: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid tcp probe address: \"192.168.100.10\": must be host:port",
+					Field:   "spec.probes[0].tcp.address",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid probe name: \"storage\": is duplicated",
+					Field:   "spec.probes[1].name",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid http probe url: \"ftp://storage\": must be an http or https URL",
+					Field:   "spec.probes[1].http.url",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid http probe expected status: 42: must be between 100 and 599",
+					Field:   "spec.probes[1].http.expectedStatus",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid probe timeout: \"0s\": must be positive",
+					Field:   "spec.probes[2].timeout",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid interface probe: carrier or ipAddress has to be checked",
+					Field:   "spec.probes[2].interface",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid probe \"both\": exactly one of icmp, tcp, http, dns or interface has to be set",
+					Field:   "spec.probes[3]",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid dns probe host: must not be empty",
+					Field:   "spec.probes[4].dns.host",
+				},
+			},
+		}),
 		Entry("policy has name with length beyond the limit", ValidationWebhookCase{
 			policy:       nmstatev1beta1.NodeNetworkConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "this-is-longer-than-sixty-three-characters-hostname-bar-bar-bar.foo.com"}},
 			validationFn: validatePolicyName,
@@ -126,4 +412,81 @@ var _ = Describe("NNCP Conditions Validation Admission Webhook", func() {
 			}},
 		}),
 	)
+
+	Context("when the policy in progress is paused", func() {
+		var (
+			enactment     nmstatev1beta1.NodeNetworkConfigurationEnactment
+			currentPolicy nmstatev1beta1.NodeNetworkConfigurationPolicy
+			policy        nmstatev1beta1.NodeNetworkConfigurationPolicy
+		)
+		BeforeEach(func() {
+			currentPolicy = pause(p(allNodes, policyconditions.SetPolicyProgressing, ""))
+			currentPolicy.Spec.DesiredState = shared.NewState("interfaces:\n- name: eth1\n  type: ethernet\n  state: up\n  mtu: 1500\n")
+			policy = currentPolicy
+			policy.Spec.DesiredState = shared.NewState("interfaces:\n- name: eth1\n  type: ethernet\n  state: up\n  mtu: 9000\n")
+			enactment = nmstatev1beta1.NewEnactment("node01", currentPolicy)
+			enactmentconditions.SetPaused(&enactment.Status.Conditions, "Policy rollout is paused")
+		})
+		validate := func() []metav1.StatusCause {
+			s := scheme.Scheme
+			s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+				&nmstatev1beta1.NodeNetworkConfigurationEnactment{},
+				&nmstatev1beta1.NodeNetworkConfigurationEnactmentList{},
+			)
+			cli := fake.NewFakeClientWithScheme(s, &enactment)
+			return validatePolicyNotInProgress(cli)(policy, currentPolicy)
+		}
+		It("should allow editing the desiredState", func() {
+			Expect(validate()).To(BeEmpty())
+		})
+		It("should deny editing the desiredState while an enactment is in flight", func() {
+			enactmentconditions.SetProgressing(&enactment.Status.Conditions, "Applying desired state")
+			Expect(validate()).To(ConsistOf(metav1.StatusCause{
+				Message: "policy testPolicy is still in progress",
+			}))
+		})
+	})
+})
+
+var _ = Describe("NNCP offline validation", func() {
+	It("should run the spec validations against the given nodes and policies", func() {
+		s := scheme.Scheme
+		s.AddKnownTypes(nmstatev1beta1.GroupVersion,
+			&nmstatev1beta1.NodeNetworkConfigurationPolicy{},
+			&nmstatev1beta1.NodeNetworkConfigurationPolicyList{},
+		)
+		node := conflictNode("node01", map[string]string{"role": "worker"})
+		otherPolicy := conflictPolicy("other", map[string]string{"role": "worker"}, `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: down
+`)
+		cli := fake.NewFakeClientWithScheme(s, []runtime.Object{&node, &otherPolicy}...)
+
+		policy := conflictPolicy("foo+bar", map[string]string{"role": "worker"}, `
+interfaces:
+- name: eth1
+  type: ethernet
+  state: up
+  ipv4:
+    enabled: true
+    address:
+    - ip: 10.244.0.300
+      prefix-length: 24
+`)
+		policy.Spec.Timeouts = &shared.NodeNetworkConfigurationPolicyTimeouts{Checkpoint: &metav1.Duration{Duration: -time.Second}}
+		causes := ValidatePolicy(cli, policy)
+
+		fields := []string{}
+		for _, cause := range causes {
+			fields = append(fields, cause.Field)
+		}
+		Expect(fields).To(Equal([]string{
+			"name",
+			"spec.timeouts.checkpoint",
+			"spec.desiredState.interfaces[0].ipv4.address[0].ip",
+			"spec.desiredState.interfaces[0].state",
+		}))
+	})
 })
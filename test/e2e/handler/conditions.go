@@ -15,6 +15,7 @@ import (
 
 	shared "github.com/nmstate/kubernetes-nmstate/api/shared"
 	nmstatev1beta1 "github.com/nmstate/kubernetes-nmstate/api/v1beta1"
+	enactmentconditions "github.com/nmstate/kubernetes-nmstate/pkg/enactmentstatus/conditions"
 	testenv "github.com/nmstate/kubernetes-nmstate/test/env"
 )
 
@@ -177,3 +178,16 @@ func matchConditionsFrom(conditionsSetter func(*shared.ConditionList, string)) G
 	expectedConditions = filterOutMessageAndTimestampFromConditions(expectedConditions)
 	return WithTransform(filterOutMessageAndTimestampFromConditions, ConsistOf(expectedConditions))
 }
+
+// matchFailingConditions matches the Failing conditions with any of the
+// failure reasons
+func matchFailingConditions() GomegaMatcher {
+	matchers := []GomegaMatcher{}
+	for _, reason := range shared.NodeNetworkConfigurationEnactmentFailingReasons {
+		reason := reason
+		matchers = append(matchers, matchConditionsFrom(func(conditions *shared.ConditionList, message string) {
+			enactmentconditions.SetFailed(conditions, reason, message)
+		}))
+	}
+	return SatisfyAny(matchers...)
+}
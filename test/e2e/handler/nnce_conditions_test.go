@@ -71,7 +71,7 @@ var _ = Describe("[rfe_id:3503][crit:medium][vendor:cnv-qe@redhat.com][level:com
 				By(fmt.Sprintf("Check %s failing state is reached", node))
 				enactmentConditionsStatusEventually(node).Should(
 					SatisfyAny(
-						matchConditionsFrom(enactmentconditions.SetFailedToConfigure),
+						matchFailingConditions(),
 						matchConditionsFrom(enactmentconditions.SetConfigurationAborted),
 					), "should eventually reach failing or aborted conditions at enactments",
 				)
@@ -90,7 +90,7 @@ var _ = Describe("[rfe_id:3503][crit:medium][vendor:cnv-qe@redhat.com][level:com
 					By(fmt.Sprintf("Check %s failing state is kept", node))
 					enactmentConditionsStatusConsistently(node).Should(
 						SatisfyAny(
-							matchConditionsFrom(enactmentconditions.SetFailedToConfigure),
+							matchFailingConditions(),
 							matchConditionsFrom(enactmentconditions.SetConfigurationAborted),
 						), "should consistently keep failing or aborted conditions at enactments",
 					)
@@ -105,7 +105,7 @@ var _ = Describe("[rfe_id:3503][crit:medium][vendor:cnv-qe@redhat.com][level:com
 				abortedConditions := 0
 				for _, node := range nodes {
 					conditionList := enactmentConditionsStatus(node, TestPolicy)
-					success, _ := matchConditionsFrom(enactmentconditions.SetFailedToConfigure).Match(conditionList)
+					success, _ := matchFailingConditions().Match(conditionList)
 					if success {
 						failingConditions++
 					}
@@ -135,7 +135,7 @@ var _ = Describe("[rfe_id:3503][crit:medium][vendor:cnv-qe@redhat.com][level:com
 					By(fmt.Sprintf("Check %s failing state is kept", node))
 					enactmentConditionsStatusConsistently(node).Should(
 						SatisfyAny(
-							matchConditionsFrom(enactmentconditions.SetFailedToConfigure),
+							matchFailingConditions(),
 							matchConditionsFrom(enactmentconditions.SetConfigurationAborted),
 						), "should consistently keep failing or aborted conditions at enactments")
 				}()